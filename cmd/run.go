@@ -10,6 +10,8 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/jumppad-labs/connector/http"
@@ -31,6 +33,10 @@ var runCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Do Stuff Here
 
+		if err := remote.ValidateDefaultBindAddress(serviceBindAddr); err != nil {
+			return fmt.Errorf("invalid service bind address: %s", err)
+		}
+
 		lo := hclog.LoggerOptions{}
 		lo.Level = hclog.LevelFromString(logLevel)
 		l := hclog.New(&lo)
@@ -48,8 +54,23 @@ var runCmd = &cobra.Command{
 			in = local.New(l.Named("local_integration"))
 		}
 
+		opts := []remote.Option{
+			remote.WithResumeGracePeriod(resumeGracePeriod),
+			remote.WithStreams(linkStreams),
+			remote.WithUDPIdleTimeout(udpIdleTimeout),
+			remote.WithHeartbeat(heartbeatInterval, heartbeatMisses),
+			remote.WithBackoff(backoffInitial, backoffMax, backoffMultiplier, backoffJitter),
+			remote.WithMessageSize(messageSize),
+			remote.WithPortRange(portRangeMin, portRangeMax),
+			remote.WithBindAddress(serviceBindAddr),
+			remote.WithDialTimeout(dialTimeout),
+			remote.WithLinkRateLimit(linkRateLimit, linkRateBurst),
+			remote.WithProxyAllowList(proxyAllow),
+			remote.WithProxyDenyList(proxyDeny),
+		}
+
 		grpcServer := grpc.NewServer()
-		s := remote.New(l.Named("grpc_server"), nil, nil, in)
+		s := remote.New(l.Named("grpc_server"), nil, nil, in, opts...)
 
 		// do we need to set up the server to use TLS?
 		if pathCertServer != "" && pathKeyServer != "" && pathCertRoot != "" {
@@ -82,7 +103,7 @@ var runCmd = &cobra.Command{
 			})
 
 			grpcServer = grpc.NewServer(grpc.Creds(creds))
-			s = remote.New(l.Named("grpc_server"), certPool, &certificate, in)
+			s = remote.New(l.Named("grpc_server"), certPool, &certificate, in, opts...)
 		}
 
 		shipyard.RegisterRemoteConnectionServer(grpcServer, s)
@@ -110,11 +131,18 @@ var runCmd = &cobra.Command{
 		c := make(chan os.Signal, 1)
 		signal.Notify(c, os.Interrupt)
 		signal.Notify(c, os.Kill)
+		signal.Notify(c, syscall.SIGTERM)
 
 		// Block until a signal is received.
 		sig := <-c
 		log.Println("Got signal:", sig)
 
+		// allow active connections to finish when terminated, for example during a rolling restart
+		if sig == syscall.SIGTERM {
+			s.Drain(drainTimeout)
+			return nil
+		}
+
 		s.Shutdown()
 
 		return nil
@@ -132,10 +160,30 @@ var integration string
 var namespace string
 var verifyClient bool
 var disableLocalExpose bool
+var resumeGracePeriod time.Duration
+var linkStreams int
+var udpIdleTimeout time.Duration
+var heartbeatInterval time.Duration
+var heartbeatMisses int
+var backoffInitial time.Duration
+var backoffMax time.Duration
+var backoffMultiplier float64
+var backoffJitter float64
+var drainTimeout time.Duration
+var messageSize int
+var portRangeMin int
+var portRangeMax int
+var serviceBindAddr string
+var dialTimeout time.Duration
+var linkRateLimit int64
+var linkRateBurst int64
+var proxyAllow []string
+var proxyDeny []string
 
 func init() {
 	runCmd.Flags().StringVarP(&grpcBindAddr, "grpc-bind", "", ":9090", "Bind address for the gRPC API")
 	runCmd.Flags().StringVarP(&httpBindAddr, "http-bind", "", ":9091", "Bind address for the HTTP API")
+	runCmd.Flags().StringVarP(&serviceBindAddr, "service-bind", "", remote.DefaultBindAddress, "Default IP address for the listeners of exposed services, 0.0.0.0 for all IPv4 interfaces, :: for all IPv4 and IPv6 interfaces")
 	runCmd.Flags().StringVarP(&pathCertRoot, "root-cert-path", "", "", "Path for the PEM encoded TLS root certificate")
 	runCmd.Flags().StringVarP(&pathKeyRoot, "root-cert-key", "", "", "Path for the PEM encoded TLS root key needed to generate certificates")
 	runCmd.Flags().StringVarP(&pathCertServer, "server-cert-path", "", "", "Path for the servers PEM encoded TLS certificate")
@@ -145,4 +193,22 @@ func init() {
 	runCmd.Flags().StringVarP(&namespace, "namespace", "", "shipyard", "Kubernetes namespace when using Kubernetes integration, default: shipyard")
 	runCmd.Flags().BoolVarP(&verifyClient, "disableLocalExpose", "", false, "Disable exposing local services to remote connections")
 	runCmd.Flags().BoolVarP(&verifyClient, "disable-remote-expose", "", true, "Verify client cert has been signed by same root as CA")
+	runCmd.Flags().DurationVarP(&resumeGracePeriod, "resume-grace-period", "", remote.DefaultResumeGracePeriod, "Time to keep connections open waiting for an interrupted stream to resume, 0 disables resuming streams")
+	runCmd.Flags().IntVarP(&linkStreams, "streams", "", 1, "Number of parallel gRPC streams used for each link to a remote server")
+	runCmd.Flags().DurationVarP(&udpIdleTimeout, "udp-idle-timeout", "", remote.DefaultUDPIdleTimeout, "Time a UDP client can be inactive before its connection is closed")
+	runCmd.Flags().DurationVarP(&heartbeatInterval, "heartbeat-interval", "", remote.DefaultHeartbeatInterval, "Interval between heartbeats sent to remote connectors, 0 disables heartbeats")
+	runCmd.Flags().IntVarP(&heartbeatMisses, "heartbeat-misses", "", remote.DefaultHeartbeatMisses, "Number of consecutive heartbeats which can be missed before a remote connector is considered dead")
+	runCmd.Flags().DurationVarP(&backoffInitial, "backoff-initial", "", remote.DefaultBackoffInitial, "Time to wait before retrying a failed connection to a remote connector")
+	runCmd.Flags().DurationVarP(&backoffMax, "backoff-max", "", remote.DefaultBackoffMax, "Maximum time to wait between attempts to connect to a remote connector")
+	runCmd.Flags().Float64VarP(&backoffMultiplier, "backoff-multiplier", "", remote.DefaultBackoffMultiplier, "Multiplier applied to the wait after each failed connection attempt")
+	runCmd.Flags().Float64VarP(&backoffJitter, "backoff-jitter", "", remote.DefaultBackoffJitter, "Fraction of the wait which is randomly added or removed to spread out reconnections")
+	runCmd.Flags().DurationVarP(&drainTimeout, "drain-timeout", "", remote.DefaultDrainTimeout, "Time to wait for active connections to finish when terminated with SIGTERM")
+	runCmd.Flags().IntVarP(&messageSize, "message-size", "", remote.MessageSize, "Maximum data payload in bytes sent in a single message, larger messages improve throughput for bulk transfers")
+	runCmd.Flags().IntVarP(&portRangeMin, "port-range-min", "", 0, "First port of the range used for services exposed without a source port, when not set a free port is allocated by the OS")
+	runCmd.Flags().IntVarP(&portRangeMax, "port-range-max", "", 0, "Last port of the range used for services exposed without a source port")
+	runCmd.Flags().DurationVarP(&dialTimeout, "dial-timeout", "", remote.DefaultDialTimeout, "Time to wait for a connection to the destination of a service which does not set a dial timeout")
+	runCmd.Flags().Int64VarP(&linkRateLimit, "link-rate-limit", "", 0, "Bandwidth limit in bytes per second for each link to a remote connector, 0 does not limit links")
+	runCmd.Flags().Int64VarP(&linkRateBurst, "link-rate-burst", "", 0, "Number of bytes which can be sent at once over a rate limited link, defaults to the rate")
+	runCmd.Flags().StringSliceVarP(&proxyAllow, "proxy-allow", "", nil, "Destinations which can be dialed for clients of proxy services, a CIDR, IP address or hostname such as *.example.com, proxy connections are refused when not set")
+	runCmd.Flags().StringSliceVarP(&proxyDeny, "proxy-deny", "", nil, "Destinations which can not be dialed for clients of proxy services even when they are allowed, in the same format as --proxy-allow")
 }
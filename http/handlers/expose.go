@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
@@ -25,18 +26,105 @@ func NewExpose(client shipyard.RemoteConnectionClient, l hclog.Logger) *Expose {
 
 // ExposeRequest is the JSON request for the Create handler
 type ExposeRequest struct {
-	Name                string `json:"name" validate:"required"`
-	SourcePort          int    `json:"source_port" validate:"required"`
-	RemoteConnectorAddr string `json:"remote_connector_addr" validate:"required"`
-	DestinationAddr     string `json:"destination_addr" validate:"required"`
-	Type                string `json:"type" validate:"oneof=local remote"`
+	Name                string       `json:"name" validate:"required"`
+	SourcePort          int          `json:"source_port" validate:"min=0,max=65535"`
+	RemoteConnectorAddr string       `json:"remote_connector_addr" validate:"required"`
+	DestinationAddr     string       `json:"destination_addr"`
+	Type                string       `json:"type" validate:"oneof=local remote socks5 http_proxy"`
+	Protocol            string       `json:"protocol" validate:"omitempty,oneof=tcp udp"`
+	Compression         string       `json:"compression" validate:"omitempty,oneof=none gzip"`
+	MessageSize         int          `json:"message_size" validate:"omitempty,min=512,max=1048576"`
+	BindAddress         string       `json:"bind_address" validate:"omitempty,ip|startswith=unix://"`
+	DestinationAddrs    []string     `json:"destination_addrs" validate:"omitempty,dive,required"`
+	LoadBalancing       string       `json:"load_balancing" validate:"omitempty,oneof=round_robin least_connections random"`
+	HealthCheck         *HealthCheck `json:"health_check"`
+	DialTimeout         string       `json:"dial_timeout"`
+	DialRetries         int          `json:"dial_retries" validate:"min=0,max=10"`
+	IdleTimeout         string       `json:"idle_timeout"`
+	MaxLifetime         string       `json:"max_lifetime"`
+	MaxConnections      int          `json:"max_connections" validate:"min=0"`
+	ConnectionLimit     string       `json:"connection_limit_action" validate:"omitempty,oneof=reject queue"`
+	RateLimit           *RateLimit   `json:"rate_limit"`
+	Match               *Match       `json:"match"`
+	DefaultDestination  string       `json:"default_destination_addr" validate:"required_with=Match"`
+	Hosts               []string     `json:"hosts" validate:"omitempty,dive,required"`
+	ProxyProtocol       string       `json:"proxy_protocol" validate:"omitempty,oneof=v1 v2"`
+}
+
+// HealthCheck configures active health checks for the destinations of a service
+type HealthCheck struct {
+	Interval           string `json:"interval" validate:"required"`
+	Timeout            string `json:"timeout"`
+	UnhealthyThreshold int    `json:"unhealthy_threshold" validate:"min=0"`
+	HealthyThreshold   int    `json:"healthy_threshold" validate:"min=0"`
+}
+
+// proto returns the health check for the gRPC API
+func (h *HealthCheck) proto() (*shipyard.HealthCheck, error) {
+	interval, err := time.ParseDuration(h.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health check interval: %s", err)
+	}
+
+	var timeout time.Duration
+	if h.Timeout != "" {
+		timeout, err = time.ParseDuration(h.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid health check timeout: %s", err)
+		}
+	}
+
+	return &shipyard.HealthCheck{
+		Interval:           int64(interval),
+		Timeout:            int64(timeout),
+		UnhealthyThreshold: int32(h.UnhealthyThreshold),
+		HealthyThreshold:   int32(h.HealthyThreshold),
+	}, nil
+}
+
+// timeouts returns the dial timeout, idle timeout and max lifetime for the service,
+// durations which are not set are 0
+func (c *ExposeRequest) timeouts() (dial, idle, lifetime time.Duration, err error) {
+	if dial, err = parseDuration("dial timeout", c.DialTimeout); err != nil {
+		return
+	}
+
+	if idle, err = parseDuration("idle timeout", c.IdleTimeout); err != nil {
+		return
+	}
+
+	lifetime, err = parseDuration("max lifetime", c.MaxLifetime)
+	return
+}
+
+// parseDuration parses an optional duration, an empty string is 0
+func parseDuration(name, v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, v)
+	}
+
+	return d, nil
 }
 
 // Validate the struct and return an error if invalid
 func (c *ExposeRequest) Validate() error {
 	validate := validator.New()
 
-	return validate.Struct(c)
+	if err := validate.Struct(c); err != nil {
+		return err
+	}
+
+	// the destination for proxy services is requested by the client
+	if c.DestinationAddr == "" && c.Type != "socks5" && c.Type != "http_proxy" {
+		return fmt.Errorf("destination_addr is required for %s services", c.Type)
+	}
+
+	return nil
 }
 
 func decodeJSON(r io.Reader, dest interface{}) error {
@@ -70,18 +158,94 @@ func (c *Expose) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	// first get a client
 	c.logger.Info("Sending request to the local gRPC server", "request", cr)
 	t := shipyard.ServiceType_LOCAL
-	if cr.Type == "remote" {
+	px := shipyard.ProxyProtocol_NO_PROXY
+	switch cr.Type {
+	case "remote":
 		t = shipyard.ServiceType_REMOTE
+	case "socks5":
+		// proxy services listen locally and dial from the remote connector
+		t = shipyard.ServiceType_REMOTE
+		px = shipyard.ProxyProtocol_SOCKS5
+	case "http_proxy":
+		t = shipyard.ServiceType_REMOTE
+		px = shipyard.ProxyProtocol_HTTP
+	}
+
+	p := shipyard.ServiceProtocol_TCP
+	if cr.Protocol == "udp" {
+		p = shipyard.ServiceProtocol_UDP
+	}
+
+	cmp := shipyard.Compression_NONE
+	if cr.Compression == "gzip" {
+		cmp = shipyard.Compression_GZIP
+	}
+
+	lb := shipyard.LoadBalancing_ROUND_ROBIN
+	switch cr.LoadBalancing {
+	case "least_connections":
+		lb = shipyard.LoadBalancing_LEAST_CONNECTIONS
+	case "random":
+		lb = shipyard.LoadBalancing_RANDOM
+	}
+
+	var hc *shipyard.HealthCheck
+	if cr.HealthCheck != nil {
+		hc, err = cr.HealthCheck.proto()
+		if err != nil {
+			c.logger.Error("Failed validation", "error", err)
+			http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	dt, idle, lifetime, err := cr.timeouts()
+	if err != nil {
+		c.logger.Error("Failed validation", "error", err)
+		http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	ph := shipyard.ProxyHeader_NO_PROXY_HEADER
+	switch cr.ProxyProtocol {
+	case "v1":
+		ph = shipyard.ProxyHeader_PROXY_V1
+	case "v2":
+		ph = shipyard.ProxyHeader_PROXY_V2
+	}
+
+	la := shipyard.ConnectionLimitAction_REJECT
+	if cr.ConnectionLimit == "queue" {
+		la = shipyard.ConnectionLimitAction_QUEUE
 	}
 
 	// Call the grpc upstream
 	resp, err := c.client.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
-			Name:                cr.Name,
-			RemoteConnectorAddr: cr.RemoteConnectorAddr,
-			DestinationAddr:     cr.DestinationAddr,
-			SourcePort:          int32(cr.SourcePort),
-			Type:                t,
+			Name:                   cr.Name,
+			RemoteConnectorAddr:    cr.RemoteConnectorAddr,
+			DestinationAddr:        cr.DestinationAddr,
+			SourcePort:             int32(cr.SourcePort),
+			Type:                   t,
+			Protocol:               p,
+			Compression:            cmp,
+			MessageSize:            int32(cr.MessageSize),
+			BindAddress:            cr.BindAddress,
+			DestinationAddrs:       cr.DestinationAddrs,
+			LoadBalancing:          lb,
+			HealthCheck:            hc,
+			DialTimeout:            int64(dt),
+			DialRetries:            int32(cr.DialRetries),
+			IdleTimeout:            int64(idle),
+			MaxLifetime:            int64(lifetime),
+			MaxConnections:         int32(cr.MaxConnections),
+			ConnectionLimitAction:  la,
+			RateLimit:              cr.RateLimit.proto(),
+			Match:                  cr.Match.proto(),
+			DefaultDestinationAddr: cr.DefaultDestination,
+			Hosts:                  cr.Hosts,
+			Proxy:                  px,
+			ProxyHeader:            ph,
 		},
 	})
 
@@ -35,6 +35,15 @@ func (t *testClient) ListServices(ctx context.Context, in *shipyard.NullMessage,
 	return nil, nil
 }
 
+func (t *testClient) SetRateLimit(ctx context.Context, in *shipyard.RateLimitRequest, opts ...grpc.CallOption) (*shipyard.NullMessage, error) {
+	if len(t.ExpectedCalls) == 0 {
+		return &shipyard.NullMessage{}, nil
+	}
+
+	args := t.Called(in)
+	return &shipyard.NullMessage{}, args.Error(0)
+}
+
 func TestNoBodyBadReqest(t *testing.T) {
 	h := NewExpose(&testClient{}, hclog.Default())
 	rr := httptest.NewRecorder()
@@ -57,3 +66,276 @@ func TestInvalidBodyUnprocessableEntity(t *testing.T) {
 
 	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
 }
+
+func TestInvalidProtocolUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+		Protocol:            "sctp",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestZeroSourcePortAllocatesPort(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          0,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "test", rr.Body.String())
+}
+
+func TestInvalidSourcePortUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          70000,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestInvalidBindAddressUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+		BindAddress:         "localhost",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestUnixSocketBindAddressIsValid(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "unix:///var/run/docker.sock",
+		Type:                "local",
+		BindAddress:         "unix:///tmp/docker.sock",
+	}
+
+	require.NoError(t, cr.Validate())
+}
+
+func TestInvalidHealthCheckIntervalUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		DestinationAddrs:    []string{"localhost:8081"},
+		Type:                "local",
+		LoadBalancing:       "least_connections",
+		HealthCheck:         &HealthCheck{Interval: "often"},
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestInvalidDialTimeoutUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+		DialTimeout:         "soon",
+		DialRetries:         2,
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestMatchWithoutDefaultDestinationUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+		Match: &Match{
+			HTTP: &HTTPMatch{Headers: []HeaderMatch{{Name: "x-dev-user", Exact: "alice"}}},
+		},
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestMatchHeaderWithoutNameUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+		Match: &Match{
+			HTTP: &HTTPMatch{Headers: []HeaderMatch{{Exact: "alice"}}},
+		},
+		DefaultDestination: "localhost:8081",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestEmptyHostUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "local",
+		Hosts:               []string{"api.example.com", ""},
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestMissingDestinationUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		Type:                "remote",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestSOCKS5WithoutDestinationOK(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          1080,
+		RemoteConnectorAddr: "localhost:9090",
+		Type:                "socks5",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestHTTPProxyWithoutDestinationOK(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          3128,
+		RemoteConnectorAddr: "localhost:9090",
+		Type:                "http_proxy",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestInvalidProxyProtocolUnprocessableEntity(t *testing.T) {
+	cr := &ExposeRequest{
+		Name:                "test",
+		SourcePort:          8080,
+		RemoteConnectorAddr: "localhost:9090",
+		DestinationAddr:     "localhost:8080",
+		Type:                "remote",
+		ProxyProtocol:       "v3",
+	}
+	d, _ := json.Marshal(cr)
+
+	h := NewExpose(&testClient{}, hclog.Default())
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/jumppad-labs/connector/protos/shipyard"
@@ -16,13 +17,94 @@ type List struct {
 }
 
 type Service struct {
-	ID                  string `json:"id" validate:"required"`
-	Name                string `json:"name" validate:"required"`
-	SourcePort          int    `json:"source_port" validate:"required"`
-	RemoteConnectorAddr string `json:"remote_connector_addr" validate:"required"`
-	DestinationAddr     string `json:"destination_addr" validate:"required"`
-	Type                string `json:"type" validate:"oneof=local remote"`
-	Status              string `json:"status"`
+	ID                  string            `json:"id" validate:"required"`
+	Name                string            `json:"name" validate:"required"`
+	SourcePort          int               `json:"source_port" validate:"required"`
+	RemoteConnectorAddr string            `json:"remote_connector_addr" validate:"required"`
+	DestinationAddr     string            `json:"destination_addr"`
+	Type                string            `json:"type" validate:"oneof=local remote"`
+	Protocol            string            `json:"protocol"`
+	Status              string            `json:"status"`
+	Link                *Link             `json:"link,omitempty"`
+	Compression         string            `json:"compression"`
+	MessageSize         int               `json:"message_size,omitempty"`
+	BindAddress         string            `json:"bind_address,omitempty"`
+	DestinationAddrs    []string          `json:"destination_addrs,omitempty"`
+	LoadBalancing       string            `json:"load_balancing"`
+	Endpoints           []Endpoint        `json:"endpoints,omitempty"`
+	CompressionStats    *CompressionStats `json:"compression_stats,omitempty"`
+	DialTimeout         string            `json:"dial_timeout,omitempty"`
+	DialRetries         int               `json:"dial_retries,omitempty"`
+	DialStats           *DialStats        `json:"dial_stats,omitempty"`
+	IdleTimeout         string            `json:"idle_timeout,omitempty"`
+	MaxLifetime         string            `json:"max_lifetime,omitempty"`
+	MaxConnections      int               `json:"max_connections,omitempty"`
+	ConnectionLimit     string            `json:"connection_limit_action,omitempty"`
+	RateLimit           *RateLimit        `json:"rate_limit,omitempty"`
+	ThrottleStats       *ThrottleStats    `json:"throttle_stats,omitempty"`
+	Match               *Match            `json:"match,omitempty"`
+	DefaultDestination  string            `json:"default_destination_addr,omitempty"`
+	Hosts               []string          `json:"hosts,omitempty"`
+	Proxy               string            `json:"proxy,omitempty"`
+	ProxyProtocol       string            `json:"proxy_protocol,omitempty"`
+}
+
+// ThrottleStats are the totals for data delayed by a rate limit
+type ThrottleStats struct {
+	Bytes         int64  `json:"bytes"`
+	ThrottledTime string `json:"throttled_time"`
+	Waiting       int    `json:"waiting"`
+}
+
+func newThrottleStats(t *shipyard.ThrottleStats) *ThrottleStats {
+	if t == nil {
+		return nil
+	}
+
+	return &ThrottleStats{
+		Bytes:         t.Bytes,
+		ThrottledTime: time.Duration(t.ThrottledTime).String(),
+		Waiting:       int(t.Waiting),
+	}
+}
+
+// DialStats are the totals for connections which could not be made to the destinations
+type DialStats struct {
+	Failures    int64  `json:"failures"`
+	Retries     int64  `json:"retries"`
+	LastError   string `json:"last_error,omitempty"`
+	LastFailure string `json:"last_failure,omitempty"`
+}
+
+// CompressionStats are the totals for the data sent for the service
+type CompressionStats struct {
+	BytesIn  int64   `json:"bytes_in"`
+	BytesOut int64   `json:"bytes_out"`
+	Skipped  int64   `json:"skipped"`
+	Ratio    float64 `json:"ratio"` // bytes_out / bytes_in
+}
+
+// Endpoint is the health of a destination for the service
+type Endpoint struct {
+	Addr              string `json:"addr"`
+	Healthy           bool   `json:"healthy"`
+	Ejected           bool   `json:"ejected"`
+	ActiveConnections int64  `json:"active_connections"`
+	Failures          int    `json:"failures"`
+	LastError         string `json:"last_error,omitempty"`
+}
+
+// Link is the status of the connection to the remote connector
+type Link struct {
+	RoundTripTime    string         `json:"round_trip_time"`
+	MissedHeartbeats int            `json:"missed_heartbeats"`
+	LastHeartbeat    string         `json:"last_heartbeat,omitempty"`
+	State            string         `json:"state"`
+	Attempts         int            `json:"attempts"`
+	LastError        string         `json:"last_error,omitempty"`
+	NextAttempt      string         `json:"next_attempt,omitempty"`
+	RateLimit        *RateLimit     `json:"rate_limit,omitempty"`
+	ThrottleStats    *ThrottleStats `json:"throttle_stats,omitempty"`
 }
 
 // NewExpose creates a new Expose handler
@@ -48,7 +130,100 @@ func (l *List) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			RemoteConnectorAddr: v.RemoteConnectorAddr,
 			DestinationAddr:     v.DestinationAddr,
 			Type:                v.Type.String(),
+			Protocol:            v.Protocol.String(),
 			Status:              v.Status.String(),
+			Compression:         v.Compression.String(),
+			MessageSize:         int(v.MessageSize),
+			BindAddress:         v.BindAddress,
+			DestinationAddrs:    v.DestinationAddrs,
+			LoadBalancing:       v.LoadBalancing.String(),
+			DialRetries:         int(v.DialRetries),
+			Match:               newMatch(v.Match),
+			DefaultDestination:  v.DefaultDestinationAddr,
+			Hosts:               v.Hosts,
+		}
+
+		if v.Proxy != shipyard.ProxyProtocol_NO_PROXY {
+			s.Proxy = v.Proxy.String()
+		}
+
+		if v.ProxyHeader != shipyard.ProxyHeader_NO_PROXY_HEADER {
+			s.ProxyProtocol = v.ProxyHeader.String()
+		}
+
+		if v.DialTimeout > 0 {
+			s.DialTimeout = time.Duration(v.DialTimeout).String()
+		}
+
+		if v.IdleTimeout > 0 {
+			s.IdleTimeout = time.Duration(v.IdleTimeout).String()
+		}
+
+		if v.MaxLifetime > 0 {
+			s.MaxLifetime = time.Duration(v.MaxLifetime).String()
+		}
+
+		if v.MaxConnections > 0 {
+			s.MaxConnections = int(v.MaxConnections)
+			s.ConnectionLimit = v.ConnectionLimitAction.String()
+		}
+
+		s.RateLimit = newRateLimit(v.RateLimit)
+		s.ThrottleStats = newThrottleStats(v.ThrottleStats)
+
+		if v.DialStats != nil {
+			s.DialStats = &DialStats{
+				Failures:  v.DialStats.Failures,
+				Retries:   v.DialStats.Retries,
+				LastError: v.DialStats.LastError,
+			}
+
+			if v.DialStats.LastFailure > 0 {
+				s.DialStats.LastFailure = time.Unix(0, v.DialStats.LastFailure).UTC().Format(time.RFC3339)
+			}
+		}
+
+		for _, e := range v.Endpoints {
+			s.Endpoints = append(s.Endpoints, Endpoint{
+				Addr:              e.Addr,
+				Healthy:           e.Healthy,
+				Ejected:           e.Ejected,
+				ActiveConnections: e.ActiveConnections,
+				Failures:          int(e.Failures),
+				LastError:         e.LastError,
+			})
+		}
+
+		if v.CompressionStats != nil {
+			s.CompressionStats = &CompressionStats{
+				BytesIn:  v.CompressionStats.BytesIn,
+				BytesOut: v.CompressionStats.BytesOut,
+				Skipped:  v.CompressionStats.Skipped,
+			}
+
+			if v.CompressionStats.BytesIn > 0 {
+				s.CompressionStats.Ratio = float64(v.CompressionStats.BytesOut) / float64(v.CompressionStats.BytesIn)
+			}
+		}
+
+		if v.Link != nil {
+			s.Link = &Link{
+				RoundTripTime:    time.Duration(v.Link.RoundTripTime).String(),
+				MissedHeartbeats: int(v.Link.MissedHeartbeats),
+				State:            v.Link.State.String(),
+				Attempts:         int(v.Link.Attempts),
+				LastError:        v.Link.LastError,
+				RateLimit:        newRateLimit(v.Link.RateLimit),
+				ThrottleStats:    newThrottleStats(v.Link.ThrottleStats),
+			}
+
+			if v.Link.LastHeartbeat > 0 {
+				s.Link.LastHeartbeat = time.Unix(0, v.Link.LastHeartbeat).UTC().Format(time.RFC3339)
+			}
+
+			if v.Link.NextAttempt > 0 {
+				s.Link.NextAttempt = time.Unix(0, v.Link.NextAttempt).UTC().Format(time.RFC3339)
+			}
 		}
 
 		services = append(services, s)
@@ -0,0 +1,76 @@
+package handlers
+
+import "github.com/jumppad-labs/connector/protos/shipyard"
+
+// Match routes HTTP requests, requests which match are sent to the destination for the
+// service and all other requests to the default destination
+type Match struct {
+	HTTP *HTTPMatch `json:"http,omitempty"`
+	GRPC *GRPCMatch `json:"grpc,omitempty"`
+}
+
+// HTTPMatch matches requests on the headers and the path, all rules must match
+type HTTPMatch struct {
+	Headers    []HeaderMatch `json:"headers,omitempty" validate:"dive"`
+	PathPrefix string        `json:"path_prefix,omitempty"`
+	PathExact  string        `json:"path_exact,omitempty"`
+}
+
+// HeaderMatch matches a header by name, the header only needs to be present when
+// exact and prefix are not set
+type HeaderMatch struct {
+	Name   string `json:"name" validate:"required"`
+	Exact  string `json:"exact,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// GRPCMatch matches gRPC requests, an empty service or method matches any
+type GRPCMatch struct {
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+// proto returns the match for the gRPC API
+func (m *Match) proto() *shipyard.Match {
+	if m == nil {
+		return nil
+	}
+
+	pm := &shipyard.Match{}
+
+	if m.HTTP != nil {
+		pm.Http = &shipyard.Http{PathPrefix: m.HTTP.PathPrefix, PathExact: m.HTTP.PathExact}
+
+		for _, h := range m.HTTP.Headers {
+			pm.Http.Headers = append(pm.Http.Headers, &shipyard.Header{Name: h.Name, Exact: h.Exact, Prefix: h.Prefix})
+		}
+	}
+
+	if m.GRPC != nil {
+		pm.Grpc = &shipyard.Grpc{Service: m.GRPC.Service, Method: m.GRPC.Method}
+	}
+
+	return pm
+}
+
+func newMatch(m *shipyard.Match) *Match {
+	if m == nil {
+		return nil
+	}
+
+	hm := &Match{}
+
+	if m.Http != nil {
+		hm.HTTP = &HTTPMatch{PathPrefix: m.Http.PathPrefix, PathExact: m.Http.PathExact}
+
+		for _, h := range m.Http.Headers {
+			hm.HTTP.Headers = append(hm.HTTP.Headers, HeaderMatch{Name: h.Name, Exact: h.Exact, Prefix: h.Prefix})
+		}
+	}
+
+	if m.Grpc != nil {
+		hm.GRPC = &GRPCMatch{Service: m.Grpc.Service, Method: m.Grpc.Method}
+	}
+
+	return hm
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimit is a bandwidth limit in bytes per second
+type RateLimit struct {
+	BytesPerSecond int64 `json:"bytes_per_second" validate:"min=0"`
+	Burst          int64 `json:"burst" validate:"min=0"`
+}
+
+// proto returns the rate limit for the gRPC API, nil when there is no limit
+func (r *RateLimit) proto() *shipyard.RateLimit {
+	if r == nil || r.BytesPerSecond == 0 {
+		return nil
+	}
+
+	return &shipyard.RateLimit{BytesPerSecond: r.BytesPerSecond, Burst: r.Burst}
+}
+
+func newRateLimit(r *shipyard.RateLimit) *RateLimit {
+	if r == nil {
+		return nil
+	}
+
+	return &RateLimit{BytesPerSecond: r.BytesPerSecond, Burst: r.Burst}
+}
+
+// SetRateLimit handler changes the bandwidth limit for a service or a link
+type SetRateLimit struct {
+	client shipyard.RemoteConnectionClient
+	logger hclog.Logger
+}
+
+// NewSetRateLimit creates a new SetRateLimit handler
+func NewSetRateLimit(client shipyard.RemoteConnectionClient, l hclog.Logger) *SetRateLimit {
+	return &SetRateLimit{client, l}
+}
+
+// RateLimitRequest is the JSON request for the SetRateLimit handler, the limit is set for
+// the service when service_id is set otherwise for the link to the remote connector
+type RateLimitRequest struct {
+	ServiceID           string `json:"service_id" validate:"required_without=RemoteConnectorAddr"`
+	RemoteConnectorAddr string `json:"remote_connector_addr" validate:"required_without=ServiceID"`
+	RateLimit
+}
+
+// Validate the struct and return an error if invalid
+func (c *RateLimitRequest) Validate() error {
+	validate := validator.New()
+
+	return validate.Struct(c)
+}
+
+// ServeHTTP implements the http.Handler interface
+func (c *SetRateLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	c.logger.Info("Handle Set Rate Limit")
+
+	rr := &RateLimitRequest{}
+
+	err := decodeJSON(r.Body, rr)
+	if err != nil {
+		c.logger.Error("Unable to decode JSON", "error", err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = rr.Validate()
+	if err != nil {
+		c.logger.Error("Failed validation", "error", err)
+		http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	_, err = c.client.SetRateLimit(context.Background(), &shipyard.RateLimitRequest{
+		ServiceId:           rr.ServiceID,
+		RemoteConnectorAddr: rr.RemoteConnectorAddr,
+		Limit:               rr.RateLimit.proto(),
+	})
+
+	if status.Code(err) == codes.NotFound {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("Unable to set rate limit", "error", err)
+		http.Error(rw, fmt.Sprintf("Unable to set rate limit: %s", err), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setRateLimit(t *testing.T, c *testClient, rr *RateLimitRequest) *httptest.ResponseRecorder {
+	d, _ := json.Marshal(rr)
+
+	h := NewSetRateLimit(c, hclog.Default())
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(d))
+
+	h.ServeHTTP(rec, r)
+
+	return rec
+}
+
+func TestSetRateLimitWithoutTargetUnprocessableEntity(t *testing.T) {
+	rec := setRateLimit(t, &testClient{}, &RateLimitRequest{RateLimit: RateLimit{BytesPerSecond: 1024}})
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestSetRateLimitForServiceCallsServer(t *testing.T) {
+	c := &testClient{}
+	c.On("SetRateLimit", mock.Anything).Return(nil)
+
+	rec := setRateLimit(t, c, &RateLimitRequest{ServiceID: "abc", RateLimit: RateLimit{BytesPerSecond: 1024, Burst: 4096}})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	c.AssertCalled(t, "SetRateLimit", &shipyard.RateLimitRequest{
+		ServiceId: "abc",
+		Limit:     &shipyard.RateLimit{BytesPerSecond: 1024, Burst: 4096},
+	})
+}
+
+func TestSetRateLimitForUnknownLinkNotFound(t *testing.T) {
+	c := &testClient{}
+	c.On("SetRateLimit", mock.Anything).Return(status.Error(codes.NotFound, "not found"))
+
+	rec := setRateLimit(t, c, &RateLimitRequest{RemoteConnectorAddr: "localhost:9090"})
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -92,6 +92,9 @@ func (l *LocalServer) createHandlers() *mux.Router {
 	lh := handlers.NewList(cli, l.logger.Named("list_handler"))
 	r.Handle("/list", lh).Methods(gohttp.MethodGet)
 
+	rh := handlers.NewSetRateLimit(cli, l.logger.Named("rate_limit_handler"))
+	r.Handle("/rate_limit", rh).Methods(gohttp.MethodPut)
+
 	ch := handlers.NewGenerateCertificate(l.logger.Named("certificate_handler"), l.tlsCAPath, l.tlsCAKeyPath)
 	r.Handle("/certificate", ch).Methods(gohttp.MethodPost)
 
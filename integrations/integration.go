@@ -20,6 +20,12 @@ type Integration interface {
 	LookupAddress(service string) (string, error)
 }
 
+// EndpointResolver is implemented by integrations which can resolve a service name to
+// all of the addresses for the service, connections are balanced across the addresses
+type EndpointResolver interface {
+	LookupAddresses(service string) ([]string, error)
+}
+
 // SanitizeName takes a string and returns a URI acceptable name
 // e.g. Test Service would become test-service
 func SanitizeName(original string) string {
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/go-hclog"
@@ -29,38 +31,51 @@ func (i *Integration) Deregister(id string) error {
 }
 
 func (i *Integration) LookupAddress(service string) (string, error) {
-	i.log.Debug("Attempting to resolve host address for", "service", service)
+	addrs, err := i.LookupAddresses(service)
+	if err != nil {
+		return "", err
+	}
+
+	// choose a random endpoint
+	return addrs[rand.Intn(len(addrs))], nil
+}
+
+// LookupAddresses returns the addresses of all the running allocations for the service
+func (i *Integration) LookupAddresses(service string) ([]string, error) {
+	i.log.Debug("Attempting to resolve host addresses for", "service", service)
 
 	parts := strings.Split(service, ".")
 	if len(parts) != 3 {
-		return "", fmt.Errorf("service %s should be formatted job.group.task:port", service)
+		return nil, fmt.Errorf("service %s should be formatted job.group.task:port", service)
 	}
 
 	taskParts := strings.Split(parts[2], ":")
 	if len(taskParts) != 2 {
-		return "", fmt.Errorf("service %s should be formatted job.group.task:port", service)
+		return nil, fmt.Errorf("service %s should be formatted job.group.task:port", service)
 	}
 
 	eps, err := i.jobEndpoints(parts[0], parts[1], taskParts[0])
 	if err != nil {
-		return "", fmt.Errorf("unable to find endpoint for service %s, error: %s", service, err)
+		return nil, fmt.Errorf("unable to find endpoint for service %s, error: %s", service, err)
 	}
 
 	if len(eps) < 1 {
-		return "", fmt.Errorf("unable to find endpoint for service %s", service)
+		return nil, fmt.Errorf("unable to find endpoint for service %s", service)
 	}
 
-	// choose a random endpoint
-	ei := rand.Intn(len(eps))
-	ep := eps[ei]
-
 	// get the endpoint for the port
-	p, ok := ep[taskParts[1]]
-	if !ok {
-		return "", fmt.Errorf("unable to find port %s in endpoints for service %s", taskParts[1], service)
+	addrs := []string{}
+	for _, ep := range eps {
+		if p, ok := ep[taskParts[1]]; ok {
+			addrs = append(addrs, p)
+		}
+	}
+
+	if len(addrs) < 1 {
+		return nil, fmt.Errorf("unable to find port %s in endpoints for service %s", taskParts[1], service)
 	}
 
-	return p, nil
+	return addrs, nil
 }
 
 func (i *Integration) jobEndpoints(job, group, task string) ([]map[string]string, error) {
@@ -142,14 +157,14 @@ func (i *Integration) jobEndpoints(job, group, task string) ([]map[string]string
 			for _, n := range allocDetail.Resources.Networks {
 				for _, dp := range n.DynamicPorts {
 					if dp.Label == p {
-						ep[p] = fmt.Sprintf("%s:%d", n.IP, dp.Value)
+						ep[p] = net.JoinHostPort(n.IP, strconv.Itoa(dp.Value))
 						epc++
 					}
 				}
 
 				for _, dp := range n.ReservedPorts {
 					if dp.Label == p {
-						ep[p] = fmt.Sprintf("%s:%d", n.IP, dp.Value)
+						ep[p] = net.JoinHostPort(n.IP, strconv.Itoa(dp.Value))
 						epc++
 					}
 				}
@@ -30,6 +30,343 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+type ProxyHeader int32
+
+const (
+	ProxyHeader_NO_PROXY_HEADER ProxyHeader = 0
+	ProxyHeader_PROXY_V1        ProxyHeader = 1 // human readable header
+	ProxyHeader_PROXY_V2        ProxyHeader = 2 // binary header
+)
+
+// Enum value maps for ProxyHeader.
+var (
+	ProxyHeader_name = map[int32]string{
+		0: "NO_PROXY_HEADER",
+		1: "PROXY_V1",
+		2: "PROXY_V2",
+	}
+	ProxyHeader_value = map[string]int32{
+		"NO_PROXY_HEADER": 0,
+		"PROXY_V1":        1,
+		"PROXY_V2":        2,
+	}
+)
+
+func (x ProxyHeader) Enum() *ProxyHeader {
+	p := new(ProxyHeader)
+	*p = x
+	return p
+}
+
+func (x ProxyHeader) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProxyHeader) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[0].Descriptor()
+}
+
+func (ProxyHeader) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[0]
+}
+
+func (x ProxyHeader) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProxyHeader.Descriptor instead.
+func (ProxyHeader) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{0}
+}
+
+type ProxyProtocol int32
+
+const (
+	ProxyProtocol_NO_PROXY ProxyProtocol = 0 // connections are opened to the destinations for the service
+	ProxyProtocol_SOCKS5   ProxyProtocol = 1 // SOCKS5 CONNECT requests without authentication
+	ProxyProtocol_HTTP     ProxyProtocol = 2 // HTTP CONNECT requests and requests with an absolute URL
+)
+
+// Enum value maps for ProxyProtocol.
+var (
+	ProxyProtocol_name = map[int32]string{
+		0: "NO_PROXY",
+		1: "SOCKS5",
+		2: "HTTP",
+	}
+	ProxyProtocol_value = map[string]int32{
+		"NO_PROXY": 0,
+		"SOCKS5":   1,
+		"HTTP":     2,
+	}
+)
+
+func (x ProxyProtocol) Enum() *ProxyProtocol {
+	p := new(ProxyProtocol)
+	*p = x
+	return p
+}
+
+func (x ProxyProtocol) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProxyProtocol) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[1].Descriptor()
+}
+
+func (ProxyProtocol) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[1]
+}
+
+func (x ProxyProtocol) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProxyProtocol.Descriptor instead.
+func (ProxyProtocol) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{1}
+}
+
+type ConnectionLimitAction int32
+
+const (
+	ConnectionLimitAction_REJECT ConnectionLimitAction = 0 // new connections are closed
+	ConnectionLimitAction_QUEUE  ConnectionLimitAction = 1 // new connections wait until an existing connection closes
+)
+
+// Enum value maps for ConnectionLimitAction.
+var (
+	ConnectionLimitAction_name = map[int32]string{
+		0: "REJECT",
+		1: "QUEUE",
+	}
+	ConnectionLimitAction_value = map[string]int32{
+		"REJECT": 0,
+		"QUEUE":  1,
+	}
+)
+
+func (x ConnectionLimitAction) Enum() *ConnectionLimitAction {
+	p := new(ConnectionLimitAction)
+	*p = x
+	return p
+}
+
+func (x ConnectionLimitAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConnectionLimitAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[2].Descriptor()
+}
+
+func (ConnectionLimitAction) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[2]
+}
+
+func (x ConnectionLimitAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConnectionLimitAction.Descriptor instead.
+func (ConnectionLimitAction) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{2}
+}
+
+type LoadBalancing int32
+
+const (
+	LoadBalancing_ROUND_ROBIN       LoadBalancing = 0
+	LoadBalancing_LEAST_CONNECTIONS LoadBalancing = 1
+	LoadBalancing_RANDOM            LoadBalancing = 2
+)
+
+// Enum value maps for LoadBalancing.
+var (
+	LoadBalancing_name = map[int32]string{
+		0: "ROUND_ROBIN",
+		1: "LEAST_CONNECTIONS",
+		2: "RANDOM",
+	}
+	LoadBalancing_value = map[string]int32{
+		"ROUND_ROBIN":       0,
+		"LEAST_CONNECTIONS": 1,
+		"RANDOM":            2,
+	}
+)
+
+func (x LoadBalancing) Enum() *LoadBalancing {
+	p := new(LoadBalancing)
+	*p = x
+	return p
+}
+
+func (x LoadBalancing) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LoadBalancing) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[3].Descriptor()
+}
+
+func (LoadBalancing) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[3]
+}
+
+func (x LoadBalancing) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LoadBalancing.Descriptor instead.
+func (LoadBalancing) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{3}
+}
+
+type Compression int32
+
+const (
+	Compression_NONE Compression = 0
+	Compression_GZIP Compression = 1
+)
+
+// Enum value maps for Compression.
+var (
+	Compression_name = map[int32]string{
+		0: "NONE",
+		1: "GZIP",
+	}
+	Compression_value = map[string]int32{
+		"NONE": 0,
+		"GZIP": 1,
+	}
+)
+
+func (x Compression) Enum() *Compression {
+	p := new(Compression)
+	*p = x
+	return p
+}
+
+func (x Compression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Compression) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[4].Descriptor()
+}
+
+func (Compression) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[4]
+}
+
+func (x Compression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Compression.Descriptor instead.
+func (Compression) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{4}
+}
+
+type LinkState int32
+
+const (
+	LinkState_DISCONNECTED LinkState = 0 // no stream to the remote, waiting to reconnect
+	LinkState_CONNECTING   LinkState = 1 // opening a stream to the remote
+	LinkState_CONNECTED    LinkState = 2 // stream is open
+	LinkState_DRAINING     LinkState = 3 // stream is open but is being shut down
+)
+
+// Enum value maps for LinkState.
+var (
+	LinkState_name = map[int32]string{
+		0: "DISCONNECTED",
+		1: "CONNECTING",
+		2: "CONNECTED",
+		3: "DRAINING",
+	}
+	LinkState_value = map[string]int32{
+		"DISCONNECTED": 0,
+		"CONNECTING":   1,
+		"CONNECTED":    2,
+		"DRAINING":     3,
+	}
+)
+
+func (x LinkState) Enum() *LinkState {
+	p := new(LinkState)
+	*p = x
+	return p
+}
+
+func (x LinkState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LinkState) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[5].Descriptor()
+}
+
+func (LinkState) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[5]
+}
+
+func (x LinkState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LinkState.Descriptor instead.
+func (LinkState) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{5}
+}
+
+type ServiceProtocol int32
+
+const (
+	ServiceProtocol_TCP ServiceProtocol = 0
+	ServiceProtocol_UDP ServiceProtocol = 1
+)
+
+// Enum value maps for ServiceProtocol.
+var (
+	ServiceProtocol_name = map[int32]string{
+		0: "TCP",
+		1: "UDP",
+	}
+	ServiceProtocol_value = map[string]int32{
+		"TCP": 0,
+		"UDP": 1,
+	}
+)
+
+func (x ServiceProtocol) Enum() *ServiceProtocol {
+	p := new(ServiceProtocol)
+	*p = x
+	return p
+}
+
+func (x ServiceProtocol) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ServiceProtocol) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[6].Descriptor()
+}
+
+func (ServiceProtocol) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[6]
+}
+
+func (x ServiceProtocol) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ServiceProtocol.Descriptor instead.
+func (ServiceProtocol) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{6}
+}
+
 type ServiceType int32
 
 const (
@@ -60,11 +397,11 @@ func (x ServiceType) String() string {
 }
 
 func (ServiceType) Descriptor() protoreflect.EnumDescriptor {
-	return file_server_proto_enumTypes[0].Descriptor()
+	return file_server_proto_enumTypes[7].Descriptor()
 }
 
 func (ServiceType) Type() protoreflect.EnumType {
-	return &file_server_proto_enumTypes[0]
+	return &file_server_proto_enumTypes[7]
 }
 
 func (x ServiceType) Number() protoreflect.EnumNumber {
@@ -73,7 +410,7 @@ func (x ServiceType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ServiceType.Descriptor instead.
 func (ServiceType) EnumDescriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{0}
+	return file_server_proto_rawDescGZIP(), []int{7}
 }
 
 type ServiceStatus int32
@@ -109,11 +446,11 @@ func (x ServiceStatus) String() string {
 }
 
 func (ServiceStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_server_proto_enumTypes[1].Descriptor()
+	return file_server_proto_enumTypes[8].Descriptor()
 }
 
 func (ServiceStatus) Type() protoreflect.EnumType {
-	return &file_server_proto_enumTypes[1]
+	return &file_server_proto_enumTypes[8]
 }
 
 func (x ServiceStatus) Number() protoreflect.EnumNumber {
@@ -122,20 +459,20 @@ func (x ServiceStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ServiceStatus.Descriptor instead.
 func (ServiceStatus) EnumDescriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{1}
+	return file_server_proto_rawDescGZIP(), []int{8}
 }
 
 // Expose remote service - allow traffic on remote server 8081 to be exposed locally at 8080
-// 1. ExposeRequest called on local server
-//      name = service name
-//      serverAddr = http://remote.server
-//      localPort = 8080
-//      remotePort = 8081
-//      type = remote
-// 2. Call CreateListener on local server to setup a TCP listener
-//      name = service name
-//      port = 8080
-// 3. OpenStream called on remote if no stream exists
+//  1. ExposeRequest called on local server
+//     name = service name
+//     serverAddr = http://remote.server
+//     localPort = 8080
+//     remotePort = 8081
+//     type = remote
+//  2. Call CreateListener on local server to setup a TCP listener
+//     name = service name
+//     port = 8080
+//  3. OpenStream called on remote if no stream exists
 type NullMessage struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -194,6 +531,12 @@ type OpenData struct {
 	//	*OpenData_StatusUpdate
 	//	*OpenData_Ping
 	//	*OpenData_Error
+	//	*OpenData_WindowUpdate
+	//	*OpenData_Session
+	//	*OpenData_Handshake
+	//	*OpenData_Pong
+	//	*OpenData_GoingAway
+	//	*OpenData_Connected
 	Message isOpenData_Message `protobuf_oneof:"message"`
 }
 
@@ -306,7 +649,7 @@ func (x *OpenData) GetStatusUpdate() *StatusUpdate {
 	return nil
 }
 
-func (x *OpenData) GetPing() *NullMessage {
+func (x *OpenData) GetPing() *Ping {
 	if x, ok := x.GetMessage().(*OpenData_Ping); ok {
 		return x.Ping
 	}
@@ -320,6 +663,48 @@ func (x *OpenData) GetError() *status.Status {
 	return nil
 }
 
+func (x *OpenData) GetWindowUpdate() *WindowUpdate {
+	if x, ok := x.GetMessage().(*OpenData_WindowUpdate); ok {
+		return x.WindowUpdate
+	}
+	return nil
+}
+
+func (x *OpenData) GetSession() *Session {
+	if x, ok := x.GetMessage().(*OpenData_Session); ok {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *OpenData) GetHandshake() *Handshake {
+	if x, ok := x.GetMessage().(*OpenData_Handshake); ok {
+		return x.Handshake
+	}
+	return nil
+}
+
+func (x *OpenData) GetPong() *Pong {
+	if x, ok := x.GetMessage().(*OpenData_Pong); ok {
+		return x.Pong
+	}
+	return nil
+}
+
+func (x *OpenData) GetGoingAway() *GoingAway {
+	if x, ok := x.GetMessage().(*OpenData_GoingAway); ok {
+		return x.GoingAway
+	}
+	return nil
+}
+
+func (x *OpenData) GetConnected() *Connected {
+	if x, ok := x.GetMessage().(*OpenData_Connected); ok {
+		return x.Connected
+	}
+	return nil
+}
+
 type isOpenData_Message interface {
 	isOpenData_Message()
 }
@@ -357,13 +742,37 @@ type OpenData_StatusUpdate struct {
 }
 
 type OpenData_Ping struct {
-	Ping *NullMessage `protobuf:"bytes,11,opt,name=ping,proto3,oneof"`
+	Ping *Ping `protobuf:"bytes,11,opt,name=ping,proto3,oneof"`
 }
 
 type OpenData_Error struct {
 	Error *status.Status `protobuf:"bytes,12,opt,name=error,proto3,oneof"`
 }
 
+type OpenData_WindowUpdate struct {
+	WindowUpdate *WindowUpdate `protobuf:"bytes,13,opt,name=window_update,json=windowUpdate,proto3,oneof"`
+}
+
+type OpenData_Session struct {
+	Session *Session `protobuf:"bytes,14,opt,name=session,proto3,oneof"`
+}
+
+type OpenData_Handshake struct {
+	Handshake *Handshake `protobuf:"bytes,15,opt,name=handshake,proto3,oneof"`
+}
+
+type OpenData_Pong struct {
+	Pong *Pong `protobuf:"bytes,16,opt,name=pong,proto3,oneof"`
+}
+
+type OpenData_GoingAway struct {
+	GoingAway *GoingAway `protobuf:"bytes,17,opt,name=going_away,json=goingAway,proto3,oneof"`
+}
+
+type OpenData_Connected struct {
+	Connected *Connected `protobuf:"bytes,18,opt,name=connected,proto3,oneof"`
+}
+
 func (*OpenData_Data) isOpenData_Message() {}
 
 func (*OpenData_Expose) isOpenData_Message() {}
@@ -384,18 +793,31 @@ func (*OpenData_Ping) isOpenData_Message() {}
 
 func (*OpenData_Error) isOpenData_Message() {}
 
-// Data is a message containing data for a connection
-type Data struct {
+func (*OpenData_WindowUpdate) isOpenData_Message() {}
+
+func (*OpenData_Session) isOpenData_Message() {}
+
+func (*OpenData_Handshake) isOpenData_Message() {}
+
+func (*OpenData_Pong) isOpenData_Message() {}
+
+func (*OpenData_GoingAway) isOpenData_Message() {}
+
+func (*OpenData_Connected) isOpenData_Message() {}
+
+// GoingAway is sent when a connector starts to drain before shutting down, the
+// receiver must not open new connections over the stream. Existing connections
+// continue until they finish or the deadline is reached.
+type GoingAway struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id   int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Deadline int64 `protobuf:"varint,1,opt,name=deadline,proto3" json:"deadline,omitempty"` // time the stream will be closed, unix nanoseconds
 }
 
-func (x *Data) Reset() {
-	*x = Data{}
+func (x *GoingAway) Reset() {
+	*x = GoingAway{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -403,13 +825,13 @@ func (x *Data) Reset() {
 	}
 }
 
-func (x *Data) String() string {
+func (x *GoingAway) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Data) ProtoMessage() {}
+func (*GoingAway) ProtoMessage() {}
 
-func (x *Data) ProtoReflect() protoreflect.Message {
+func (x *GoingAway) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -421,34 +843,31 @@ func (x *Data) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Data.ProtoReflect.Descriptor instead.
-func (*Data) Descriptor() ([]byte, []int) {
+// Deprecated: Use GoingAway.ProtoReflect.Descriptor instead.
+func (*GoingAway) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *Data) GetId() int32 {
+func (x *GoingAway) GetDeadline() int64 {
 	if x != nil {
-		return x.Id
+		return x.Deadline
 	}
 	return 0
 }
 
-func (x *Data) GetData() []byte {
-	if x != nil {
-		return x.Data
-	}
-	return nil
-}
-
-// Indicates that a new connection has been received
-type NewConnection struct {
+// Ping is sent periodically to check the remote is alive, the remote replies
+// with a Pong containing the same timestamp. Version 1 connectors send an empty
+// Ping when a stream is opened which does not require a reply.
+type Ping struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Sent int64 `protobuf:"varint,1,opt,name=sent,proto3" json:"sent,omitempty"` // time the ping was sent in unix nanoseconds
 }
 
-func (x *NewConnection) Reset() {
-	*x = NewConnection{}
+func (x *Ping) Reset() {
+	*x = Ping{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -456,13 +875,13 @@ func (x *NewConnection) Reset() {
 	}
 }
 
-func (x *NewConnection) String() string {
+func (x *Ping) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NewConnection) ProtoMessage() {}
+func (*Ping) ProtoMessage() {}
 
-func (x *NewConnection) ProtoReflect() protoreflect.Message {
+func (x *Ping) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -474,21 +893,28 @@ func (x *NewConnection) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NewConnection.ProtoReflect.Descriptor instead.
-func (*NewConnection) Descriptor() ([]byte, []int) {
+// Deprecated: Use Ping.ProtoReflect.Descriptor instead.
+func (*Ping) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{3}
 }
 
-// WriteDone is sent when writing to a socket has been completed
-// the next step is to read a reply
-type WriteDone struct {
+func (x *Ping) GetSent() int64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+type Pong struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Sent int64 `protobuf:"varint,1,opt,name=sent,proto3" json:"sent,omitempty"` // timestamp from the Ping
 }
 
-func (x *WriteDone) Reset() {
-	*x = WriteDone{}
+func (x *Pong) Reset() {
+	*x = Pong{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -496,13 +922,13 @@ func (x *WriteDone) Reset() {
 	}
 }
 
-func (x *WriteDone) String() string {
+func (x *Pong) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteDone) ProtoMessage() {}
+func (*Pong) ProtoMessage() {}
 
-func (x *WriteDone) ProtoReflect() protoreflect.Message {
+func (x *Pong) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -514,21 +940,34 @@ func (x *WriteDone) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteDone.ProtoReflect.Descriptor instead.
-func (*WriteDone) Descriptor() ([]byte, []int) {
+// Deprecated: Use Pong.ProtoReflect.Descriptor instead.
+func (*Pong) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{4}
 }
 
-// ReadDone is sent when all data has been read from a socket
-// the next step is to write a reply
-type ReadDone struct {
+func (x *Pong) GetSent() int64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+// Handshake is the first message sent by the client when it opens a stream, the
+// server replies with its own Handshake. Features are only used when both sides
+// list them in their capabilities, peers which do not send a Handshake are treated
+// as protocol version 1 with no capabilities.
+type Handshake struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Version      int32    `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`                         // protocol version of the sender
+	MinVersion   int32    `protobuf:"varint,2,opt,name=min_version,json=minVersion,proto3" json:"min_version,omitempty"` // oldest protocol version the sender can communicate with
+	Capabilities []string `protobuf:"bytes,3,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                // optional features supported by the sender
 }
 
-func (x *ReadDone) Reset() {
-	*x = ReadDone{}
+func (x *Handshake) Reset() {
+	*x = Handshake{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -536,13 +975,13 @@ func (x *ReadDone) Reset() {
 	}
 }
 
-func (x *ReadDone) String() string {
+func (x *Handshake) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadDone) ProtoMessage() {}
+func (*Handshake) ProtoMessage() {}
 
-func (x *ReadDone) ProtoReflect() protoreflect.Message {
+func (x *Handshake) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -554,20 +993,47 @@ func (x *ReadDone) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadDone.ProtoReflect.Descriptor instead.
-func (*ReadDone) Descriptor() ([]byte, []int) {
+// Deprecated: Use Handshake.ProtoReflect.Descriptor instead.
+func (*Handshake) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{5}
 }
 
-// Closed is sent when a remote connection is closed
-type Closed struct {
+func (x *Handshake) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Handshake) GetMinVersion() int32 {
+	if x != nil {
+		return x.MinVersion
+	}
+	return 0
+}
+
+func (x *Handshake) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+// Data is a message containing data for a connection, for UDP services each message
+// contains a single datagram
+type Data struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Id          int32       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Data        []byte      `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Compression Compression `protobuf:"varint,3,opt,name=compression,proto3,enum=shipyard.Compression" json:"compression,omitempty"` // encoding of data, chunks which do not compress are sent uncompressed
+	Client      *Client     `protobuf:"bytes,4,opt,name=client,proto3" json:"client,omitempty"`                                      // only sent with the first message for a connection
 }
 
-func (x *Closed) Reset() {
-	*x = Closed{}
+func (x *Data) Reset() {
+	*x = Data{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -575,13 +1041,13 @@ func (x *Closed) Reset() {
 	}
 }
 
-func (x *Closed) String() string {
+func (x *Data) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Closed) ProtoMessage() {}
+func (*Data) ProtoMessage() {}
 
-func (x *Closed) ProtoReflect() protoreflect.Message {
+func (x *Data) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -593,23 +1059,53 @@ func (x *Closed) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Closed.ProtoReflect.Descriptor instead.
-func (*Closed) Descriptor() ([]byte, []int) {
+// Deprecated: Use Data.ProtoReflect.Descriptor instead.
+func (*Data) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{6}
 }
 
-// ExposeRequest is a message indicating that a new TCP Listener should be created
-// ExposeRequests will be replayed when a connection is re-opened
-type ExposeRequest struct {
+func (x *Data) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Data) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Data) GetCompression() Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return Compression_NONE
+}
+
+func (x *Data) GetClient() *Client {
+	if x != nil {
+		return x.Client
+	}
+	return nil
+}
+
+// Client is the address of the client which opened a connection and the address it
+// connected to, the connector which dials the destination sends them in a PROXY protocol
+// header when the service has one
+type Client struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Service *Service `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Addr      string `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	LocalAddr string `protobuf:"bytes,2,opt,name=local_addr,json=localAddr,proto3" json:"local_addr,omitempty"`
 }
 
-func (x *ExposeRequest) Reset() {
-	*x = ExposeRequest{}
+func (x *Client) Reset() {
+	*x = Client{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -617,13 +1113,13 @@ func (x *ExposeRequest) Reset() {
 	}
 }
 
-func (x *ExposeRequest) String() string {
+func (x *Client) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExposeRequest) ProtoMessage() {}
+func (*Client) ProtoMessage() {}
 
-func (x *ExposeRequest) ProtoReflect() protoreflect.Message {
+func (x *Client) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -635,31 +1131,39 @@ func (x *ExposeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExposeRequest.ProtoReflect.Descriptor instead.
-func (*ExposeRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Client.ProtoReflect.Descriptor instead.
+func (*Client) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ExposeRequest) GetService() *Service {
+func (x *Client) GetAddr() string {
 	if x != nil {
-		return x.Service
+		return x.Addr
 	}
-	return nil
+	return ""
 }
 
-// StatusUpdate is sent by the remote connector when the status
-// of a service changes
-type StatusUpdate struct {
+func (x *Client) GetLocalAddr() string {
+	if x != nil {
+		return x.LocalAddr
+	}
+	return ""
+}
+
+// Indicates that a new connection has been received, for proxy services the connection
+// is opened to the destination requested by the client. The connector which dials the
+// destination replies with Connected, or an Error followed by Closed when it can not.
+type NewConnection struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Status  ServiceStatus `protobuf:"varint,1,opt,name=status,proto3,enum=shipyard.ServiceStatus" json:"status,omitempty"`
-	Message string        `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	DestinationAddr string  `protobuf:"bytes,1,opt,name=destination_addr,json=destinationAddr,proto3" json:"destination_addr,omitempty"` // host:port requested by the proxy client
+	Client          *Client `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
 }
 
-func (x *StatusUpdate) Reset() {
-	*x = StatusUpdate{}
+func (x *NewConnection) Reset() {
+	*x = NewConnection{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -667,13 +1171,13 @@ func (x *StatusUpdate) Reset() {
 	}
 }
 
-func (x *StatusUpdate) String() string {
+func (x *NewConnection) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatusUpdate) ProtoMessage() {}
+func (*NewConnection) ProtoMessage() {}
 
-func (x *StatusUpdate) ProtoReflect() protoreflect.Message {
+func (x *NewConnection) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -685,41 +1189,34 @@ func (x *StatusUpdate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatusUpdate.ProtoReflect.Descriptor instead.
-func (*StatusUpdate) Descriptor() ([]byte, []int) {
+// Deprecated: Use NewConnection.ProtoReflect.Descriptor instead.
+func (*NewConnection) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *StatusUpdate) GetStatus() ServiceStatus {
+func (x *NewConnection) GetDestinationAddr() string {
 	if x != nil {
-		return x.Status
+		return x.DestinationAddr
 	}
-	return ServiceStatus_PENDING
+	return ""
 }
 
-func (x *StatusUpdate) GetMessage() string {
+func (x *NewConnection) GetClient() *Client {
 	if x != nil {
-		return x.Message
+		return x.Client
 	}
-	return ""
+	return nil
 }
 
-type Service struct {
+// Connected is sent once the destination for a NewConnection has been dialed
+type Connected struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Id                  string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                   // id for the service
-	Name                string        `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                               // name of the service
-	RemoteConnectorAddr string        `protobuf:"bytes,3,opt,name=remoteConnectorAddr,proto3" json:"remoteConnectorAddr,omitempty"` // address of the remote component for the service
-	DestinationAddr     string        `protobuf:"bytes,4,opt,name=destinationAddr,proto3" json:"destinationAddr,omitempty"`         // address of the service being exposed
-	SourcePort          int32         `protobuf:"varint,5,opt,name=sourcePort,proto3" json:"sourcePort,omitempty"`                  // local port to expose on
-	Type                ServiceType   `protobuf:"varint,6,opt,name=type,proto3,enum=shipyard.ServiceType" json:"type,omitempty"`    // is the service running on this machine or the remote machine
-	Status              ServiceStatus `protobuf:"varint,7,opt,name=status,proto3,enum=shipyard.ServiceStatus" json:"status,omitempty"`
 }
 
-func (x *Service) Reset() {
-	*x = Service{}
+func (x *Connected) Reset() {
+	*x = Connected{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -727,13 +1224,13 @@ func (x *Service) Reset() {
 	}
 }
 
-func (x *Service) String() string {
+func (x *Connected) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Service) ProtoMessage() {}
+func (*Connected) ProtoMessage() {}
 
-func (x *Service) ProtoReflect() protoreflect.Message {
+func (x *Connected) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -745,70 +1242,24 @@ func (x *Service) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Service.ProtoReflect.Descriptor instead.
-func (*Service) Descriptor() ([]byte, []int) {
+// Deprecated: Use Connected.ProtoReflect.Descriptor instead.
+func (*Connected) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *Service) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *Service) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *Service) GetRemoteConnectorAddr() string {
-	if x != nil {
-		return x.RemoteConnectorAddr
-	}
-	return ""
-}
-
-func (x *Service) GetDestinationAddr() string {
-	if x != nil {
-		return x.DestinationAddr
-	}
-	return ""
-}
-
-func (x *Service) GetSourcePort() int32 {
-	if x != nil {
-		return x.SourcePort
-	}
-	return 0
-}
-
-func (x *Service) GetType() ServiceType {
-	if x != nil {
-		return x.Type
-	}
-	return ServiceType_LOCAL
-}
-
-func (x *Service) GetStatus() ServiceStatus {
-	if x != nil {
-		return x.Status
-	}
-	return ServiceStatus_PENDING
-}
-
-type ExposeResponse struct {
+// WriteDone is sent when the peer has finished writing to a socket (half-close),
+// the next step is to read a reply. The receiver closes the write side of its socket
+// once all the messages sent for the connection have been written.
+type WriteDone struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // id of the service
+	Messages int32 `protobuf:"varint,1,opt,name=messages,proto3" json:"messages,omitempty"`
 }
 
-func (x *ExposeResponse) Reset() {
-	*x = ExposeResponse{}
+func (x *WriteDone) Reset() {
+	*x = WriteDone{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -816,13 +1267,13 @@ func (x *ExposeResponse) Reset() {
 	}
 }
 
-func (x *ExposeResponse) String() string {
+func (x *WriteDone) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExposeResponse) ProtoMessage() {}
+func (*WriteDone) ProtoMessage() {}
 
-func (x *ExposeResponse) ProtoReflect() protoreflect.Message {
+func (x *WriteDone) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -834,28 +1285,28 @@ func (x *ExposeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExposeResponse.ProtoReflect.Descriptor instead.
-func (*ExposeResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use WriteDone.ProtoReflect.Descriptor instead.
+func (*WriteDone) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ExposeResponse) GetId() string {
+func (x *WriteDone) GetMessages() int32 {
 	if x != nil {
-		return x.Id
+		return x.Messages
 	}
-	return ""
+	return 0
 }
 
-type DestroyRequest struct {
+// ReadDone is sent when data can no longer be written to a socket, the receiver
+// closes the read side of its socket as no more data can be delivered
+type ReadDone struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 }
 
-func (x *DestroyRequest) Reset() {
-	*x = DestroyRequest{}
+func (x *ReadDone) Reset() {
+	*x = ReadDone{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -863,13 +1314,13 @@ func (x *DestroyRequest) Reset() {
 	}
 }
 
-func (x *DestroyRequest) String() string {
+func (x *ReadDone) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DestroyRequest) ProtoMessage() {}
+func (*ReadDone) ProtoMessage() {}
 
-func (x *DestroyRequest) ProtoReflect() protoreflect.Message {
+func (x *ReadDone) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -881,28 +1332,24 @@ func (x *DestroyRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DestroyRequest.ProtoReflect.Descriptor instead.
-func (*DestroyRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReadDone.ProtoReflect.Descriptor instead.
+func (*ReadDone) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *DestroyRequest) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-type ListResponse struct {
+// Closed is sent when a remote connection is closed, messages contains the number
+// of data messages sent for the connection. Data may arrive on a different stream
+// to the Closed message, the connection is closed once all messages have been received.
+type Closed struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Services []*Service `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	Messages int32 `protobuf:"varint,1,opt,name=messages,proto3" json:"messages,omitempty"`
 }
 
-func (x *ListResponse) Reset() {
-	*x = ListResponse{}
+func (x *Closed) Reset() {
+	*x = Closed{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_server_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -910,13 +1357,13 @@ func (x *ListResponse) Reset() {
 	}
 }
 
-func (x *ListResponse) String() string {
+func (x *Closed) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListResponse) ProtoMessage() {}
+func (*Closed) ProtoMessage() {}
 
-func (x *ListResponse) ProtoReflect() protoreflect.Message {
+func (x *Closed) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -928,16 +1375,1601 @@ func (x *ListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
-func (*ListResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use Closed.ProtoReflect.Descriptor instead.
+func (*Closed) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Closed) GetMessages() int32 {
+	if x != nil {
+		return x.Messages
+	}
+	return 0
+}
+
+// WindowUpdate is sent by the receiver of connection data once data has been
+// written to the destination socket, it returns credit to the sender so that
+// it can send more data for the connection
+type WindowUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Consumed int64 `protobuf:"varint,1,opt,name=consumed,proto3" json:"consumed,omitempty"` // total number of bytes written to the socket for the connection
+}
+
+func (x *WindowUpdate) Reset() {
+	*x = WindowUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WindowUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WindowUpdate) ProtoMessage() {}
+
+func (x *WindowUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WindowUpdate.ProtoReflect.Descriptor instead.
+func (*WindowUpdate) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WindowUpdate) GetConsumed() int64 {
+	if x != nil {
+		return x.Consumed
+	}
+	return 0
+}
+
+// Session is sent by the client when a stream is opened, the server replies with its own
+// Session message. Sessions allow a stream which has been interrupted to be resumed
+// without closing the connections which were using it.
+type Session struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string             `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Resumed     bool               `protobuf:"varint,2,opt,name=resumed,proto3" json:"resumed,omitempty"`                        // set by the server when an existing session has been resumed
+	Connections []*ConnectionState `protobuf:"bytes,3,rep,name=connections,proto3" json:"connections,omitempty"`                 // connections open on the sender
+	ServiceIds  []string           `protobuf:"bytes,4,rep,name=service_ids,json=serviceIds,proto3" json:"service_ids,omitempty"` // services exposed by the client
+	Stream      int32              `protobuf:"varint,5,opt,name=stream,proto3" json:"stream,omitempty"`                          // index of the stream in the pool, additional streams join an existing session
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Session) GetResumed() bool {
+	if x != nil {
+		return x.Resumed
+	}
+	return false
+}
+
+func (x *Session) GetConnections() []*ConnectionState {
+	if x != nil {
+		return x.Connections
+	}
+	return nil
+}
+
+func (x *Session) GetServiceIds() []string {
+	if x != nil {
+		return x.ServiceIds
+	}
+	return nil
+}
+
+func (x *Session) GetStream() int32 {
+	if x != nil {
+		return x.Stream
+	}
+	return 0
+}
+
+// ConnectionState describes the data received for a connection, it is used to
+// replay any data which was lost when the stream was interrupted
+type ConnectionState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServiceId     string `protobuf:"bytes,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	ConnectionId  string `protobuf:"bytes,2,opt,name=connection_id,json=connectionId,proto3" json:"connection_id,omitempty"`
+	NextMessageId int32  `protobuf:"varint,3,opt,name=next_message_id,json=nextMessageId,proto3" json:"next_message_id,omitempty"` // id of the next data message expected
+	Consumed      int64  `protobuf:"varint,4,opt,name=consumed,proto3" json:"consumed,omitempty"`                                  // total number of bytes written to the socket
+}
+
+func (x *ConnectionState) Reset() {
+	*x = ConnectionState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConnectionState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectionState) ProtoMessage() {}
+
+func (x *ConnectionState) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectionState.ProtoReflect.Descriptor instead.
+func (*ConnectionState) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConnectionState) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *ConnectionState) GetConnectionId() string {
+	if x != nil {
+		return x.ConnectionId
+	}
+	return ""
+}
+
+func (x *ConnectionState) GetNextMessageId() int32 {
+	if x != nil {
+		return x.NextMessageId
+	}
+	return 0
+}
+
+func (x *ConnectionState) GetConsumed() int64 {
+	if x != nil {
+		return x.Consumed
+	}
+	return 0
+}
+
+// ExposeRequest is a message indicating that a new TCP Listener should be created
+// ExposeRequests will be replayed when a connection is re-opened
+type ExposeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Service *Service `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+}
+
+func (x *ExposeRequest) Reset() {
+	*x = ExposeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExposeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExposeRequest) ProtoMessage() {}
+
+func (x *ExposeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExposeRequest.ProtoReflect.Descriptor instead.
+func (*ExposeRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExposeRequest) GetService() *Service {
+	if x != nil {
+		return x.Service
+	}
+	return nil
+}
+
+// StatusUpdate is sent by the remote connector when the status
+// of a service changes
+type StatusUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status     ServiceStatus `protobuf:"varint,1,opt,name=status,proto3,enum=shipyard.ServiceStatus" json:"status,omitempty"`
+	Message    string        `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	SourcePort int32         `protobuf:"varint,3,opt,name=source_port,json=sourcePort,proto3" json:"source_port,omitempty"` // port allocated for the listener when the service source_port is 0
+}
+
+func (x *StatusUpdate) Reset() {
+	*x = StatusUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatusUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusUpdate) ProtoMessage() {}
+
+func (x *StatusUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusUpdate.ProtoReflect.Descriptor instead.
+func (*StatusUpdate) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StatusUpdate) GetStatus() ServiceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ServiceStatus_PENDING
+}
+
+func (x *StatusUpdate) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *StatusUpdate) GetSourcePort() int32 {
+	if x != nil {
+		return x.SourcePort
+	}
+	return 0
+}
+
+type Service struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                     string                `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                   // id for the service
+	Name                   string                `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                               // name of the service
+	RemoteConnectorAddr    string                `protobuf:"bytes,3,opt,name=remoteConnectorAddr,proto3" json:"remoteConnectorAddr,omitempty"` // address of the remote component for the service
+	DestinationAddr        string                `protobuf:"bytes,4,opt,name=destinationAddr,proto3" json:"destinationAddr,omitempty"`         // address of the service being exposed
+	SourcePort             int32                 `protobuf:"varint,5,opt,name=sourcePort,proto3" json:"sourcePort,omitempty"`                  // local port to expose on, 0 allocates a free port
+	Type                   ServiceType           `protobuf:"varint,6,opt,name=type,proto3,enum=shipyard.ServiceType" json:"type,omitempty"`    // is the service running on this machine or the remote machine
+	Status                 ServiceStatus         `protobuf:"varint,7,opt,name=status,proto3,enum=shipyard.ServiceStatus" json:"status,omitempty"`
+	Protocol               ServiceProtocol       `protobuf:"varint,8,opt,name=protocol,proto3,enum=shipyard.ServiceProtocol" json:"protocol,omitempty"`                                                                 // transport protocol for the service
+	Link                   *LinkStatus           `protobuf:"bytes,9,opt,name=link,proto3" json:"link,omitempty"`                                                                                                        // status of the link to the remote connector, only set by ListServices
+	Compression            Compression           `protobuf:"varint,10,opt,name=compression,proto3,enum=shipyard.Compression" json:"compression,omitempty"`                                                              // compression for data sent over the stream, used when both connectors support it
+	CompressionStats       *CompressionStats     `protobuf:"bytes,11,opt,name=compression_stats,json=compressionStats,proto3" json:"compression_stats,omitempty"`                                                       // only set by ListServices
+	MessageSize            int32                 `protobuf:"varint,12,opt,name=message_size,json=messageSize,proto3" json:"message_size,omitempty"`                                                                     // maximum data payload sent in a single message, 0 uses the connector default
+	BindAddress            string                `protobuf:"bytes,13,opt,name=bind_address,json=bindAddress,proto3" json:"bind_address,omitempty"`                                                                      // address the listener for the service binds to, empty uses the connector default
+	DestinationAddrs       []string              `protobuf:"bytes,14,rep,name=destination_addrs,json=destinationAddrs,proto3" json:"destination_addrs,omitempty"`                                                       // additional destinations, connections are balanced across destinationAddr and these
+	LoadBalancing          LoadBalancing         `protobuf:"varint,15,opt,name=load_balancing,json=loadBalancing,proto3,enum=shipyard.LoadBalancing" json:"load_balancing,omitempty"`                                   // how connections are balanced across the destinations
+	HealthCheck            *HealthCheck          `protobuf:"bytes,16,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`                                                                      // active health checks for the destinations, not set disables health checks
+	Endpoints              []*EndpointStatus     `protobuf:"bytes,17,rep,name=endpoints,proto3" json:"endpoints,omitempty"`                                                                                             // only set by ListServices on the connector which dials the destinations
+	DialTimeout            int64                 `protobuf:"varint,18,opt,name=dial_timeout,json=dialTimeout,proto3" json:"dial_timeout,omitempty"`                                                                     // time to wait for a connection to a destination in nanoseconds, 0 uses the connector default
+	DialRetries            int32                 `protobuf:"varint,19,opt,name=dial_retries,json=dialRetries,proto3" json:"dial_retries,omitempty"`                                                                     // number of times a failed dial to the destinations is retried before the connection is closed
+	DialStats              *DialStats            `protobuf:"bytes,20,opt,name=dial_stats,json=dialStats,proto3" json:"dial_stats,omitempty"`                                                                            // only set by ListServices
+	IdleTimeout            int64                 `protobuf:"varint,21,opt,name=idle_timeout,json=idleTimeout,proto3" json:"idle_timeout,omitempty"`                                                                     // close connections with no data in either direction for this time in nanoseconds, 0 disables
+	MaxLifetime            int64                 `protobuf:"varint,22,opt,name=max_lifetime,json=maxLifetime,proto3" json:"max_lifetime,omitempty"`                                                                     // close connections which have been open for this time in nanoseconds, 0 disables
+	MaxConnections         int32                 `protobuf:"varint,23,opt,name=max_connections,json=maxConnections,proto3" json:"max_connections,omitempty"`                                                            // maximum number of concurrent connections accepted by the listener, 0 is unlimited
+	ConnectionLimitAction  ConnectionLimitAction `protobuf:"varint,24,opt,name=connection_limit_action,json=connectionLimitAction,proto3,enum=shipyard.ConnectionLimitAction" json:"connection_limit_action,omitempty"` // what happens to new connections once max_connections is reached
+	RateLimit              *RateLimit            `protobuf:"bytes,25,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`                                                                            // bandwidth limit for the data sent and received by the connector where the service is exposed
+	ThrottleStats          *ThrottleStats        `protobuf:"bytes,26,opt,name=throttle_stats,json=throttleStats,proto3" json:"throttle_stats,omitempty"`                                                                // only set by ListServices
+	Match                  *Match                `protobuf:"bytes,27,opt,name=match,proto3" json:"match,omitempty"`                                                                                                     // route HTTP requests, only requests which match are sent to the destination
+	DefaultDestinationAddr string                `protobuf:"bytes,28,opt,name=default_destination_addr,json=defaultDestinationAddr,proto3" json:"default_destination_addr,omitempty"`                                   // destination for requests which do not match, dialed by the connector with the listener
+	Hosts                  []string              `protobuf:"bytes,29,rep,name=hosts,proto3" json:"hosts,omitempty"`                                                                                                     // virtual hosts, services with hosts share the listener for the port and are routed by the Host header or TLS server name
+	Proxy                  ProxyProtocol         `protobuf:"varint,30,opt,name=proxy,proto3,enum=shipyard.ProxyProtocol" json:"proxy,omitempty"`                                                                        // the listener is a proxy and each connection is opened to the destination requested by the client, only for remote services
+	ProxyHeader            ProxyHeader           `protobuf:"varint,31,opt,name=proxy_header,json=proxyHeader,proto3,enum=shipyard.ProxyHeader" json:"proxy_header,omitempty"`                                           // PROXY protocol header sent to the destination before the data for a connection
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Service) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Service) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Service) GetRemoteConnectorAddr() string {
+	if x != nil {
+		return x.RemoteConnectorAddr
+	}
+	return ""
+}
+
+func (x *Service) GetDestinationAddr() string {
+	if x != nil {
+		return x.DestinationAddr
+	}
+	return ""
+}
+
+func (x *Service) GetSourcePort() int32 {
+	if x != nil {
+		return x.SourcePort
+	}
+	return 0
+}
+
+func (x *Service) GetType() ServiceType {
+	if x != nil {
+		return x.Type
+	}
+	return ServiceType_LOCAL
+}
+
+func (x *Service) GetStatus() ServiceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ServiceStatus_PENDING
+}
+
+func (x *Service) GetProtocol() ServiceProtocol {
+	if x != nil {
+		return x.Protocol
+	}
+	return ServiceProtocol_TCP
+}
+
+func (x *Service) GetLink() *LinkStatus {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+func (x *Service) GetCompression() Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return Compression_NONE
+}
+
+func (x *Service) GetCompressionStats() *CompressionStats {
+	if x != nil {
+		return x.CompressionStats
+	}
+	return nil
+}
+
+func (x *Service) GetMessageSize() int32 {
+	if x != nil {
+		return x.MessageSize
+	}
+	return 0
+}
+
+func (x *Service) GetBindAddress() string {
+	if x != nil {
+		return x.BindAddress
+	}
+	return ""
+}
+
+func (x *Service) GetDestinationAddrs() []string {
+	if x != nil {
+		return x.DestinationAddrs
+	}
+	return nil
+}
+
+func (x *Service) GetLoadBalancing() LoadBalancing {
+	if x != nil {
+		return x.LoadBalancing
+	}
+	return LoadBalancing_ROUND_ROBIN
+}
+
+func (x *Service) GetHealthCheck() *HealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
+func (x *Service) GetEndpoints() []*EndpointStatus {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+func (x *Service) GetDialTimeout() int64 {
+	if x != nil {
+		return x.DialTimeout
+	}
+	return 0
+}
+
+func (x *Service) GetDialRetries() int32 {
+	if x != nil {
+		return x.DialRetries
+	}
+	return 0
+}
+
+func (x *Service) GetDialStats() *DialStats {
+	if x != nil {
+		return x.DialStats
+	}
+	return nil
+}
+
+func (x *Service) GetIdleTimeout() int64 {
+	if x != nil {
+		return x.IdleTimeout
+	}
+	return 0
+}
+
+func (x *Service) GetMaxLifetime() int64 {
+	if x != nil {
+		return x.MaxLifetime
+	}
+	return 0
+}
+
+func (x *Service) GetMaxConnections() int32 {
+	if x != nil {
+		return x.MaxConnections
+	}
+	return 0
+}
+
+func (x *Service) GetConnectionLimitAction() ConnectionLimitAction {
+	if x != nil {
+		return x.ConnectionLimitAction
+	}
+	return ConnectionLimitAction_REJECT
+}
+
+func (x *Service) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *Service) GetThrottleStats() *ThrottleStats {
+	if x != nil {
+		return x.ThrottleStats
+	}
+	return nil
+}
+
+func (x *Service) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+func (x *Service) GetDefaultDestinationAddr() string {
+	if x != nil {
+		return x.DefaultDestinationAddr
+	}
+	return ""
+}
+
+func (x *Service) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *Service) GetProxy() ProxyProtocol {
+	if x != nil {
+		return x.Proxy
+	}
+	return ProxyProtocol_NO_PROXY
+}
+
+func (x *Service) GetProxyHeader() ProxyHeader {
+	if x != nil {
+		return x.ProxyHeader
+	}
+	return ProxyHeader_NO_PROXY_HEADER
+}
+
+// RateLimit is a token bucket limit for the bytes sent and received
+type RateLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BytesPerSecond int64 `protobuf:"varint,1,opt,name=bytes_per_second,json=bytesPerSecond,proto3" json:"bytes_per_second,omitempty"` // rate the bucket is refilled, 0 removes the limit
+	Burst          int64 `protobuf:"varint,2,opt,name=burst,proto3" json:"burst,omitempty"`                                           // size of the bucket in bytes, defaults to bytes_per_second
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RateLimit) GetBytesPerSecond() int64 {
+	if x != nil {
+		return x.BytesPerSecond
+	}
+	return 0
+}
+
+func (x *RateLimit) GetBurst() int64 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+// RateLimitRequest sets the rate limit for a service, or for the link to the remote
+// connector when service_id is not set
+type RateLimitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServiceId           string     `protobuf:"bytes,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	RemoteConnectorAddr string     `protobuf:"bytes,2,opt,name=remote_connector_addr,json=remoteConnectorAddr,proto3" json:"remote_connector_addr,omitempty"`
+	Limit               *RateLimit `protobuf:"bytes,3,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *RateLimitRequest) Reset() {
+	*x = RateLimitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RateLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimitRequest) ProtoMessage() {}
+
+func (x *RateLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimitRequest.ProtoReflect.Descriptor instead.
+func (*RateLimitRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RateLimitRequest) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *RateLimitRequest) GetRemoteConnectorAddr() string {
+	if x != nil {
+		return x.RemoteConnectorAddr
+	}
+	return ""
+}
+
+func (x *RateLimitRequest) GetLimit() *RateLimit {
+	if x != nil {
+		return x.Limit
+	}
+	return nil
+}
+
+// ThrottleStats are the totals for data which has been delayed by a rate limit
+type ThrottleStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bytes         int64 `protobuf:"varint,1,opt,name=bytes,proto3" json:"bytes,omitempty"`                                      // bytes counted by the rate limit
+	ThrottledTime int64 `protobuf:"varint,2,opt,name=throttled_time,json=throttledTime,proto3" json:"throttled_time,omitempty"` // total time data has waited for the rate limit in nanoseconds
+	Waiting       int32 `protobuf:"varint,3,opt,name=waiting,proto3" json:"waiting,omitempty"`                                  // connections currently waiting for the rate limit
+}
+
+func (x *ThrottleStats) Reset() {
+	*x = ThrottleStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThrottleStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThrottleStats) ProtoMessage() {}
+
+func (x *ThrottleStats) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThrottleStats.ProtoReflect.Descriptor instead.
+func (*ThrottleStats) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ThrottleStats) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *ThrottleStats) GetThrottledTime() int64 {
+	if x != nil {
+		return x.ThrottledTime
+	}
+	return 0
+}
+
+func (x *ThrottleStats) GetWaiting() int32 {
+	if x != nil {
+		return x.Waiting
+	}
+	return 0
+}
+
+// DialStats are the totals for connections which could not be made to the destinations of a service,
+// the connector which dials the destinations reports dial failures back to the connector which accepted
+// the connection and both count the failure
+type DialStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Failures    int64  `protobuf:"varint,1,opt,name=failures,proto3" json:"failures,omitempty"`                          // connections closed as no destination could be dialed
+	Retries     int64  `protobuf:"varint,2,opt,name=retries,proto3" json:"retries,omitempty"`                            // dial attempts which were retried, only counted by the connector which dials
+	LastError   string `protobuf:"bytes,3,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`        // error from the last failed dial
+	LastFailure int64  `protobuf:"varint,4,opt,name=last_failure,json=lastFailure,proto3" json:"last_failure,omitempty"` // time of the last failed dial in unix nanoseconds
+}
+
+func (x *DialStats) Reset() {
+	*x = DialStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DialStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DialStats) ProtoMessage() {}
+
+func (x *DialStats) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DialStats.ProtoReflect.Descriptor instead.
+func (*DialStats) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DialStats) GetFailures() int64 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *DialStats) GetRetries() int64 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *DialStats) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *DialStats) GetLastFailure() int64 {
+	if x != nil {
+		return x.LastFailure
+	}
+	return 0
+}
+
+// HealthCheck configures active TCP health checks for the destinations of a service
+type HealthCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Interval           int64 `protobuf:"varint,1,opt,name=interval,proto3" json:"interval,omitempty"`                                               // time between checks in nanoseconds
+	Timeout            int64 `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`                                                 // time to wait for a connection in nanoseconds, defaults to the interval
+	UnhealthyThreshold int32 `protobuf:"varint,3,opt,name=unhealthy_threshold,json=unhealthyThreshold,proto3" json:"unhealthy_threshold,omitempty"` // consecutive failed checks before a destination is unhealthy, defaults to 3
+	HealthyThreshold   int32 `protobuf:"varint,4,opt,name=healthy_threshold,json=healthyThreshold,proto3" json:"healthy_threshold,omitempty"`       // consecutive passed checks before a destination is healthy again, defaults to 1
+}
+
+func (x *HealthCheck) Reset() {
+	*x = HealthCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HealthCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheck) ProtoMessage() {}
+
+func (x *HealthCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
+func (*HealthCheck) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *HealthCheck) GetInterval() int64 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *HealthCheck) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *HealthCheck) GetUnhealthyThreshold() int32 {
+	if x != nil {
+		return x.UnhealthyThreshold
+	}
+	return 0
+}
+
+func (x *HealthCheck) GetHealthyThreshold() int32 {
+	if x != nil {
+		return x.HealthyThreshold
+	}
+	return 0
+}
+
+// EndpointStatus is the health of a destination address for a service
+type EndpointStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Addr              string `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Healthy           bool   `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"` // passing the active health checks
+	Ejected           bool   `protobuf:"varint,3,opt,name=ejected,proto3" json:"ejected,omitempty"` // not used after consecutive dial failures
+	ActiveConnections int64  `protobuf:"varint,4,opt,name=active_connections,json=activeConnections,proto3" json:"active_connections,omitempty"`
+	Failures          int32  `protobuf:"varint,5,opt,name=failures,proto3" json:"failures,omitempty"`                   // consecutive dial failures
+	LastError         string `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"` // error from the last failed dial or health check
+}
+
+func (x *EndpointStatus) Reset() {
+	*x = EndpointStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EndpointStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointStatus) ProtoMessage() {}
+
+func (x *EndpointStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndpointStatus.ProtoReflect.Descriptor instead.
+func (*EndpointStatus) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *EndpointStatus) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *EndpointStatus) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *EndpointStatus) GetEjected() bool {
+	if x != nil {
+		return x.Ejected
+	}
+	return false
+}
+
+func (x *EndpointStatus) GetActiveConnections() int64 {
+	if x != nil {
+		return x.ActiveConnections
+	}
+	return 0
+}
+
+func (x *EndpointStatus) GetFailures() int32 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *EndpointStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+// CompressionStats are the totals for the data sent by this connector for a service
+type CompressionStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BytesIn  int64 `protobuf:"varint,1,opt,name=bytes_in,json=bytesIn,proto3" json:"bytes_in,omitempty"`    // bytes read from connections
+	BytesOut int64 `protobuf:"varint,2,opt,name=bytes_out,json=bytesOut,proto3" json:"bytes_out,omitempty"` // bytes sent to the remote after compression
+	Skipped  int64 `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`                   // chunks which were sent uncompressed as they did not compress
+}
+
+func (x *CompressionStats) Reset() {
+	*x = CompressionStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompressionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressionStats) ProtoMessage() {}
+
+func (x *CompressionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressionStats.ProtoReflect.Descriptor instead.
+func (*CompressionStats) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CompressionStats) GetBytesIn() int64 {
+	if x != nil {
+		return x.BytesIn
+	}
+	return 0
+}
+
+func (x *CompressionStats) GetBytesOut() int64 {
+	if x != nil {
+		return x.BytesOut
+	}
+	return 0
+}
+
+func (x *CompressionStats) GetSkipped() int64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+// LinkStatus is the health of the stream between two connectors
+type LinkStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoundTripTime    int64          `protobuf:"varint,1,opt,name=round_trip_time,json=roundTripTime,proto3" json:"round_trip_time,omitempty"`        // last measured round trip time in nanoseconds
+	MissedHeartbeats int32          `protobuf:"varint,2,opt,name=missed_heartbeats,json=missedHeartbeats,proto3" json:"missed_heartbeats,omitempty"` // number of consecutive heartbeats without a reply
+	LastHeartbeat    int64          `protobuf:"varint,3,opt,name=last_heartbeat,json=lastHeartbeat,proto3" json:"last_heartbeat,omitempty"`          // time the last heartbeat reply was received in unix nanoseconds
+	State            LinkState      `protobuf:"varint,4,opt,name=state,proto3,enum=shipyard.LinkState" json:"state,omitempty"`
+	Attempts         int32          `protobuf:"varint,5,opt,name=attempts,proto3" json:"attempts,omitempty"`                               // number of failed connection attempts since the link was last connected
+	LastError        string         `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`             // error from the last failed connection attempt or stream
+	NextAttempt      int64          `protobuf:"varint,7,opt,name=next_attempt,json=nextAttempt,proto3" json:"next_attempt,omitempty"`      // time of the next connection attempt in unix nanoseconds
+	RateLimit        *RateLimit     `protobuf:"bytes,8,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`             // bandwidth limit for the link
+	ThrottleStats    *ThrottleStats `protobuf:"bytes,9,opt,name=throttle_stats,json=throttleStats,proto3" json:"throttle_stats,omitempty"` // only set when the link has a rate limit
+}
+
+func (x *LinkStatus) Reset() {
+	*x = LinkStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkStatus) ProtoMessage() {}
+
+func (x *LinkStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkStatus.ProtoReflect.Descriptor instead.
+func (*LinkStatus) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *LinkStatus) GetRoundTripTime() int64 {
+	if x != nil {
+		return x.RoundTripTime
+	}
+	return 0
+}
+
+func (x *LinkStatus) GetMissedHeartbeats() int32 {
+	if x != nil {
+		return x.MissedHeartbeats
+	}
+	return 0
+}
+
+func (x *LinkStatus) GetLastHeartbeat() int64 {
+	if x != nil {
+		return x.LastHeartbeat
+	}
+	return 0
+}
+
+func (x *LinkStatus) GetState() LinkState {
+	if x != nil {
+		return x.State
+	}
+	return LinkState_DISCONNECTED
+}
+
+func (x *LinkStatus) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *LinkStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *LinkStatus) GetNextAttempt() int64 {
+	if x != nil {
+		return x.NextAttempt
+	}
+	return 0
+}
+
+func (x *LinkStatus) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *LinkStatus) GetThrottleStats() *ThrottleStats {
+	if x != nil {
+		return x.ThrottleStats
+	}
+	return nil
+}
+
+type ExposeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // id of the service
+}
+
+func (x *ExposeResponse) Reset() {
+	*x = ExposeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExposeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExposeResponse) ProtoMessage() {}
+
+func (x *ExposeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExposeResponse.ProtoReflect.Descriptor instead.
+func (*ExposeResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ExposeResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DestroyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DestroyRequest) Reset() {
+	*x = DestroyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DestroyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestroyRequest) ProtoMessage() {}
+
+func (x *DestroyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestroyRequest.ProtoReflect.Descriptor instead.
+func (*DestroyRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DestroyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Services []*Service `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *ListResponse) GetServices() []*Service {
 	if x != nil {
-		return x.Services
+		return x.Services
+	}
+	return nil
+}
+
+// Match routes HTTP requests received by the listener for a service, requests which
+// match are sent to the destination for the service and all other requests are sent to
+// the default destination. When both http and grpc are set a request must match both.
+type Match struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Http *Http `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
+	Grpc *Grpc `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
+}
+
+func (x *Match) Reset() {
+	*x = Match{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Match) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Match) ProtoMessage() {}
+
+func (x *Match) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Match.ProtoReflect.Descriptor instead.
+func (*Match) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *Match) GetHttp() *Http {
+	if x != nil {
+		return x.Http
+	}
+	return nil
+}
+
+func (x *Match) GetGrpc() *Grpc {
+	if x != nil {
+		return x.Grpc
+	}
+	return nil
+}
+
+// Http matches requests on the headers and the path, all rules must match
+type Http struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Headers    []*Header `protobuf:"bytes,1,rep,name=headers,proto3" json:"headers,omitempty"`
+	PathPrefix string    `protobuf:"bytes,2,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	PathExact  string    `protobuf:"bytes,3,opt,name=path_exact,json=pathExact,proto3" json:"path_exact,omitempty"`
+}
+
+func (x *Http) Reset() {
+	*x = Http{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Http) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Http) ProtoMessage() {}
+
+func (x *Http) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Http.ProtoReflect.Descriptor instead.
+func (*Http) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Http) GetHeaders() []*Header {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Http) GetPathPrefix() string {
+	if x != nil {
+		return x.PathPrefix
+	}
+	return ""
+}
+
+func (x *Http) GetPathExact() string {
+	if x != nil {
+		return x.PathExact
+	}
+	return ""
+}
+
+// Header matches a request header by name, when exact and prefix are not set the
+// header only needs to be present
+type Header struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Exact  string `protobuf:"bytes,2,opt,name=exact,proto3" json:"exact,omitempty"`
+	Prefix string `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+}
+
+func (x *Header) Reset() {
+	*x = Header{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Header) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Header) ProtoMessage() {}
+
+func (x *Header) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Header.ProtoReflect.Descriptor instead.
+func (*Header) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *Header) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Header) GetExact() string {
+	if x != nil {
+		return x.Exact
+	}
+	return ""
+}
+
+func (x *Header) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+// Grpc matches gRPC requests, an empty service or method matches any
+type Grpc struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"` // fully qualified service name e.g. helloworld.Greeter
+	Method  string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+}
+
+func (x *Grpc) Reset() {
+	*x = Grpc{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Grpc) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Grpc) ProtoMessage() {}
+
+func (x *Grpc) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Grpc.ProtoReflect.Descriptor instead.
+func (*Grpc) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Grpc) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Grpc) GetMethod() string {
+	if x != nil {
+		return x.Method
 	}
-	return nil
+	return ""
 }
 
 var File_server_proto protoreflect.FileDescriptor
@@ -947,7 +2979,7 @@ var file_server_proto_rawDesc = []byte{
 	0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x1a, 0x17, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
 	0x2f, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x22, 0x0d, 0x0a, 0x0b, 0x4e, 0x75, 0x6c, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x22, 0xd7, 0x04, 0x0a, 0x08, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a,
+	0x22, 0x84, 0x07, 0x0a, 0x08, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a,
 	0x0a, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x09, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d,
 	0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
@@ -978,77 +3010,359 @@ var file_server_proto_rawDesc = []byte{
 	0x73, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
 	0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
 	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2b, 0x0a, 0x04, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x0b,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e,
-	0x4e, 0x75, 0x6c, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x04, 0x70,
-	0x69, 0x6e, 0x67, 0x12, 0x2a, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0c, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42,
-	0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x2a, 0x0a, 0x04, 0x44, 0x61,
-	0x74, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
-	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x0f, 0x0a, 0x0d, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e,
-	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x0b, 0x0a, 0x09, 0x57, 0x72, 0x69, 0x74, 0x65,
-	0x44, 0x6f, 0x6e, 0x65, 0x22, 0x0a, 0x0a, 0x08, 0x52, 0x65, 0x61, 0x64, 0x44, 0x6f, 0x6e, 0x65,
-	0x22, 0x08, 0x0a, 0x06, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x22, 0x3c, 0x0a, 0x0d, 0x45, 0x78,
-	0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x07, 0x73,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73,
-	0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x52,
-	0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x22, 0x59, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79,
-	0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x22, 0x85, 0x02, 0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
-	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x6e,
-	0x6e, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x41, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x6f,
-	0x72, 0x41, 0x64, 0x64, 0x72, 0x12, 0x28, 0x0a, 0x0f, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
-	0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x12,
-	0x1e, 0x0a, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x12,
-	0x29, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e,
-	0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x68, 0x69,
-	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x20, 0x0a, 0x0e, 0x45,
-	0x78, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x20, 0x0a,
-	0x0e, 0x44, 0x65, 0x73, 0x74, 0x72, 0x6f, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22,
-	0x3d, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x2d, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x11, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x2a, 0x24,
-	0x0a, 0x0b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x09, 0x0a,
-	0x05, 0x4c, 0x4f, 0x43, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x4d, 0x4f,
-	0x54, 0x45, 0x10, 0x01, 0x2a, 0x35, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47,
-	0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x01,
-	0x12, 0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x32, 0x92, 0x02, 0x0a, 0x10,
-	0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x12, 0x38, 0x0a, 0x0a, 0x4f, 0x70, 0x65, 0x6e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x12,
-	0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x61,
-	0x74, 0x61, 0x1a, 0x12, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4f, 0x70,
-	0x65, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x28, 0x01, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0d, 0x45, 0x78,
-	0x70, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x17, 0x2e, 0x73, 0x68,
-	0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e,
-	0x45, 0x78, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41,
-	0x0a, 0x0e, 0x44, 0x65, 0x73, 0x74, 0x72, 0x6f, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x12, 0x18, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x44, 0x65, 0x73, 0x74,
-	0x72, 0x6f, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x73, 0x68, 0x69,
-	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4e, 0x75, 0x6c, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x12, 0x3d, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x73, 0x12, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4e, 0x75, 0x6c,
-	0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x16, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79,
-	0x61, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x04, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e,
+	0x50, 0x69, 0x6e, 0x67, 0x48, 0x00, 0x52, 0x04, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x2a, 0x0a, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48,
+	0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x3d, 0x0a, 0x0d, 0x77, 0x69, 0x6e, 0x64,
+	0x6f, 0x77, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x57, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x77, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2d, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79,
+	0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x07, 0x73,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x33, 0x0a, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x68,
+	0x61, 0x6b, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69, 0x70,
+	0x79, 0x61, 0x72, 0x64, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x48, 0x00,
+	0x52, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x12, 0x24, 0x0a, 0x04, 0x70,
+	0x6f, 0x6e, 0x67, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x69, 0x70,
+	0x79, 0x61, 0x72, 0x64, 0x2e, 0x50, 0x6f, 0x6e, 0x67, 0x48, 0x00, 0x52, 0x04, 0x70, 0x6f, 0x6e,
+	0x67, 0x12, 0x34, 0x0a, 0x0a, 0x67, 0x6f, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x77, 0x61, 0x79, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64,
+	0x2e, 0x47, 0x6f, 0x69, 0x6e, 0x67, 0x41, 0x77, 0x61, 0x79, 0x48, 0x00, 0x52, 0x09, 0x67, 0x6f,
+	0x69, 0x6e, 0x67, 0x41, 0x77, 0x61, 0x79, 0x12, 0x33, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69,
+	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x48,
+	0x00, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x42, 0x09, 0x0a, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x27, 0x0a, 0x09, 0x47, 0x6f, 0x69, 0x6e, 0x67,
+	0x41, 0x77, 0x61, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65,
+	0x22, 0x1a, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x6e, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x6e, 0x74, 0x22, 0x1a, 0x0a, 0x04,
+	0x50, 0x6f, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x6e, 0x74, 0x22, 0x6a, 0x0a, 0x09, 0x48, 0x61, 0x6e, 0x64,
+	0x73, 0x68, 0x61, 0x6b, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x1f, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6d, 0x69, 0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x22, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x69, 0x65, 0x73, 0x22, 0x8d, 0x01, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x12, 0x37, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72,
+	0x64, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x06, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x73, 0x68, 0x69,
+	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x22, 0x3b, 0x0a, 0x06, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64,
+	0x64, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x64, 0x64,
+	0x72, 0x22, 0x64, 0x0a, 0x0d, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x64, 0x65,
+	0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x12, 0x28, 0x0a,
+	0x06, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52,
+	0x06, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x22, 0x0b, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x22, 0x27, 0x0a, 0x09, 0x57, 0x72, 0x69, 0x74, 0x65, 0x44, 0x6f, 0x6e,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x0a, 0x0a,
+	0x08, 0x52, 0x65, 0x61, 0x64, 0x44, 0x6f, 0x6e, 0x65, 0x22, 0x24, 0x0a, 0x06, 0x43, 0x6c, 0x6f,
+	0x73, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22,
+	0x2a, 0x0a, 0x0c, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12,
+	0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x64, 0x22, 0xa9, 0x01, 0x0a, 0x07,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6d,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65,
+	0x64, 0x12, 0x3b, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72,
+	0x64, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x22, 0x99, 0x01, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x73, 0x75,
+	0x6d, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x73, 0x75,
+	0x6d, 0x65, 0x64, 0x22, 0x3c, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64,
+	0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x22, 0x7a, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x17, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x22, 0xa3, 0x0b,
+	0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x30, 0x0a,
+	0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x41, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x41, 0x64, 0x64, 0x72, 0x12,
+	0x28, 0x0a, 0x0f, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64,
+	0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x29, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61,
+	0x72, 0x64, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61,
+	0x72, 0x64, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x28, 0x0a, 0x04,
+	0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x68, 0x69,
+	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x37, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x73, 0x68,
+	0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x47, 0x0a, 0x11, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x68, 0x69,
+	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x62,
+	0x69, 0x6e, 0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x62, 0x69, 0x6e, 0x64, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x2b,
+	0x0a, 0x11, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x64, 0x65, 0x73, 0x74, 0x69,
+	0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x73, 0x12, 0x3e, 0x0a, 0x0e, 0x6c,
+	0x6f, 0x61, 0x64, 0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4c,
+	0x6f, 0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x52, 0x0d, 0x6c, 0x6f,
+	0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x12, 0x38, 0x0a, 0x0c, 0x68,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x10, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x48, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x36, 0x0a, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x73, 0x18, 0x11, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79,
+	0x61, 0x72, 0x64, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x69, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x12, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x64, 0x69, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x12, 0x21, 0x0a, 0x0c, 0x64, 0x69, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x13, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x64, 0x69, 0x61, 0x6c, 0x52, 0x65, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x12, 0x32, 0x0a, 0x0a, 0x64, 0x69, 0x61, 0x6c, 0x5f, 0x73, 0x74, 0x61, 0x74,
+	0x73, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61,
+	0x72, 0x64, 0x2e, 0x44, 0x69, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x09, 0x64, 0x69,
+	0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x64, 0x6c, 0x65, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x69,
+	0x64, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61,
+	0x78, 0x5f, 0x6c, 0x69, 0x66, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x6d, 0x61, 0x78, 0x4c, 0x69, 0x66, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a,
+	0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x17, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x57, 0x0a, 0x17, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61,
+	0x72, 0x64, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x6d,
+	0x69, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x15, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x32, 0x0a, 0x0a, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x19, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x52,
+	0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x09, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x12, 0x3e, 0x0a, 0x0e, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x5f,
+	0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x68,
+	0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x54, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x52, 0x0d, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x1b, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x38, 0x0a, 0x18, 0x64, 0x65,
+	0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x16, 0x64, 0x65,
+	0x66, 0x61, 0x75, 0x6c, 0x74, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x41, 0x64, 0x64, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x1d, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x05, 0x70, 0x72,
+	0x6f, 0x78, 0x79, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x68, 0x69, 0x70,
+	0x79, 0x61, 0x72, 0x64, 0x2e, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x12, 0x38, 0x0a, 0x0c, 0x70, 0x72, 0x6f,
+	0x78, 0x79, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x50, 0x72, 0x6f, 0x78, 0x79,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x22, 0x4b, 0x0a, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x12, 0x28, 0x0a, 0x10, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x75,
+	0x72, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74,
+	0x22, 0x90, 0x01, 0x0a, 0x10, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x49, 0x64, 0x12, 0x32, 0x0a, 0x15, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x63,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x41, 0x64, 0x64, 0x72, 0x12, 0x29, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61,
+	0x72, 0x64, 0x2e, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x22, 0x66, 0x0a, 0x0d, 0x54, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x68,
+	0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0d, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x64, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x77, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x22, 0x83, 0x01, 0x0a, 0x09,
+	0x44, 0x69, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x61, 0x69,
+	0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x66, 0x61, 0x69,
+	0x6c, 0x75, 0x72, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x21,
+	0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72,
+	0x65, 0x22, 0xa1, 0x01, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x18, 0x0a,
+	0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2f, 0x0a, 0x13, 0x75, 0x6e, 0x68, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x75, 0x6e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x54,
+	0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x68, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x10, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x54, 0x68, 0x72, 0x65,
+	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x22, 0xc2, 0x01, 0x0a, 0x0e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64, 0x64, 0x72, 0x12, 0x18, 0x0a, 0x07,
+	0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x12, 0x2d, 0x0a, 0x12, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x61, 0x63,
+	0x74, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c,
+	0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x64, 0x0a, 0x10, 0x43, 0x6f,
+	0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x19,
+	0x0a, 0x08, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x62, 0x79, 0x74, 0x65, 0x73, 0x49, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x4f, 0x75, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64,
+	0x22, 0x85, 0x03, 0x0a, 0x0a, 0x4c, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x26, 0x0a, 0x0f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x72, 0x69, 0x70, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x54,
+	0x72, 0x69, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x6d, 0x69, 0x73, 0x73, 0x65,
+	0x64, 0x5f, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x10, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62,
+	0x65, 0x61, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x68, 0x65, 0x61,
+	0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x6c, 0x61,
+	0x73, 0x74, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x29, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69,
+	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
+	0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
+	0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
+	0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x41, 0x74, 0x74,
+	0x65, 0x6d, 0x70, 0x74, 0x12, 0x32, 0x0a, 0x0a, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79,
+	0x61, 0x72, 0x64, 0x2e, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x09, 0x72,
+	0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x3e, 0x0a, 0x0e, 0x74, 0x68, 0x72, 0x6f,
+	0x74, 0x74, 0x6c, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x54, 0x68, 0x72, 0x6f,
+	0x74, 0x74, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x0d, 0x74, 0x68, 0x72, 0x6f, 0x74,
+	0x74, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x22, 0x20, 0x0a, 0x0e, 0x45, 0x78, 0x70, 0x6f,
+	0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x20, 0x0a, 0x0e, 0x44, 0x65,
+	0x73, 0x74, 0x72, 0x6f, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x3d, 0x0a, 0x0c,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x08,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0x4f, 0x0a, 0x05, 0x4d,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x22, 0x0a, 0x04, 0x68, 0x74, 0x74, 0x70, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x48, 0x74,
+	0x74, 0x70, 0x52, 0x04, 0x68, 0x74, 0x74, 0x70, 0x12, 0x22, 0x0a, 0x04, 0x67, 0x72, 0x70, 0x63,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72,
+	0x64, 0x2e, 0x47, 0x72, 0x70, 0x63, 0x52, 0x04, 0x67, 0x72, 0x70, 0x63, 0x22, 0x72, 0x0a, 0x04,
+	0x48, 0x74, 0x74, 0x70, 0x12, 0x2a, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64,
+	0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73,
+	0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x61, 0x74, 0x68, 0x50, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x65, 0x78, 0x61, 0x63, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x74, 0x68, 0x45, 0x78, 0x61, 0x63, 0x74,
+	0x22, 0x4a, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x78, 0x61, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x78, 0x61, 0x63, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x22, 0x38, 0x0a, 0x04,
+	0x47, 0x72, 0x70, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x2a, 0x3e, 0x0a, 0x0b, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x13, 0x0a, 0x0f, 0x4e, 0x4f, 0x5f, 0x50, 0x52, 0x4f, 0x58,
+	0x59, 0x5f, 0x48, 0x45, 0x41, 0x44, 0x45, 0x52, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x52,
+	0x4f, 0x58, 0x59, 0x5f, 0x56, 0x31, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x52, 0x4f, 0x58,
+	0x59, 0x5f, 0x56, 0x32, 0x10, 0x02, 0x2a, 0x33, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x0c, 0x0a, 0x08, 0x4e, 0x4f, 0x5f, 0x50, 0x52,
+	0x4f, 0x58, 0x59, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x4f, 0x43, 0x4b, 0x53, 0x35, 0x10,
+	0x01, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x54, 0x54, 0x50, 0x10, 0x02, 0x2a, 0x2e, 0x0a, 0x15, 0x43,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x10, 0x00,
+	0x12, 0x09, 0x0a, 0x05, 0x51, 0x55, 0x45, 0x55, 0x45, 0x10, 0x01, 0x2a, 0x43, 0x0a, 0x0d, 0x4c,
+	0x6f, 0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x12, 0x0f, 0x0a, 0x0b,
+	0x52, 0x4f, 0x55, 0x4e, 0x44, 0x5f, 0x52, 0x4f, 0x42, 0x49, 0x4e, 0x10, 0x00, 0x12, 0x15, 0x0a,
+	0x11, 0x4c, 0x45, 0x41, 0x53, 0x54, 0x5f, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x49, 0x4f,
+	0x4e, 0x53, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x41, 0x4e, 0x44, 0x4f, 0x4d, 0x10, 0x02,
+	0x2a, 0x21, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x47, 0x5a, 0x49,
+	0x50, 0x10, 0x01, 0x2a, 0x4a, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x10, 0x0a, 0x0c, 0x44, 0x49, 0x53, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x45, 0x44,
+	0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x49, 0x4e, 0x47,
+	0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x45, 0x44, 0x10,
+	0x02, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x2a,
+	0x23, 0x0a, 0x0f, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x55,
+	0x44, 0x50, 0x10, 0x01, 0x2a, 0x24, 0x0a, 0x0b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x4c, 0x4f, 0x43, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x0a,
+	0x0a, 0x06, 0x52, 0x45, 0x4d, 0x4f, 0x54, 0x45, 0x10, 0x01, 0x2a, 0x35, 0x0a, 0x0d, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x50,
+	0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x4f, 0x4d, 0x50,
+	0x4c, 0x45, 0x54, 0x45, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10,
+	0x02, 0x32, 0xd5, 0x02, 0x0a, 0x10, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x0a, 0x4f, 0x70, 0x65, 0x6e, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x12, 0x12, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e,
+	0x4f, 0x70, 0x65, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x12, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79,
+	0x61, 0x72, 0x64, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x28, 0x01, 0x30, 0x01,
+	0x12, 0x42, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x17, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x45, 0x78, 0x70,
+	0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x68, 0x69,
+	0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0e, 0x44, 0x65, 0x73, 0x74, 0x72, 0x6f, 0x79, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x18, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72,
+	0x64, 0x2e, 0x44, 0x65, 0x73, 0x74, 0x72, 0x6f, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4e, 0x75, 0x6c, 0x6c,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3d, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61,
+	0x72, 0x64, 0x2e, 0x4e, 0x75, 0x6c, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x16,
+	0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0c, 0x53, 0x65, 0x74, 0x52, 0x61, 0x74,
+	0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1a, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72,
+	0x64, 0x2e, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x15, 0x2e, 0x73, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x2e, 0x4e, 0x75,
+	0x6c, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -1063,55 +3377,116 @@ func file_server_proto_rawDescGZIP() []byte {
 	return file_server_proto_rawDescData
 }
 
-var file_server_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_server_proto_enumTypes = make([]protoimpl.EnumInfo, 9)
+var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 34)
 var file_server_proto_goTypes = []interface{}{
-	(ServiceType)(0),       // 0: shipyard.ServiceType
-	(ServiceStatus)(0),     // 1: shipyard.ServiceStatus
-	(*NullMessage)(nil),    // 2: shipyard.NullMessage
-	(*OpenData)(nil),       // 3: shipyard.OpenData
-	(*Data)(nil),           // 4: shipyard.Data
-	(*NewConnection)(nil),  // 5: shipyard.NewConnection
-	(*WriteDone)(nil),      // 6: shipyard.WriteDone
-	(*ReadDone)(nil),       // 7: shipyard.ReadDone
-	(*Closed)(nil),         // 8: shipyard.Closed
-	(*ExposeRequest)(nil),  // 9: shipyard.ExposeRequest
-	(*StatusUpdate)(nil),   // 10: shipyard.StatusUpdate
-	(*Service)(nil),        // 11: shipyard.Service
-	(*ExposeResponse)(nil), // 12: shipyard.ExposeResponse
-	(*DestroyRequest)(nil), // 13: shipyard.DestroyRequest
-	(*ListResponse)(nil),   // 14: shipyard.ListResponse
-	(*status.Status)(nil),  // 15: google.rpc.Status
+	(ProxyHeader)(0),           // 0: shipyard.ProxyHeader
+	(ProxyProtocol)(0),         // 1: shipyard.ProxyProtocol
+	(ConnectionLimitAction)(0), // 2: shipyard.ConnectionLimitAction
+	(LoadBalancing)(0),         // 3: shipyard.LoadBalancing
+	(Compression)(0),           // 4: shipyard.Compression
+	(LinkState)(0),             // 5: shipyard.LinkState
+	(ServiceProtocol)(0),       // 6: shipyard.ServiceProtocol
+	(ServiceType)(0),           // 7: shipyard.ServiceType
+	(ServiceStatus)(0),         // 8: shipyard.ServiceStatus
+	(*NullMessage)(nil),        // 9: shipyard.NullMessage
+	(*OpenData)(nil),           // 10: shipyard.OpenData
+	(*GoingAway)(nil),          // 11: shipyard.GoingAway
+	(*Ping)(nil),               // 12: shipyard.Ping
+	(*Pong)(nil),               // 13: shipyard.Pong
+	(*Handshake)(nil),          // 14: shipyard.Handshake
+	(*Data)(nil),               // 15: shipyard.Data
+	(*Client)(nil),             // 16: shipyard.Client
+	(*NewConnection)(nil),      // 17: shipyard.NewConnection
+	(*Connected)(nil),          // 18: shipyard.Connected
+	(*WriteDone)(nil),          // 19: shipyard.WriteDone
+	(*ReadDone)(nil),           // 20: shipyard.ReadDone
+	(*Closed)(nil),             // 21: shipyard.Closed
+	(*WindowUpdate)(nil),       // 22: shipyard.WindowUpdate
+	(*Session)(nil),            // 23: shipyard.Session
+	(*ConnectionState)(nil),    // 24: shipyard.ConnectionState
+	(*ExposeRequest)(nil),      // 25: shipyard.ExposeRequest
+	(*StatusUpdate)(nil),       // 26: shipyard.StatusUpdate
+	(*Service)(nil),            // 27: shipyard.Service
+	(*RateLimit)(nil),          // 28: shipyard.RateLimit
+	(*RateLimitRequest)(nil),   // 29: shipyard.RateLimitRequest
+	(*ThrottleStats)(nil),      // 30: shipyard.ThrottleStats
+	(*DialStats)(nil),          // 31: shipyard.DialStats
+	(*HealthCheck)(nil),        // 32: shipyard.HealthCheck
+	(*EndpointStatus)(nil),     // 33: shipyard.EndpointStatus
+	(*CompressionStats)(nil),   // 34: shipyard.CompressionStats
+	(*LinkStatus)(nil),         // 35: shipyard.LinkStatus
+	(*ExposeResponse)(nil),     // 36: shipyard.ExposeResponse
+	(*DestroyRequest)(nil),     // 37: shipyard.DestroyRequest
+	(*ListResponse)(nil),       // 38: shipyard.ListResponse
+	(*Match)(nil),              // 39: shipyard.Match
+	(*Http)(nil),               // 40: shipyard.Http
+	(*Header)(nil),             // 41: shipyard.Header
+	(*Grpc)(nil),               // 42: shipyard.Grpc
+	(*status.Status)(nil),      // 43: google.rpc.Status
 }
 var file_server_proto_depIdxs = []int32{
-	4,  // 0: shipyard.OpenData.data:type_name -> shipyard.Data
-	9,  // 1: shipyard.OpenData.expose:type_name -> shipyard.ExposeRequest
-	13, // 2: shipyard.OpenData.destroy:type_name -> shipyard.DestroyRequest
-	5,  // 3: shipyard.OpenData.new_connection:type_name -> shipyard.NewConnection
-	6,  // 4: shipyard.OpenData.write_done:type_name -> shipyard.WriteDone
-	7,  // 5: shipyard.OpenData.read_done:type_name -> shipyard.ReadDone
-	8,  // 6: shipyard.OpenData.closed:type_name -> shipyard.Closed
-	10, // 7: shipyard.OpenData.status_update:type_name -> shipyard.StatusUpdate
-	2,  // 8: shipyard.OpenData.ping:type_name -> shipyard.NullMessage
-	15, // 9: shipyard.OpenData.error:type_name -> google.rpc.Status
-	11, // 10: shipyard.ExposeRequest.service:type_name -> shipyard.Service
-	1,  // 11: shipyard.StatusUpdate.status:type_name -> shipyard.ServiceStatus
-	0,  // 12: shipyard.Service.type:type_name -> shipyard.ServiceType
-	1,  // 13: shipyard.Service.status:type_name -> shipyard.ServiceStatus
-	11, // 14: shipyard.ListResponse.services:type_name -> shipyard.Service
-	3,  // 15: shipyard.RemoteConnection.OpenStream:input_type -> shipyard.OpenData
-	9,  // 16: shipyard.RemoteConnection.ExposeService:input_type -> shipyard.ExposeRequest
-	13, // 17: shipyard.RemoteConnection.DestroyService:input_type -> shipyard.DestroyRequest
-	2,  // 18: shipyard.RemoteConnection.ListServices:input_type -> shipyard.NullMessage
-	3,  // 19: shipyard.RemoteConnection.OpenStream:output_type -> shipyard.OpenData
-	12, // 20: shipyard.RemoteConnection.ExposeService:output_type -> shipyard.ExposeResponse
-	2,  // 21: shipyard.RemoteConnection.DestroyService:output_type -> shipyard.NullMessage
-	14, // 22: shipyard.RemoteConnection.ListServices:output_type -> shipyard.ListResponse
-	19, // [19:23] is the sub-list for method output_type
-	15, // [15:19] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	15, // 0: shipyard.OpenData.data:type_name -> shipyard.Data
+	25, // 1: shipyard.OpenData.expose:type_name -> shipyard.ExposeRequest
+	37, // 2: shipyard.OpenData.destroy:type_name -> shipyard.DestroyRequest
+	17, // 3: shipyard.OpenData.new_connection:type_name -> shipyard.NewConnection
+	19, // 4: shipyard.OpenData.write_done:type_name -> shipyard.WriteDone
+	20, // 5: shipyard.OpenData.read_done:type_name -> shipyard.ReadDone
+	21, // 6: shipyard.OpenData.closed:type_name -> shipyard.Closed
+	26, // 7: shipyard.OpenData.status_update:type_name -> shipyard.StatusUpdate
+	12, // 8: shipyard.OpenData.ping:type_name -> shipyard.Ping
+	43, // 9: shipyard.OpenData.error:type_name -> google.rpc.Status
+	22, // 10: shipyard.OpenData.window_update:type_name -> shipyard.WindowUpdate
+	23, // 11: shipyard.OpenData.session:type_name -> shipyard.Session
+	14, // 12: shipyard.OpenData.handshake:type_name -> shipyard.Handshake
+	13, // 13: shipyard.OpenData.pong:type_name -> shipyard.Pong
+	11, // 14: shipyard.OpenData.going_away:type_name -> shipyard.GoingAway
+	18, // 15: shipyard.OpenData.connected:type_name -> shipyard.Connected
+	4,  // 16: shipyard.Data.compression:type_name -> shipyard.Compression
+	16, // 17: shipyard.Data.client:type_name -> shipyard.Client
+	16, // 18: shipyard.NewConnection.client:type_name -> shipyard.Client
+	24, // 19: shipyard.Session.connections:type_name -> shipyard.ConnectionState
+	27, // 20: shipyard.ExposeRequest.service:type_name -> shipyard.Service
+	8,  // 21: shipyard.StatusUpdate.status:type_name -> shipyard.ServiceStatus
+	7,  // 22: shipyard.Service.type:type_name -> shipyard.ServiceType
+	8,  // 23: shipyard.Service.status:type_name -> shipyard.ServiceStatus
+	6,  // 24: shipyard.Service.protocol:type_name -> shipyard.ServiceProtocol
+	35, // 25: shipyard.Service.link:type_name -> shipyard.LinkStatus
+	4,  // 26: shipyard.Service.compression:type_name -> shipyard.Compression
+	34, // 27: shipyard.Service.compression_stats:type_name -> shipyard.CompressionStats
+	3,  // 28: shipyard.Service.load_balancing:type_name -> shipyard.LoadBalancing
+	32, // 29: shipyard.Service.health_check:type_name -> shipyard.HealthCheck
+	33, // 30: shipyard.Service.endpoints:type_name -> shipyard.EndpointStatus
+	31, // 31: shipyard.Service.dial_stats:type_name -> shipyard.DialStats
+	2,  // 32: shipyard.Service.connection_limit_action:type_name -> shipyard.ConnectionLimitAction
+	28, // 33: shipyard.Service.rate_limit:type_name -> shipyard.RateLimit
+	30, // 34: shipyard.Service.throttle_stats:type_name -> shipyard.ThrottleStats
+	39, // 35: shipyard.Service.match:type_name -> shipyard.Match
+	1,  // 36: shipyard.Service.proxy:type_name -> shipyard.ProxyProtocol
+	0,  // 37: shipyard.Service.proxy_header:type_name -> shipyard.ProxyHeader
+	28, // 38: shipyard.RateLimitRequest.limit:type_name -> shipyard.RateLimit
+	5,  // 39: shipyard.LinkStatus.state:type_name -> shipyard.LinkState
+	28, // 40: shipyard.LinkStatus.rate_limit:type_name -> shipyard.RateLimit
+	30, // 41: shipyard.LinkStatus.throttle_stats:type_name -> shipyard.ThrottleStats
+	27, // 42: shipyard.ListResponse.services:type_name -> shipyard.Service
+	40, // 43: shipyard.Match.http:type_name -> shipyard.Http
+	42, // 44: shipyard.Match.grpc:type_name -> shipyard.Grpc
+	41, // 45: shipyard.Http.headers:type_name -> shipyard.Header
+	10, // 46: shipyard.RemoteConnection.OpenStream:input_type -> shipyard.OpenData
+	25, // 47: shipyard.RemoteConnection.ExposeService:input_type -> shipyard.ExposeRequest
+	37, // 48: shipyard.RemoteConnection.DestroyService:input_type -> shipyard.DestroyRequest
+	9,  // 49: shipyard.RemoteConnection.ListServices:input_type -> shipyard.NullMessage
+	29, // 50: shipyard.RemoteConnection.SetRateLimit:input_type -> shipyard.RateLimitRequest
+	10, // 51: shipyard.RemoteConnection.OpenStream:output_type -> shipyard.OpenData
+	36, // 52: shipyard.RemoteConnection.ExposeService:output_type -> shipyard.ExposeResponse
+	9,  // 53: shipyard.RemoteConnection.DestroyService:output_type -> shipyard.NullMessage
+	38, // 54: shipyard.RemoteConnection.ListServices:output_type -> shipyard.ListResponse
+	9,  // 55: shipyard.RemoteConnection.SetRateLimit:output_type -> shipyard.NullMessage
+	51, // [51:56] is the sub-list for method output_type
+	46, // [46:51] is the sub-list for method input_type
+	46, // [46:46] is the sub-list for extension type_name
+	46, // [46:46] is the sub-list for extension extendee
+	0,  // [0:46] is the sub-list for field type_name
 }
 
 func init() { file_server_proto_init() }
@@ -1145,7 +3520,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Data); i {
+			switch v := v.(*GoingAway); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1157,7 +3532,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NewConnection); i {
+			switch v := v.(*Ping); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1169,7 +3544,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WriteDone); i {
+			switch v := v.(*Pong); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1181,7 +3556,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReadDone); i {
+			switch v := v.(*Handshake); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1193,7 +3568,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Closed); i {
+			switch v := v.(*Data); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1205,7 +3580,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExposeRequest); i {
+			switch v := v.(*Client); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1217,7 +3592,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StatusUpdate); i {
+			switch v := v.(*NewConnection); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1229,7 +3604,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Service); i {
+			switch v := v.(*Connected); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1241,7 +3616,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExposeResponse); i {
+			switch v := v.(*WriteDone); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1253,7 +3628,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DestroyRequest); i {
+			switch v := v.(*ReadDone); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1265,6 +3640,210 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Closed); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WindowUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Session); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConnectionState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExposeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Service); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RateLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RateLimitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThrottleStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DialStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EndpointStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompressionStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExposeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DestroyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListResponse); i {
 			case 0:
 				return &v.state
@@ -1276,6 +3855,54 @@ func file_server_proto_init() {
 				return nil
 			}
 		}
+		file_server_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Match); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Http); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Header); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Grpc); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_server_proto_msgTypes[1].OneofWrappers = []interface{}{
 		(*OpenData_Data)(nil),
@@ -1288,14 +3915,20 @@ func file_server_proto_init() {
 		(*OpenData_StatusUpdate)(nil),
 		(*OpenData_Ping)(nil),
 		(*OpenData_Error)(nil),
+		(*OpenData_WindowUpdate)(nil),
+		(*OpenData_Session)(nil),
+		(*OpenData_Handshake)(nil),
+		(*OpenData_Pong)(nil),
+		(*OpenData_GoingAway)(nil),
+		(*OpenData_Connected)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_server_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   13,
+			NumEnums:      9,
+			NumMessages:   34,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -1330,6 +3963,8 @@ type RemoteConnectionClient interface {
 	DestroyService(ctx context.Context, in *DestroyRequest, opts ...grpc.CallOption) (*NullMessage, error)
 	// Close the remote TCP port and remove all resources
 	ListServices(ctx context.Context, in *NullMessage, opts ...grpc.CallOption) (*ListResponse, error)
+	// Set the bandwidth limit for a service or for the link to a remote connector
+	SetRateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*NullMessage, error)
 }
 
 type remoteConnectionClient struct {
@@ -1398,6 +4033,15 @@ func (c *remoteConnectionClient) ListServices(ctx context.Context, in *NullMessa
 	return out, nil
 }
 
+func (c *remoteConnectionClient) SetRateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*NullMessage, error) {
+	out := new(NullMessage)
+	err := c.cc.Invoke(ctx, "/shipyard.RemoteConnection/SetRateLimit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RemoteConnectionServer is the server API for RemoteConnection service.
 type RemoteConnectionServer interface {
 	// Open a stream between two servers
@@ -1408,6 +4052,8 @@ type RemoteConnectionServer interface {
 	DestroyService(context.Context, *DestroyRequest) (*NullMessage, error)
 	// Close the remote TCP port and remove all resources
 	ListServices(context.Context, *NullMessage) (*ListResponse, error)
+	// Set the bandwidth limit for a service or for the link to a remote connector
+	SetRateLimit(context.Context, *RateLimitRequest) (*NullMessage, error)
 }
 
 // UnimplementedRemoteConnectionServer can be embedded to have forward compatible implementations.
@@ -1426,6 +4072,9 @@ func (*UnimplementedRemoteConnectionServer) DestroyService(context.Context, *Des
 func (*UnimplementedRemoteConnectionServer) ListServices(context.Context, *NullMessage) (*ListResponse, error) {
 	return nil, status1.Errorf(codes.Unimplemented, "method ListServices not implemented")
 }
+func (*UnimplementedRemoteConnectionServer) SetRateLimit(context.Context, *RateLimitRequest) (*NullMessage, error) {
+	return nil, status1.Errorf(codes.Unimplemented, "method SetRateLimit not implemented")
+}
 
 func RegisterRemoteConnectionServer(s *grpc.Server, srv RemoteConnectionServer) {
 	s.RegisterService(&_RemoteConnection_serviceDesc, srv)
@@ -1511,6 +4160,24 @@ func _RemoteConnection_ListServices_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RemoteConnection_SetRateLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemoteConnectionServer).SetRateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/shipyard.RemoteConnection/SetRateLimit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemoteConnectionServer).SetRateLimit(ctx, req.(*RateLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RemoteConnection_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "shipyard.RemoteConnection",
 	HandlerType: (*RemoteConnectionServer)(nil),
@@ -1527,6 +4194,10 @@ var _RemoteConnection_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListServices",
 			Handler:    _RemoteConnection_ListServices_Handler,
 		},
+		{
+			MethodName: "SetRateLimit",
+			Handler:    _RemoteConnection_SetRateLimit_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,49 @@
+package remote
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Default values for the backoff between attempts to connect to a remote server
+const (
+	DefaultBackoffInitial    = 1 * time.Second
+	DefaultBackoffMax        = 60 * time.Second
+	DefaultBackoffMultiplier = 2.0
+	DefaultBackoffJitter     = 0.2
+)
+
+// backoff calculates the time to wait between connection attempts, the wait grows
+// exponentially from initial up to max. A random jitter of +/- jitter * wait is
+// added so that many clients do not reconnect at the same time.
+type backoff struct {
+	initial    time.Duration
+	max        time.Duration
+	multiplier float64
+	jitter     float64
+}
+
+var defaultBackoff = backoff{
+	initial:    DefaultBackoffInitial,
+	max:        DefaultBackoffMax,
+	multiplier: DefaultBackoffMultiplier,
+	jitter:     DefaultBackoffJitter,
+}
+
+// duration returns the time to wait before the given attempt, the first
+// attempt is made immediately
+func (b backoff) duration(attempt int) time.Duration {
+	if attempt <= 0 {
+		return 0
+	}
+
+	d := float64(b.initial) * math.Pow(b.multiplier, float64(attempt-1))
+	if d > float64(b.max) {
+		d = float64(b.max)
+	}
+
+	d += d * b.jitter * (rand.Float64()*2 - 1)
+
+	return time.Duration(d)
+}
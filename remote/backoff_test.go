@@ -0,0 +1,118 @@
+package remote
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackoffFirstAttemptIsImmediate(t *testing.T) {
+	require.Equal(t, time.Duration(0), defaultBackoff.duration(0))
+}
+
+func TestBackoffGrowsExponentially(t *testing.T) {
+	b := backoff{initial: time.Second, max: time.Minute, multiplier: 2}
+
+	require.Equal(t, 1*time.Second, b.duration(1))
+	require.Equal(t, 2*time.Second, b.duration(2))
+	require.Equal(t, 4*time.Second, b.duration(3))
+}
+
+func TestBackoffIsLimitedToMax(t *testing.T) {
+	b := backoff{initial: time.Second, max: 10 * time.Second, multiplier: 2}
+
+	require.Equal(t, 10*time.Second, b.duration(10))
+	require.Equal(t, 10*time.Second, b.duration(1000))
+}
+
+func TestBackoffAddsJitter(t *testing.T) {
+	b := backoff{initial: time.Second, max: time.Minute, multiplier: 2, jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		d := b.duration(2)
+		require.GreaterOrEqual(t, d, 1*time.Second)
+		require.LessOrEqual(t, d, 3*time.Second)
+	}
+}
+
+func TestUnreachableRemoteRecordsFailedAttempts(t *testing.T) {
+	// nothing is listening on the remote address
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	lis.Close()
+
+	s, id := exposeToFakeRemote(t, addr, shipyard.ServiceProtocol_TCP, WithBackoff(10*time.Millisecond, 50*time.Millisecond, 2, 0))
+
+	si, ok := s.streams.findByServiceID(id)
+	require.True(t, ok)
+
+	require.Eventually(t, func() bool {
+		ls := si.linkStatus()
+		return ls.State != shipyard.LinkState_CONNECTED && ls.Attempts >= 3 && ls.LastError != ""
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestExposeNudgesReconnect(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	lis.Close()
+
+	// the backoff is long enough that the test only passes if the wait is interrupted
+	s, _ := exposeToFakeRemote(t, addr, shipyard.ServiceProtocol_TCP, WithBackoff(time.Minute, time.Minute, 2, 0))
+
+	si, ok := s.streams.findByRemoteAddr(addr)
+	require.True(t, ok)
+
+	require.Eventually(t, func() bool {
+		return si.linkStatus().Attempts == 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// start the remote and expose another service
+	f, _ := startFakeRemoteOn(t, addr, &shipyard.Handshake{Version: ProtocolVersion, MinVersion: MinProtocolVersion})
+
+	_, err = s.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 2",
+			RemoteConnectorAddr: addr,
+			SourcePort:          int32(freePort(t)),
+			DestinationAddr:     "localhost:8080",
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return f.received(&shipyard.OpenData_Expose{}) && si.linkState() == shipyard.LinkState_CONNECTED
+	}, 5*time.Second, 20*time.Millisecond)
+
+	require.Equal(t, int32(0), si.linkStatus().Attempts)
+}
+
+func TestRejectedStreamsReconnectWithBackoff(t *testing.T) {
+	f, addr := startFakeRemoteWith(t, "localhost:0", &fakeRemote{
+		reply:  &shipyard.Handshake{Version: ProtocolVersion, MinVersion: MinProtocolVersion},
+		reject: status.Error(codes.FailedPrecondition, "incompatible protocol version"),
+	})
+
+	backoff := 200 * time.Millisecond
+	exposeToFakeRemote(t, addr, shipyard.ServiceProtocol_TCP, WithBackoff(backoff, backoff, 2, 0))
+
+	require.Eventually(t, func() bool {
+		return len(f.streamTimes()) >= 4
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// the remote accepts each stream before rejecting it, the streams are still failed
+	// attempts and the reconnections wait for the backoff
+	opened := f.streamTimes()
+	for i := 1; i < len(opened); i++ {
+		require.GreaterOrEqual(t, opened[i].Sub(opened[i-1]), backoff/2)
+	}
+}
@@ -0,0 +1,215 @@
+package remote
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// outlierFailures is the number of consecutive dial failures before a destination is ejected
+const outlierFailures = 3
+
+// outlierEjectionTime is the time an ejected destination is not used for new connections
+var outlierEjectionTime = 30 * time.Second
+
+// default thresholds for active health checks
+const (
+	defaultUnhealthyThreshold = 3
+	defaultHealthyThreshold   = 1
+)
+
+// endpoint is a destination address for a service
+type endpoint struct {
+	addr   string
+	active atomic.Int64 // open connections to the endpoint
+
+	// guarded by the balancer lock
+	failures       int // consecutive dial failures
+	ejectedUntil   time.Time
+	unhealthy      bool
+	checkFailures  int // consecutive failed health checks
+	checkSuccesses int // consecutive passed health checks
+	lastError      string
+}
+
+// balancer chooses the destination for new connections to a service
+type balancer struct {
+	lock      sync.Mutex
+	policy    shipyard.LoadBalancing
+	endpoints map[string]*endpoint
+	order     []string // addresses in the order they were resolved
+	next      int      // next index for round robin
+}
+
+func newBalancer(policy shipyard.LoadBalancing) *balancer {
+	return &balancer{policy: policy, endpoints: map[string]*endpoint{}}
+}
+
+// update sets the addresses of the endpoints, the state of existing endpoints is kept
+// and endpoints for addresses which are no longer resolved are removed
+func (b *balancer) update(addrs []string) []*endpoint {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.updateLocked(addrs)
+}
+
+func (b *balancer) updateLocked(addrs []string) []*endpoint {
+	eps := make([]*endpoint, 0, len(addrs))
+	current := map[string]*endpoint{}
+
+	for _, a := range addrs {
+		if _, ok := current[a]; ok {
+			continue
+		}
+
+		e, ok := b.endpoints[a]
+		if !ok {
+			e = &endpoint{addr: a}
+		}
+
+		current[a] = e
+		eps = append(eps, e)
+	}
+
+	b.endpoints = current
+	b.order = make([]string, 0, len(eps))
+	for _, e := range eps {
+		b.order = append(b.order, e.addr)
+	}
+
+	return eps
+}
+
+// pick returns the endpoint for a new connection, endpoints in tried are not returned.
+// Ejected and unhealthy endpoints are only used when there is no other endpoint so that
+// a failing health check does not stop all traffic. Returns nil when every endpoint
+// has been tried.
+func (b *balancer) pick(addrs []string, tried map[string]bool) *endpoint {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	eps := b.updateLocked(addrs)
+	now := time.Now()
+
+	available := []*endpoint{}
+	fallback := []*endpoint{}
+
+	for _, e := range eps {
+		if tried[e.addr] {
+			continue
+		}
+
+		if e.unhealthy || now.Before(e.ejectedUntil) {
+			fallback = append(fallback, e)
+			continue
+		}
+
+		available = append(available, e)
+	}
+
+	if len(available) == 0 {
+		available = fallback
+	}
+
+	if len(available) == 0 {
+		return nil
+	}
+
+	switch b.policy {
+	case shipyard.LoadBalancing_RANDOM:
+		return available[rand.Intn(len(available))]
+	case shipyard.LoadBalancing_LEAST_CONNECTIONS:
+		least := available[0]
+		for _, e := range available[1:] {
+			if e.active.Load() < least.active.Load() {
+				least = e
+			}
+		}
+
+		return least
+	default:
+		e := available[b.next%len(available)]
+		b.next++
+
+		return e
+	}
+}
+
+// dialFailed records a failed connection to the endpoint, the endpoint is ejected
+// after consecutive failures
+func (b *balancer) dialFailed(e *endpoint, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	e.failures++
+	e.lastError = err.Error()
+
+	if e.failures >= outlierFailures {
+		e.ejectedUntil = time.Now().Add(outlierEjectionTime)
+	}
+}
+
+// dialSucceeded records a successful connection to the endpoint
+func (b *balancer) dialSucceeded(e *endpoint) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	e.failures = 0
+	e.ejectedUntil = time.Time{}
+}
+
+// checkFailed records a failed health check, the endpoint is unhealthy after threshold
+// consecutive failures
+func (b *balancer) checkFailed(e *endpoint, err error, threshold int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	e.checkSuccesses = 0
+	e.checkFailures++
+	e.lastError = err.Error()
+
+	if e.checkFailures >= threshold {
+		e.unhealthy = true
+	}
+}
+
+// checkPassed records a passed health check, the endpoint is healthy after threshold
+// consecutive passes
+func (b *balancer) checkPassed(e *endpoint, threshold int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	e.checkFailures = 0
+	e.checkSuccesses++
+
+	if e.checkSuccesses >= threshold {
+		e.unhealthy = false
+	}
+}
+
+// status returns the state of the endpoints in the order they were resolved
+func (b *balancer) status() []*shipyard.EndpointStatus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	status := []*shipyard.EndpointStatus{}
+
+	for _, a := range b.order {
+		e := b.endpoints[a]
+		status = append(status, &shipyard.EndpointStatus{
+			Addr:              e.addr,
+			Healthy:           !e.unhealthy,
+			Ejected:           now.Before(e.ejectedUntil),
+			ActiveConnections: e.active.Load(),
+			Failures:          int32(e.failures),
+			LastError:         e.lastError,
+		})
+	}
+
+	return status
+}
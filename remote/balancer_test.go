@@ -0,0 +1,208 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+var testEndpoints = []string{"a:80", "b:80", "c:80"}
+
+func TestRoundRobinBalancerUsesEachEndpoint(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_ROUND_ROBIN)
+
+	picked := []string{}
+	for i := 0; i < 6; i++ {
+		picked = append(picked, b.pick(testEndpoints, nil).addr)
+	}
+
+	require.Equal(t, []string{"a:80", "b:80", "c:80", "a:80", "b:80", "c:80"}, picked)
+}
+
+func TestLeastConnectionsBalancerUsesEndpointWithFewestConnections(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_LEAST_CONNECTIONS)
+
+	for _, e := range b.update(testEndpoints) {
+		if e.addr != "b:80" {
+			e.active.Add(2)
+		}
+	}
+
+	require.Equal(t, "b:80", b.pick(testEndpoints, nil).addr)
+}
+
+func TestRandomBalancerOnlyUsesEndpoints(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_RANDOM)
+
+	for i := 0; i < 20; i++ {
+		require.Contains(t, testEndpoints, b.pick(testEndpoints, nil).addr)
+	}
+}
+
+func TestBalancerDoesNotReturnTriedEndpoints(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_ROUND_ROBIN)
+	tried := map[string]bool{"a:80": true, "b:80": true}
+
+	require.Equal(t, "c:80", b.pick(testEndpoints, tried).addr)
+
+	tried["c:80"] = true
+	require.Nil(t, b.pick(testEndpoints, tried))
+}
+
+func TestBalancerEjectsEndpointAfterDialFailures(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_ROUND_ROBIN)
+	eps := b.update(testEndpoints)
+
+	for i := 0; i < outlierFailures; i++ {
+		b.dialFailed(eps[0], errors.New("refused"))
+	}
+
+	for i := 0; i < 4; i++ {
+		require.NotEqual(t, "a:80", b.pick(testEndpoints, nil).addr)
+	}
+
+	status := b.status()
+	require.True(t, status[0].Ejected)
+	require.Equal(t, int32(outlierFailures), status[0].Failures)
+	require.Equal(t, "refused", status[0].LastError)
+
+	// a successful connection returns the endpoint to the balancer
+	b.dialSucceeded(eps[0])
+	require.False(t, b.status()[0].Ejected)
+}
+
+func TestBalancerUsesEjectedEndpointsWhenNoOtherIsAvailable(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_ROUND_ROBIN)
+
+	for _, e := range b.update(testEndpoints) {
+		for i := 0; i < outlierFailures; i++ {
+			b.dialFailed(e, errors.New("refused"))
+		}
+	}
+
+	require.NotNil(t, b.pick(testEndpoints, nil))
+}
+
+func TestBalancerHealthChecksUseThresholds(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_ROUND_ROBIN)
+	eps := b.update(testEndpoints)
+
+	b.checkFailed(eps[0], errors.New("timeout"), 2)
+	require.True(t, b.status()[0].Healthy)
+
+	b.checkFailed(eps[0], errors.New("timeout"), 2)
+	require.False(t, b.status()[0].Healthy)
+
+	for i := 0; i < 4; i++ {
+		require.NotEqual(t, "a:80", b.pick(testEndpoints, nil).addr)
+	}
+
+	b.checkPassed(eps[0], 1)
+	require.True(t, b.status()[0].Healthy)
+}
+
+func TestBalancerRemovesEndpointsWhichAreNoLongerResolved(t *testing.T) {
+	b := newBalancer(shipyard.LoadBalancing_ROUND_ROBIN)
+	b.update(testEndpoints)
+	b.update([]string{"c:80"})
+
+	status := b.status()
+	require.Len(t, status, 1)
+	require.Equal(t, "c:80", status[0].Addr)
+}
+
+func endpointStatus(t *testing.T, c shipyard.RemoteConnectionClient, id string) []*shipyard.EndpointStatus {
+	resp, err := c.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+
+	for _, s := range resp.Services {
+		if s.Id == id {
+			return s.Endpoints
+		}
+	}
+
+	return nil
+}
+
+func TestExposeServiceWithMultipleDestinationsSkipsFailedDestination(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	dead := freeAddress(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     dead,
+			DestinationAddrs:    []string{startEchoServer(t)},
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.NoError(t, err)
+
+	p := waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+	for i := 0; i < outlierFailures+1; i++ {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+		require.NoError(t, err)
+
+		requireEcho(t, conn)
+		conn.Close()
+	}
+
+	require.Eventually(t, func() bool {
+		eps := endpointStatus(t, c, resp.Id)
+		return len(eps) == 2 && eps[0].Addr == dead && eps[0].Ejected && eps[1].Failures == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestExposeServiceWithHealthCheckMarksDestinationUnhealthy(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	dead := freeAddress(t)
+	echo := startEchoServer(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     echo,
+			DestinationAddrs:    []string{dead},
+			Type:                shipyard.ServiceType_REMOTE,
+			LoadBalancing:       shipyard.LoadBalancing_LEAST_CONNECTIONS,
+			HealthCheck: &shipyard.HealthCheck{
+				Interval:           int64(20 * time.Millisecond),
+				UnhealthyThreshold: 2,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// the remote connector dials the destinations for a remote service
+	remote := createClient(t, servers[1].Address)
+
+	require.Eventually(t, func() bool {
+		eps := endpointStatus(t, remote, resp.Id)
+		return len(eps) == 2 && eps[0].Healthy && !eps[1].Healthy && eps[1].LastError != ""
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestExposeServiceWithHealthCheckForUDPReturnsError(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "localhost:8080",
+			Type:                shipyard.ServiceType_LOCAL,
+			Protocol:            shipyard.ServiceProtocol_UDP,
+			HealthCheck:         &shipyard.HealthCheck{Interval: int64(time.Second)},
+		},
+	})
+	require.Error(t, err)
+}
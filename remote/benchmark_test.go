@@ -0,0 +1,84 @@
+package remote
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+const benchmarkTransferSize = 1 << 20 // 1MB
+
+// benchmarkThroughput echoes data through a pair of connectors using the given message size
+func benchmarkThroughput(b *testing.B, messageSize int) {
+	a1 := freeAddress(b)
+	a2 := freeAddress(b)
+
+	createServer(b, a1, "server_local_1", WithMessageSize(messageSize))
+	createServer(b, a2, "server_remote_1", WithMessageSize(messageSize))
+
+	port := exposeService(b, createClient(b, a1), a2, startEchoServer(b), shipyard.ServiceType_REMOTE)
+
+	var conn net.Conn
+	require.Eventually(b, func() bool {
+		var err error
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer conn.Close()
+
+	data := make([]byte, benchmarkTransferSize)
+	rand.Read(data)
+	resp := make([]byte, benchmarkTransferSize)
+
+	b.SetBytes(benchmarkTransferSize)
+	b.ResetTimer()
+
+	// assertions are made once the timed loop has finished
+	var err error
+	for i := 0; i < b.N && err == nil; i++ {
+		go conn.Write(data)
+
+		_, err = io.ReadFull(conn, resp)
+	}
+
+	b.StopTimer()
+	require.NoError(b, err)
+}
+
+func BenchmarkThroughput4K(b *testing.B) {
+	benchmarkThroughput(b, MessageSize)
+}
+
+func BenchmarkThroughput32K(b *testing.B) {
+	benchmarkThroughput(b, 32*1024)
+}
+
+func BenchmarkThroughput256K(b *testing.B) {
+	benchmarkThroughput(b, 256*1024)
+}
+
+// benchmarkBuffer stops the compiler allocating the benchmark buffers on the stack
+var benchmarkBuffer []byte
+
+func BenchmarkAllocateBuffer(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		benchmarkBuffer = make([]byte, MessageSize)
+	}
+}
+
+func BenchmarkPooledBuffer(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		benchmarkBuffer = getBuffer(MessageSize)
+		putBuffer(benchmarkBuffer)
+	}
+}
@@ -3,20 +3,114 @@ package remote
 import (
 	"bufio"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
 )
 
 type bufferedConn struct {
 	r        *bufio.Reader
 	net.Conn // So that most methods are embedded
 	id       string
+	readSize int  // maximum size of the data read from the connection for a single message
+	datagram bool // each message is a single datagram, messages must not be coalesced
+
+	window    *sendWindow   // credit for sending data read from the connection
+	writes    *writeQueue   // data received from the stream waiting to be written
+	sequence  *sequencer    // orders data received from the stream
+	replay    *replayBuffer // data sent which has not been acknowledged
+	consumed  atomic.Int64  // total number of bytes written to the connection
+	readDone  atomic.Bool   // all data has been read from the connection
+	writeDone atomic.Bool   // no more data will be written to the connection
+	halfClose atomic.Bool   // only the write side is closed once the write queue is drained
+	messages  atomic.Int32  // number of data messages sent for the connection
+	compress  *compressor   // compresses data read from the connection, nil when compression is disabled
+	closeOnce sync.Once
+	onClose   func() // called once when the connection is closed
+
+	done       chan struct{}    // closed when the connection is closed
+	lastActive atomic.Int64     // time data was last read or written in unix nanoseconds
+	dialed     chan error       // result of dialing the destination requested by a proxy client, nil for other connections
+	client     *shipyard.Client // addresses of the client for connections accepted by a listener
+
+	// data for a connection can be received from any stream in the pool
+	recvMutex    sync.Mutex
+	closeAfter   int32 // number of messages to receive before closing, set by a Closed message
+	closePending bool  // closeAfter has been set
 }
 
 func newBufferedConn(c net.Conn) *bufferedConn {
-	return &bufferedConn{bufio.NewReader(c), c, ""}
+	b := &bufferedConn{
+		r:        bufio.NewReader(c),
+		Conn:     c,
+		readSize: MessageSize,
+		window:   newSendWindow(DefaultWindowSize),
+		writes:   newWriteQueue(),
+		sequence: newSequencer(),
+		replay:   newReplayBuffer(),
+		done:     make(chan struct{}),
+	}
+
+	b.touch()
+
+	return b
 }
 
 func newBufferedConnSize(c net.Conn, n int) *bufferedConn {
-	return &bufferedConn{bufio.NewReaderSize(c, n), c, ""}
+	b := newBufferedConn(c)
+	b.r = bufio.NewReaderSize(c, n)
+
+	return b
+}
+
+// newServiceConn creates a buffered connection for the protocol used by the service,
+// datagram connections read a whole datagram for each message
+func (s *Server) newServiceConn(svc *service, c net.Conn) *bufferedConn {
+	var b *bufferedConn
+	if svc.detail.Protocol == shipyard.ServiceProtocol_UDP {
+		b = newBufferedConnSize(c, maxDatagramSize)
+		b.readSize = maxDatagramSize
+		b.datagram = true
+	} else {
+		b = newBufferedConn(c)
+		b.readSize = s.messageSize
+
+		if svc.detail.MessageSize > 0 {
+			b.readSize = int(svc.detail.MessageSize)
+		}
+
+		// allow several large messages to be in flight
+		if 4*b.readSize > DefaultWindowSize {
+			b.window = newSendWindow(int64(4 * b.readSize))
+		}
+	}
+
+	if svc.detail.Compression == shipyard.Compression_GZIP {
+		b.compress = newCompressor(&svc.compression)
+	}
+
+	return b
+}
+
+// writeBuffers writes the buffers to the connection with a single write where possible,
+// the buffers are data received from the stream and are not returned to the pool as they
+// are still referenced by the messages
+func (b *bufferedConn) writeBuffers(data [][]byte) (int64, error) {
+	var n int64
+	var err error
+
+	if len(data) == 1 {
+		var i int
+		i, err = b.Conn.Write(data[0])
+		n = int64(i)
+	} else {
+		bufs := net.Buffers(data)
+		n, err = bufs.WriteTo(b.Conn)
+	}
+
+	return n, err
 }
 
 func (b *bufferedConn) Peek(n int) ([]byte, error) {
@@ -26,3 +120,84 @@ func (b *bufferedConn) Peek(n int) ([]byte, error) {
 func (b *bufferedConn) Read(p []byte) (int, error) {
 	return b.r.Read(p)
 }
+
+// Close the connection, any data waiting to be written is discarded
+// and any blocked senders are released
+func (b *bufferedConn) Close() error {
+	var err error
+
+	b.closeOnce.Do(func() {
+		b.writes.abort()
+		b.window.close()
+		err = b.Conn.Close()
+		close(b.done)
+
+		if b.onClose != nil {
+			b.onClose()
+		}
+	})
+
+	return err
+}
+
+// touch records that data has been read from or written to the connection
+func (b *bufferedConn) touch() {
+	b.lastActive.Store(time.Now().UnixNano())
+}
+
+// clientFor returns the client addresses to send with a message, they are only sent with
+// the first message for the connection
+func (b *bufferedConn) clientFor(id int32) *shipyard.Client {
+	if id != 0 {
+		return nil
+	}
+
+	return b.client
+}
+
+// dialResult sends the result of dialing the destination to a proxy connection which
+// is waiting for it, other connections ignore the result
+func (b *bufferedConn) dialResult(err error) {
+	if b.dialed == nil {
+		return
+	}
+
+	select {
+	case b.dialed <- err:
+	default:
+	}
+}
+
+// closeWrite shuts down the write side of the connection, connections which do not
+// support half-close are closed
+func (b *bufferedConn) closeWrite() error {
+	if cw, ok := b.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+
+	return b.Close()
+}
+
+// closeRead shuts down the read side of the connection, connections which do not
+// support half-close are closed
+func (b *bufferedConn) closeRead() error {
+	if cr, ok := b.Conn.(interface{ CloseRead() error }); ok {
+		return cr.CloseRead()
+	}
+
+	return b.Close()
+}
+
+// finishRead marks the read side of the connection as done, returns true
+// when the write side is also done and the connection can be closed
+func (b *bufferedConn) finishRead() bool {
+	b.readDone.Store(true)
+	return b.writeDone.Load()
+}
+
+// finishWrite marks the write side of the connection as done, returns true
+// when the read side is also done and the connection can be closed
+func (b *bufferedConn) finishWrite() bool {
+	b.writeDone.Store(true)
+	return b.readDone.Load()
+}
@@ -0,0 +1,149 @@
+package remote
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// minCompressSize is the smallest chunk which is compressed, the gzip header
+// outweighs any saving for smaller chunks
+const minCompressSize = 128
+
+// maxCompressRatio is the largest compressed size, as a fraction of the original, for
+// which the compressed chunk is sent
+const maxCompressRatio = 0.9
+
+// maxIncompressibleChunks is the number of consecutive chunks which do not compress
+// before compression is disabled for the connection
+const maxIncompressibleChunks = 4
+
+// compressedSignatures are the magic numbers of common compressed formats, connections
+// which start with one of these are not compressed
+var compressedSignatures = [][]byte{
+	{0x1f, 0x8b},             // gzip
+	{0x28, 0xb5, 0x2f, 0xfd}, // zstd
+	{'P', 'K', 0x03, 0x04},   // zip
+	{0xff, 0xd8, 0xff},       // jpeg
+	{0x89, 'P', 'N', 'G'},    // png
+	{0x16, 0x03},             // TLS handshake
+}
+
+// compressionStats are the totals for the data sent for a service
+type compressionStats struct {
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+	skipped  atomic.Int64
+}
+
+func (c *compressionStats) status() *shipyard.CompressionStats {
+	return &shipyard.CompressionStats{
+		BytesIn:  c.bytesIn.Load(),
+		BytesOut: c.bytesOut.Load(),
+		Skipped:  c.skipped.Load(),
+	}
+}
+
+// compressor compresses the data read from a connection, it is only used
+// by the goroutine reading from the connection
+type compressor struct {
+	stats *compressionStats
+	buf   bytes.Buffer
+	w     *gzip.Writer
+
+	chunks         int
+	incompressible int  // consecutive chunks which did not compress
+	disabled       bool // the data for the connection is not compressible
+}
+
+func newCompressor(stats *compressionStats) *compressor {
+	c := &compressor{stats: stats}
+	c.w = gzip.NewWriter(&c.buf)
+
+	return c
+}
+
+// compress returns the chunk to send for d, the chunk is only compressed when
+// it is smaller than the original
+func (c *compressor) compress(d *shipyard.Data) *shipyard.Data {
+	c.stats.bytesIn.Add(int64(len(d.Data)))
+
+	if c.chunks == 0 && isCompressed(d.Data) {
+		c.disabled = true
+	}
+	c.chunks++
+
+	if c.disabled || len(d.Data) < minCompressSize {
+		c.stats.bytesOut.Add(int64(len(d.Data)))
+		return d
+	}
+
+	c.buf.Reset()
+	c.w.Reset(&c.buf)
+	c.w.Write(d.Data)
+	c.w.Close()
+
+	if float64(c.buf.Len()) > float64(len(d.Data))*maxCompressRatio {
+		c.incompressible++
+		if c.incompressible >= maxIncompressibleChunks {
+			c.disabled = true
+		}
+
+		c.stats.skipped.Add(1)
+		c.stats.bytesOut.Add(int64(len(d.Data)))
+
+		return d
+	}
+
+	c.incompressible = 0
+	c.stats.bytesOut.Add(int64(c.buf.Len()))
+
+	return &shipyard.Data{
+		Id:          d.Id,
+		Data:        append([]byte{}, c.buf.Bytes()...),
+		Compression: shipyard.Compression_GZIP,
+		Client:      d.Client,
+	}
+}
+
+// isCompressed returns true when the data starts with the signature of a compressed format
+func isCompressed(data []byte) bool {
+	for _, sig := range compressedSignatures {
+		if bytes.HasPrefix(data, sig) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decompress returns the original data for a chunk, the size of the data is limited
+// to the largest message size
+func decompress(d *shipyard.Data) ([]byte, error) {
+	switch d.Compression {
+	case shipyard.Compression_NONE:
+		return d.Data, nil
+	case shipyard.Compression_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(d.Data))
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r, MaxMessageSize+1))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) > MaxMessageSize {
+			return nil, fmt.Errorf("decompressed data is larger than %d bytes", MaxMessageSize)
+		}
+
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("unsupported compression %s", d.Compression)
+}
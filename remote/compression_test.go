@@ -0,0 +1,121 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+var compressibleData = []byte(strings.Repeat(`{"name":"connector","status":"COMPLETE"}`, 100))
+
+func TestCompressorCompressesText(t *testing.T) {
+	c := newCompressor(&compressionStats{})
+
+	d := c.compress(&shipyard.Data{Id: 1, Data: compressibleData})
+	require.Equal(t, shipyard.Compression_GZIP, d.Compression)
+	require.Equal(t, int32(1), d.Id)
+	require.Less(t, len(d.Data), len(compressibleData))
+
+	data, err := decompress(d)
+	require.NoError(t, err)
+	require.Equal(t, compressibleData, data)
+
+	require.Equal(t, int64(len(compressibleData)), c.stats.bytesIn.Load())
+	require.Equal(t, int64(len(d.Data)), c.stats.bytesOut.Load())
+}
+
+func TestCompressorSkipsSmallChunks(t *testing.T) {
+	c := newCompressor(&compressionStats{})
+
+	d := c.compress(&shipyard.Data{Data: []byte("hello")})
+	require.Equal(t, shipyard.Compression_NONE, d.Compression)
+}
+
+func TestCompressorSkipsCompressedFormats(t *testing.T) {
+	c := newCompressor(&compressionStats{})
+
+	d := c.compress(&shipyard.Data{Data: append([]byte{0x1f, 0x8b}, compressibleData...)})
+	require.Equal(t, shipyard.Compression_NONE, d.Compression)
+
+	// compression is disabled for the rest of the connection
+	d = c.compress(&shipyard.Data{Data: compressibleData})
+	require.Equal(t, shipyard.Compression_NONE, d.Compression)
+}
+
+func TestCompressorIsDisabledForIncompressibleData(t *testing.T) {
+	c := newCompressor(&compressionStats{})
+
+	for i := 0; i < maxIncompressibleChunks; i++ {
+		data := make([]byte, 1024)
+		rand.Read(data)
+
+		d := c.compress(&shipyard.Data{Data: data})
+		require.Equal(t, shipyard.Compression_NONE, d.Compression)
+	}
+
+	require.Equal(t, int64(maxIncompressibleChunks), c.stats.skipped.Load())
+
+	d := c.compress(&shipyard.Data{Data: compressibleData})
+	require.Equal(t, shipyard.Compression_NONE, d.Compression)
+}
+
+func TestDecompressRejectsLargeData(t *testing.T) {
+	c := newCompressor(&compressionStats{})
+
+	d := c.compress(&shipyard.Data{Data: make([]byte, MaxMessageSize+1)})
+	require.Equal(t, shipyard.Compression_GZIP, d.Compression)
+
+	_, err := decompress(d)
+	require.Error(t, err)
+}
+
+func TestCompressedServiceTransfersData(t *testing.T) {
+	c, servers := setupPoolTests(t, 1)
+	p := int32(freePort(t))
+
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			SourcePort:          p,
+			DestinationAddr:     startEchoServer(t),
+			Type:                shipyard.ServiceType_REMOTE,
+			Compression:         shipyard.Compression_GZIP,
+		},
+	})
+	require.NoError(t, err)
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	go conn.Write(compressibleData)
+
+	resp := make([]byte, len(compressibleData))
+	_, err = io.ReadFull(conn, resp)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(compressibleData, resp))
+
+	l, err := c.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+	require.Len(t, l.Services, 1)
+
+	stats := l.Services[0].CompressionStats
+	require.NotNil(t, stats)
+	require.Equal(t, int64(len(compressibleData)), stats.BytesIn)
+	require.Less(t, stats.BytesOut, stats.BytesIn)
+}
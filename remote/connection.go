@@ -1,9 +1,14 @@
 package remote
 
 import (
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/jumppad-labs/connector/protos/shipyard"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (s *Server) handleConnectionRead(serviceID string, si *streamInfo, svc *service, conn *bufferedConn) {
@@ -17,15 +22,17 @@ func (s *Server) handleConnectionRead(serviceID string, si *streamInfo, svc *ser
 
 	// read the data from the connection
 	for {
-		data := make([]byte, MessageSize)
+		data := getBuffer(conn.readSize)
 
 		s.log.Debug("listener", "message", "Reading data from connection", "service_id", serviceID, "connection_id", conn.id)
 
-		// read 4K of data from the connection
+		// read 4K of data from the connection, or a single datagram for UDP services
 		i, err := conn.Read(data)
 
 		// unable to read the data, kill the connection
 		if err != nil || i == 0 {
+			putBuffer(data)
+
 			if err == io.EOF {
 				s.log.Debug(
 					"listener",
@@ -43,20 +50,55 @@ func (s *Server) handleConnectionRead(serviceID string, si *streamInfo, svc *ser
 					"error", err)
 			}
 
+			// the peer has finished writing, notify the remote so that it can close the write
+			// side of its connection, the connection stays open until the other direction is done.
+			// Datagram connections end when the peer is idle, the remote closes its connection.
+			if err == io.EOF && si.supports(CapabilityHalfClose) && !conn.datagram {
+				si.sendConnectionMessage(
+					&shipyard.OpenData{
+						ServiceId:    serviceID,
+						ConnectionId: conn.id,
+						Message:      &shipyard.OpenData_WriteDone{WriteDone: &shipyard.WriteDone{Messages: messageID}},
+					},
+				)
+
+				if conn.finishRead() {
+					s.closeConnection(svc, conn)
+				}
+
+				return
+			}
+
 			// the connection has closed
 			// notify the remote
-			si.grpcConn.Send(
+			conn.readDone.Store(true)
+			si.sendConnectionMessage(
 				&shipyard.OpenData{
 					ServiceId:    serviceID,
 					ConnectionId: conn.id,
-					Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
+					Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{Messages: messageID}},
 				},
 			)
 
+			// version 1 remotes close the connection when they receive a Closed message
+			// which closes the connection on this side
+			if err != io.EOF {
+				s.closeConnection(svc, conn)
+			}
+
 			// exit the for loop
 			return
 		}
 
+		conn.touch()
+
+		// wait until the bandwidth limits allow the data to be sent, when the connection
+		// is closed while waiting the next read fails and the remote is notified
+		if !s.waitForRateLimit(si, svc, conn, i) {
+			putBuffer(data)
+			continue
+		}
+
 		s.log.Trace(
 			"listener",
 			"message", "Read data from connection",
@@ -65,6 +107,24 @@ func (s *Server) handleConnectionRead(serviceID string, si *streamInfo, svc *ser
 			"len", i,
 			"data", string(data[:i]))
 
+		// wait until the remote has capacity to receive the data, this stops a fast
+		// sender filling the stream when the remote socket is slow to consume it
+		if si.supports(CapabilityFlowControl) && !conn.window.wait(i) {
+			s.log.Debug(
+				"listener",
+				"message", "Connection closed while waiting for send window",
+				"service_id", serviceID,
+				"connection_id", conn.id)
+
+			putBuffer(data)
+
+			if conn.finishRead() {
+				s.closeConnection(svc, conn)
+			}
+
+			return
+		}
+
 		// send the read chunk of data over the gRPC stream
 		// check there is a remote connection if not just return
 		s.log.Debug(
@@ -75,19 +135,18 @@ func (s *Server) handleConnectionRead(serviceID string, si *streamInfo, svc *ser
 			"service_id", serviceID,
 			"connection_id", conn.id)
 
-		si.grpcConn.Send(
-			&shipyard.OpenData{
-				ServiceId:    serviceID,
-				ConnectionId: conn.id,
-				Message:      &shipyard.OpenData_Data{Data: &shipyard.Data{Id: messageID, Data: data[:i]}},
-			},
-		)
+		// the buffer can be reused once the data has been sent unless it is
+		// retained to be replayed when the stream is resumed
+		if !si.sendData(conn, serviceID, &shipyard.Data{Id: messageID, Data: data[:i]}, true) {
+			putBuffer(data)
+		}
 
 		// increment the messageid
 		messageID++
+		conn.messages.Store(messageID)
 
 		// we have read all the data send the other end a message so it knows it can now send a response
-		if i < MessageSize {
+		if i < conn.readSize {
 			s.log.Debug(
 				"listener",
 				"message", "All data read from connection",
@@ -97,3 +156,634 @@ func (s *Server) handleConnectionRead(serviceID string, si *streamInfo, svc *ser
 		}
 	}
 }
+
+// handleConnectionWrite writes the data received from the stream to the connection.
+// Once data has been written the remote is sent a WindowUpdate so that it can
+// send more data for the connection.
+func (s *Server) handleConnectionWrite(serviceID string, si *streamInfo, svc *service, conn *bufferedConn) {
+	var lastUpdate int64
+
+	for {
+		data, ok := s.nextWrite(conn)
+		if !ok {
+			// the remote has finished writing, close the write side of the connection
+			// so that the peer receives EOF and can send its reply
+			if conn.halfClose.Load() {
+				s.log.Trace(
+					"connection",
+					"message", "Write queue finished, closing write side of connection",
+					"service_id", serviceID,
+					"connection_id", conn.id)
+
+				conn.closeWrite()
+				if conn.finishWrite() {
+					s.closeConnection(svc, conn)
+				}
+
+				return
+			}
+
+			// the queue has been finished by a Closed message or aborted by
+			// the connection closing, either way we are done
+			s.log.Trace(
+				"connection",
+				"message", "Write queue closed, closing connection",
+				"service_id", serviceID,
+				"connection_id", conn.id)
+
+			conn.writeDone.Store(true)
+			conn.Close()
+			return
+		}
+
+		s.log.Trace(
+			"connection",
+			"message", "Writing data to local connection",
+			"service_id", serviceID,
+			"connection_id", conn.id,
+			"messages", len(data))
+
+		// wait until the bandwidth limits allow the data to be written
+		if !s.waitForRateLimit(si, svc, conn, bufferedBytes(data)) {
+			conn.writeDone.Store(true)
+			return
+		}
+
+		i, err := conn.writeBuffers(data)
+		consumed := conn.consumed.Add(i)
+		conn.touch()
+
+		if err != nil {
+			if err == io.EOF {
+				s.log.Debug(
+					"connection",
+					"message", "Connection closed",
+					"service_id", serviceID,
+					"connection_id", conn.id)
+			} else {
+				s.log.Error(
+					"connection",
+					"message", "Error writing to connection",
+					"service_id", serviceID,
+					"connection_id", conn.id,
+					"error", err,
+				)
+			}
+
+			// version 1 remotes do not support half-close, the connection is closed
+			if !si.supports(CapabilityHalfClose) {
+				si.sendConnectionMessage(
+					&shipyard.OpenData{
+						ServiceId:    serviceID,
+						ConnectionId: conn.id,
+						Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
+					},
+				)
+
+				s.closeConnection(svc, conn)
+				return
+			}
+
+			// no more data can be written, the remote should stop reading from its
+			// connection, the other direction may still be in use
+			si.sendConnectionMessage(
+				&shipyard.OpenData{
+					ServiceId:    serviceID,
+					ConnectionId: conn.id,
+					Message:      &shipyard.OpenData_ReadDone{ReadDone: &shipyard.ReadDone{}},
+				},
+			)
+
+			conn.writes.abort()
+			if conn.finishWrite() {
+				s.closeConnection(svc, conn)
+			}
+
+			return
+		}
+
+		// return credit to the sender, to reduce the number of messages updates are
+		// batched unless the queue is empty and the sender could be waiting
+		if !si.supports(CapabilityFlowControl) {
+			continue
+		}
+
+		if conn.writes.len() == 0 || consumed-lastUpdate >= DefaultWindowSize/4 {
+			si.sendConnectionMessage(
+				&shipyard.OpenData{
+					ServiceId:    serviceID,
+					ConnectionId: conn.id,
+					Message:      &shipyard.OpenData_WindowUpdate{WindowUpdate: &shipyard.WindowUpdate{Consumed: consumed}},
+				},
+			)
+
+			lastUpdate = consumed
+		}
+	}
+}
+
+// nextWrite returns the data waiting to be written to the connection, messages which
+// have queued while the previous write was in progress are coalesced into a single write.
+// Datagrams are always written individually.
+func (s *Server) nextWrite(conn *bufferedConn) ([][]byte, bool) {
+	if conn.datagram {
+		data, ok := conn.writes.pop()
+		return [][]byte{data}, ok
+	}
+
+	return conn.writes.popAll()
+}
+
+// heldMessage is a message received for a connection while the destination is dialed
+type heldMessage struct {
+	si  *streamInfo
+	msg *shipyard.OpenData
+}
+
+// handleConnectionMessage handles a message for a connection, messages received while the
+// destination for the connection is being dialed are held until the dial completes
+func (s *Server) handleConnectionMessage(si *streamInfo, msg *shipyard.OpenData) {
+	if svc, ok := si.services.get(msg.ServiceId); ok && svc.holdWhileDialing(si, msg) {
+		return
+	}
+
+	switch m := msg.Message.(type) {
+	case *shipyard.OpenData_Data:
+		s.handleDataMessage(si, msg, m)
+
+	case *shipyard.OpenData_Closed:
+		s.handleCloseMessage(si, msg, m)
+
+	case *shipyard.OpenData_WriteDone:
+		s.handleWriteDoneMessage(si, msg, m)
+
+	case *shipyard.OpenData_ReadDone:
+		s.handleReadDoneMessage(si, msg)
+
+	case *shipyard.OpenData_WindowUpdate:
+		s.handleWindowUpdateMessage(si, msg, m)
+
+	case *shipyard.OpenData_Error:
+		s.handleErrorMessage(si, msg, m)
+	}
+}
+
+// holdWhileDialing holds the message when the destination for the connection is being
+// dialed, returns false when the connection is not being dialed
+func (s *service) holdWhileDialing(si *streamInfo, msg *shipyard.OpenData) bool {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+
+	return s.holdMessage(si, msg)
+}
+
+// holdMessage must be called with the connMutex held
+func (s *service) holdMessage(si *streamInfo, msg *shipyard.OpenData) bool {
+	held, ok := s.dialing[msg.ConnectionId]
+	if !ok {
+		return false
+	}
+
+	s.dialing[msg.ConnectionId] = append(held, heldMessage{si, msg})
+	return true
+}
+
+// handleDataMessage queues data received from the stream to be written to the connection,
+// when this side of the stream is responsible for the upstream and no connection
+// exists the destination is dialed without blocking the stream
+func (s *Server) handleDataMessage(si *streamInfo, msg *shipyard.OpenData, m *shipyard.OpenData_Data) {
+	s.log.Trace(
+		"connection",
+		"message", "Received data message",
+		"message_id", m.Data.Id,
+		"service_id", msg.ServiceId,
+		"msg", msg)
+
+	// get the service for this data
+	svc, ok := si.services.get(msg.ServiceId)
+	if !ok {
+		// if there is no service for this message ignore it
+		s.log.Error(
+			"connection",
+			"message", "Service does not exist for message, ignoring",
+			"service_id", msg.ServiceId)
+
+		return
+	}
+
+	// get the connection, data for the connection may be received from more than one stream
+	svc.connMutex.Lock()
+	c, ok := svc.getTCPConnection(msg.ConnectionId)
+
+	// no connection exists, if the upstream is on this side try to establish a new connection to the upstream service
+	// otherwise ignore as the connection should have been created by the listener
+	if !ok {
+		// data received on another stream in the pool may have started the dial
+		if svc.holdMessage(si, msg) {
+			svc.connMutex.Unlock()
+			return
+		}
+
+		// connections for proxy services are only opened when the client requests a destination
+		if !si.dialsUpstream(svc) || svc.detail.Proxy != shipyard.ProxyProtocol_NO_PROXY {
+			svc.connMutex.Unlock()
+
+			s.log.Error(
+				"connection",
+				"message", "No connection for data, ignore message",
+				"port", svc.detail.SourcePort,
+				"service_id", msg.ServiceId,
+				"connection_id", msg.ConnectionId)
+
+			return
+		}
+
+		// the data is held until the destination has been dialed, the remote does not
+		// receive window updates for it so can not send more than the window
+		if svc.dialing == nil {
+			svc.dialing = map[string][]heldMessage{}
+		}
+
+		svc.dialing[msg.ConnectionId] = []heldMessage{{si, msg}}
+		svc.connMutex.Unlock()
+
+		go s.dialUpstream(si, svc, msg)
+		return
+	}
+	svc.connMutex.Unlock()
+
+	payload, err := decompress(m.Data)
+	if err != nil {
+		s.log.Error(
+			"connection",
+			"message", "Unable to decompress data, closing connection",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId,
+			"message_id", m.Data.Id,
+			"error", err)
+
+		s.closeConnectionWithError(si, svc, c, msg.ServiceId, codes.DataLoss, err)
+		return
+	}
+
+	c.recvMutex.Lock()
+	defer c.recvMutex.Unlock()
+
+	// messages may arrive out of sequence, only queue data which is in order
+	data, err := c.sequence.push(m.Data.Id, payload)
+	if err != nil {
+		s.log.Error(
+			"connection",
+			"message", "Invalid message sequence, closing connection",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId,
+			"message_id", m.Data.Id,
+			"error", err)
+
+		s.closeConnectionWithError(si, svc, c, msg.ServiceId, codes.DataLoss, err)
+		return
+	}
+
+	for _, d := range data {
+		err := c.writes.push(d)
+		if err == errQueueFull {
+			s.log.Error(
+				"connection",
+				"message", "Too much data waiting to be written, closing connection",
+				"service_id", msg.ServiceId,
+				"connection_id", msg.ConnectionId,
+				"error", err)
+
+			s.closeConnectionWithError(si, svc, c, msg.ServiceId, codes.ResourceExhausted, err)
+			return
+		}
+
+		if err != nil {
+			s.log.Debug(
+				"connection",
+				"message", "Connection closed, ignoring data",
+				"service_id", msg.ServiceId,
+				"connection_id", msg.ConnectionId)
+
+			return
+		}
+	}
+
+	// a Closed or WriteDone message was received before all the data for the connection
+	if c.closePending && !idBefore(c.sequence.nextID(), c.closeAfter) {
+		s.closeConnectionAfterWrites(svc, c)
+	}
+}
+
+// closeConnectionWithError closes the connection immediately and notifies the remote
+// of the reason before sending a Closed message
+func (s *Server) closeConnectionWithError(si *streamInfo, svc *service, c *bufferedConn, serviceID string, code codes.Code, err error) {
+	c.Close()
+	svc.removeTCPConnection(c.id)
+
+	s.sendConnectionError(si, serviceID, c.id, code, err)
+}
+
+// sendConnectionError sends the reason a connection failed to the remote followed by
+// a Closed message
+func (s *Server) sendConnectionError(si *streamInfo, serviceID, connectionID string, code codes.Code, err error) {
+	si.sendConnectionMessage(
+		&shipyard.OpenData{
+			ServiceId:    serviceID,
+			ConnectionId: connectionID,
+			Message: &shipyard.OpenData_Error{
+				Error: &rpcstatus.Status{Code: int32(code), Message: err.Error()},
+			},
+		},
+	)
+
+	si.sendConnectionMessage(
+		&shipyard.OpenData{
+			ServiceId:    serviceID,
+			ConnectionId: connectionID,
+			Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
+		},
+	)
+}
+
+// dialUpstream opens a new connection to the destination of the service and starts the
+// read and write handlers for it, the messages held while dialing are then handled. If
+// the connection can not be created the remote is sent an Unavailable error with the
+// reason followed by a Closed message.
+func (s *Server) dialUpstream(si *streamInfo, svc *service, msg *shipyard.OpenData) {
+	s.log.Trace(
+		"connection",
+		"message", "Create new upstream connection for data",
+		"service_id", msg.ServiceId,
+		"connection_id", msg.ConnectionId,
+		"addr", svc.detail.DestinationAddr)
+
+	addrs, err := s.resolveDestinations(svc.detail)
+	if err != nil {
+		s.log.Error(
+			"connection",
+			"message", "Unable to find address for upstream",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId,
+			"addr", svc.detail.DestinationAddr,
+			"error", err,
+		)
+
+		svc.dials.failed(err)
+		s.dialUpstreamFailed(si, svc, msg, fmt.Errorf("unable to find address for destination %s: %s", svc.detail.DestinationAddr, err))
+		return
+	}
+
+	// get the service address
+	newConn, ep, err := s.dialDestination(svc, addrs)
+	if err != nil {
+		s.log.Error(
+			"connection",
+			"message", "Unable to create connection to upstream",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId,
+			"addrs", addrs,
+			"error", err)
+
+		s.dialUpstreamFailed(si, svc, msg, fmt.Errorf("unable to connect to destination: %s", err))
+		return
+	}
+
+	if err := s.writeProxyHeader(svc, newConn, msg.GetData().GetClient()); err != nil {
+		s.log.Error(
+			"connection",
+			"message", "Unable to send PROXY protocol header to upstream",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId,
+			"error", err)
+
+		newConn.Close()
+		ep.active.Add(-1)
+
+		s.dialUpstreamFailed(si, svc, msg, fmt.Errorf("unable to send PROXY protocol header: %s", err))
+		return
+	}
+
+	c := s.newServiceConn(svc, newConn)
+	c.id = msg.ConnectionId
+	c.onClose = func() { ep.active.Add(-1) }
+
+	svc.connMutex.Lock()
+	held := svc.dialing[msg.ConnectionId]
+	delete(svc.dialing, msg.ConnectionId)
+
+	// the service may have been removed while dialing, its connections have been closed
+	if existing, ok := si.services.get(msg.ServiceId); !ok || existing != svc {
+		svc.connMutex.Unlock()
+		c.Close()
+
+		return
+	}
+
+	svc.setTCPConnection(msg.ConnectionId, c)
+	svc.connMutex.Unlock()
+
+	// start read and write handlers and don't block
+	go s.handleConnectionRead(msg.ServiceId, si, svc, c)
+	go s.handleConnectionWrite(msg.ServiceId, si, svc, c)
+	s.watchConnection(svc, c)
+
+	// messages received after the connection was set are handled by the stream, the data
+	// is ordered by the sequencer
+	for _, h := range held {
+		s.handleConnectionMessage(h.si, h.msg)
+	}
+}
+
+// dialUpstreamFailed discards the messages held while dialing and notifies the remote
+// that the connection could not be opened
+func (s *Server) dialUpstreamFailed(si *streamInfo, svc *service, msg *shipyard.OpenData, err error) {
+	svc.connMutex.Lock()
+	delete(svc.dialing, msg.ConnectionId)
+	svc.connMutex.Unlock()
+
+	s.sendConnectionError(si, msg.ServiceId, msg.ConnectionId, codes.Unavailable, err)
+}
+
+// handleCloseMessage closes the connection once all data has been received and written
+func (s *Server) handleCloseMessage(si *streamInfo, msg *shipyard.OpenData, m *shipyard.OpenData_Closed) {
+	s.log.Trace(
+		"connection",
+		"message", "Received close connection message",
+		"service_id", msg.ServiceId,
+		"connection_id", msg.ConnectionId,
+		"messages", m.Closed.Messages)
+
+	s.closeAfterMessages(si, msg, m.Closed.Messages, false)
+}
+
+// handleWriteDoneMessage closes the write side of the connection once all data has been
+// received and written, the connection can still be read from
+func (s *Server) handleWriteDoneMessage(si *streamInfo, msg *shipyard.OpenData, m *shipyard.OpenData_WriteDone) {
+	s.log.Trace(
+		"connection",
+		"message", "Received write done message",
+		"service_id", msg.ServiceId,
+		"connection_id", msg.ConnectionId,
+		"messages", m.WriteDone.Messages)
+
+	s.closeAfterMessages(si, msg, m.WriteDone.Messages, true)
+}
+
+// handleReadDoneMessage closes the read side of the connection as the remote is unable
+// to write any more data for it
+func (s *Server) handleReadDoneMessage(si *streamInfo, msg *shipyard.OpenData) {
+	s.log.Trace(
+		"connection",
+		"message", "Received read done message",
+		"service_id", msg.ServiceId,
+		"connection_id", msg.ConnectionId)
+
+	svc, ok := si.services.get(msg.ServiceId)
+	if !ok {
+		return
+	}
+
+	c, ok := svc.getTCPConnection(msg.ConnectionId)
+	if !ok {
+		return
+	}
+
+	// release the reader if it is waiting for window updates which will never arrive
+	c.window.close()
+	c.closeRead()
+}
+
+// closeAfterMessages closes the connection, or only the write side of the connection when
+// half is set, once the given number of data messages have been received
+func (s *Server) closeAfterMessages(si *streamInfo, msg *shipyard.OpenData, messages int32, half bool) {
+	svc, _ := si.services.get(msg.ServiceId)
+	if svc == nil {
+		s.log.Error(
+			"connection",
+			"message", "Service does not exist",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId)
+
+		return
+	}
+
+	c, ok := svc.getTCPConnection(msg.ConnectionId)
+	if !ok {
+		return
+	}
+
+	c.recvMutex.Lock()
+	defer c.recvMutex.Unlock()
+
+	c.halfClose.Store(half)
+
+	// data sent before the message may still be in flight on another stream
+	if idBefore(c.sequence.nextID(), messages) {
+		s.log.Trace(
+			"connection",
+			"message", "Waiting for remaining data before closing connection",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId,
+			"received", c.sequence.nextID(),
+			"messages", messages)
+
+		c.closeAfter = messages
+		c.closePending = true
+		return
+	}
+
+	s.closeConnectionAfterWrites(svc, c)
+}
+
+// closeConnectionAfterWrites closes the connection once the write handler has
+// flushed any data which is still queued, for a half-close only the write side
+// is closed and the connection remains until the read side is done
+func (s *Server) closeConnectionAfterWrites(svc *service, c *bufferedConn) {
+	s.log.Trace(
+		"connection",
+		"message", "Closing connection",
+		"service_id", svc.detail.Id,
+		"connection_id", c.id,
+		"half_close", c.halfClose.Load())
+
+	c.writes.finish()
+
+	if !c.halfClose.Load() {
+		svc.removeTCPConnection(c.id)
+	}
+}
+
+// closeConnection closes a connection once both directions are done
+func (s *Server) closeConnection(svc *service, c *bufferedConn) {
+	s.log.Trace(
+		"connection",
+		"message", "Both sides of connection done, closing",
+		"service_id", svc.detail.Id,
+		"connection_id", c.id)
+
+	c.Close()
+	svc.removeTCPConnection(c.id)
+}
+
+// handleErrorMessage logs an error for a connection reported by the remote, errors are
+// always followed by a Closed message which cleans up the connection. An Unavailable
+// error is sent when the remote was unable to dial the destination for the connection.
+func (s *Server) handleErrorMessage(si *streamInfo, msg *shipyard.OpenData, m *shipyard.OpenData_Error) {
+	code := codes.Code(m.Error.Code)
+
+	// proxy connections are waiting for the result of dialing the destination
+	if svc, ok := si.services.get(msg.ServiceId); ok {
+		if c, ok := svc.getTCPConnection(msg.ConnectionId); ok {
+			c.dialResult(status.Error(code, m.Error.Message))
+		}
+	}
+
+	if code == codes.Unavailable {
+		s.log.Error(
+			"connection",
+			"message", "Remote was unable to connect to the destination",
+			"addr", si.addr,
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId,
+			"error", m.Error.Message)
+
+		if svc, ok := si.services.get(msg.ServiceId); ok {
+			svc.dials.failed(errors.New(m.Error.Message))
+		}
+
+		return
+	}
+
+	s.log.Error(
+		"connection",
+		"message", "Received error from remote",
+		"addr", si.addr,
+		"service_id", msg.ServiceId,
+		"connection_id", msg.ConnectionId,
+		"code", code,
+		"error", m.Error.Message)
+}
+
+// handleWindowUpdateMessage returns send credit to a connection
+func (s *Server) handleWindowUpdateMessage(si *streamInfo, msg *shipyard.OpenData, m *shipyard.OpenData_WindowUpdate) {
+	s.log.Trace(
+		"connection",
+		"message", "Received window update",
+		"service_id", msg.ServiceId,
+		"connection_id", msg.ConnectionId,
+		"consumed", m.WindowUpdate.Consumed)
+
+	svc, ok := si.services.get(msg.ServiceId)
+	if !ok {
+		return
+	}
+
+	c, ok := svc.getTCPConnection(msg.ConnectionId)
+	if !ok {
+		return
+	}
+
+	c.window.update(m.WindowUpdate.Consumed)
+	c.replay.ack(m.WindowUpdate.Consumed)
+}
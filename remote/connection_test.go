@@ -0,0 +1,161 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+// startReplyAfterEOFServer starts a server which reads the request until the client
+// closes its write side, the size of the request is written as the reply
+func startReplyAfterEOFServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				d, err := io.ReadAll(conn)
+				if err != nil {
+					return
+				}
+
+				fmt.Fprintf(conn, "received %d bytes", len(d))
+			}(conn)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func exposeService(t testing.TB, c shipyard.RemoteConnectionClient, remoteAddr, dest string, st shipyard.ServiceType) int32 {
+	p := int32(freePort(t))
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: remoteAddr,
+			SourcePort:          p,
+			DestinationAddr:     dest,
+			Type:                st,
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Id)
+
+	return p
+}
+
+func testHalfClose(t *testing.T, st shipyard.ServiceType) {
+	c, _, _, servers := setupTests(t)
+	dest := startReplyAfterEOFServer(t)
+	p := exposeService(t, c, servers[1].Address, dest, st)
+
+	var conn net.Conn
+	var err error
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	defer conn.Close()
+
+	request := make([]byte, 10000)
+	_, err = conn.Write(request)
+	require.NoError(t, err)
+
+	// closing the write side must be propagated to the destination without
+	// closing the connection before the reply has been received
+	err = conn.(*net.TCPConn).CloseWrite()
+	require.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "received 10000 bytes", string(reply))
+}
+
+func TestHalfCloseIsPropagatedForRemoteService(t *testing.T) {
+	testHalfClose(t, shipyard.ServiceType_REMOTE)
+}
+
+func TestHalfCloseIsPropagatedForLocalService(t *testing.T) {
+	testHalfClose(t, shipyard.ServiceType_LOCAL)
+}
+
+func TestConnectionIsRemovedWhenBothSidesAreClosed(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	dest := startReplyAfterEOFServer(t)
+	p := exposeService(t, c, servers[1].Address, dest, shipyard.ServiceType_REMOTE)
+
+	var conn net.Conn
+	var err error
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	conn.Write([]byte("hello"))
+	conn.(*net.TCPConn).CloseWrite()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = io.ReadAll(conn)
+	require.NoError(t, err)
+	conn.Close()
+
+	connections := func(s *Server) int {
+		n := 0
+		for _, si := range s.streams.all() {
+			si.services.iterate(func(id string, svc *service) bool {
+				svc.tcpConnections.Range(func(k, v interface{}) bool {
+					n++
+					return true
+				})
+
+				return true
+			})
+		}
+
+		return n
+	}
+
+	require.Eventually(t, func() bool {
+		return connections(servers[0].Server) == 0 && connections(servers[1].Server) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestServiceMessageSizeOverridesServerMessageSize(t *testing.T) {
+	s := New(hclog.NewNullLogger(), nil, nil, nil, WithMessageSize(8192))
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	svc := newService()
+	svc.detail = &shipyard.Service{}
+	require.Equal(t, 8192, s.newServiceConn(svc, c1).readSize)
+
+	svc.detail.MessageSize = 65536
+	c := s.newServiceConn(svc, c1)
+	require.Equal(t, 65536, c.readSize)
+	require.Equal(t, int64(4*65536), c.window.size)
+}
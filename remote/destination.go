@@ -0,0 +1,315 @@
+package remote
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jumppad-labs/connector/integrations"
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// DefaultDialTimeout is the time to wait for a connection to the destination of a service
+const DefaultDialTimeout = 10 * time.Second
+
+// dialRetryDelay is the wait before the first retry of a failed dial, the wait increases
+// with each retry
+var dialRetryDelay = 100 * time.Millisecond
+
+// dialStats are the totals for failed dials to the destinations of a service
+type dialStats struct {
+	lock        sync.Mutex
+	failures    int64
+	retries     int64
+	lastError   string
+	lastFailure time.Time
+}
+
+// failed records a connection which was closed as the destination could not be dialed
+func (d *dialStats) failed(err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.failures++
+	d.lastFailure = time.Now()
+
+	if err != nil {
+		d.lastError = err.Error()
+	}
+}
+
+// retried records a dial which is retried
+func (d *dialStats) retried() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.retries++
+}
+
+// status returns the totals, returns nil when no dial has failed
+func (d *dialStats) status() *shipyard.DialStats {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.failures == 0 && d.retries == 0 {
+		return nil
+	}
+
+	status := &shipyard.DialStats{
+		Failures:  d.failures,
+		Retries:   d.retries,
+		LastError: d.lastError,
+	}
+
+	if !d.lastFailure.IsZero() {
+		status.LastFailure = d.lastFailure.UnixNano()
+	}
+
+	return status
+}
+
+// destinations returns the addresses configured for a service
+func destinations(detail *shipyard.Service) []string {
+	return append([]string{detail.DestinationAddr}, detail.DestinationAddrs...)
+}
+
+// hasUnixDestination returns true when any of the destinations for the service is a Unix socket
+func hasUnixDestination(detail *shipyard.Service) bool {
+	for _, d := range destinations(detail) {
+		if isUnixSocket(d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateDestination returns an error when addr is not host:port or the path of a Unix socket
+func validateDestination(addr string) error {
+	if path, ok := unixSocketPath(addr); ok {
+		if path == "" {
+			return fmt.Errorf("destination address must include the path of the Unix socket")
+		}
+
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("destination address must be host:port, IPv6 addresses must be enclosed in brackets e.g. [::1]:8080: %s", err)
+	}
+
+	return nil
+}
+
+// resolveDestinations returns the addresses for the destinations of the service, integrations
+// which can resolve a name to multiple endpoints return all of them
+func (s *Server) resolveDestinations(detail *shipyard.Service) ([]string, error) {
+	addrs := []string{}
+
+	var lastErr error
+	for _, d := range destinations(detail) {
+		// Unix sockets are not resolved by the integration
+		if isUnixSocket(d) {
+			addrs = append(addrs, d)
+			continue
+		}
+
+		if r, ok := s.integration.(integrations.EndpointResolver); ok {
+			eps, err := r.LookupAddresses(d)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+
+			addrs = append(addrs, eps...)
+			continue
+		}
+
+		a, err := s.lookupIntegration(d)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		addrs = append(addrs, a)
+	}
+
+	if len(addrs) == 0 {
+		return nil, lastErr
+	}
+
+	return addrs, nil
+}
+
+// dialDestination opens a connection to one of the destinations of the service, the
+// dial is retried for the number of retries set for the service
+func (s *Server) dialDestination(svc *service, addrs []string) (net.Conn, *endpoint, error) {
+	timeout := s.serviceDialTimeout(svc.detail)
+
+	var lastErr error
+	for attempt := 0; attempt <= int(svc.detail.DialRetries); attempt++ {
+		if attempt > 0 {
+			svc.dials.retried()
+
+			select {
+			case <-s.ctx.Done():
+				return nil, nil, lastErr
+			case <-time.After(time.Duration(attempt) * dialRetryDelay):
+			}
+		}
+
+		conn, e, err := s.dialEndpoints(svc, addrs, timeout)
+		if err == nil {
+			return conn, e, nil
+		}
+
+		s.log.Debug(
+			"destination",
+			"message", "Unable to connect to any destination",
+			"service_id", svc.detail.Id,
+			"attempt", attempt+1,
+			"error", err)
+
+		lastErr = err
+	}
+
+	svc.dials.failed(lastErr)
+
+	return nil, nil, lastErr
+}
+
+// serviceDialTimeout returns the time to wait for a connection to a destination for the
+// service, the server default is used when the service does not set a timeout
+func (s *Server) serviceDialTimeout(detail *shipyard.Service) time.Duration {
+	if detail.DialTimeout > 0 {
+		return time.Duration(detail.DialTimeout)
+	}
+
+	return s.dialTimeout
+}
+
+// dialEndpoints opens a connection to one of the addresses chosen by the balancer for
+// the service, when a connection fails the next endpoint is tried
+func (s *Server) dialEndpoints(svc *service, addrs []string, timeout time.Duration) (net.Conn, *endpoint, error) {
+	lb := svc.balancer()
+	tried := map[string]bool{}
+
+	var lastErr error
+	for {
+		e := lb.pick(addrs, tried)
+		if e == nil {
+			return nil, nil, lastErr
+		}
+
+		tried[e.addr] = true
+
+		network, addr := dialAddress(svc.detail.Protocol, e.addr)
+		conn, err := net.DialTimeout(network, addr, timeout)
+		if err != nil {
+			s.log.Debug(
+				"destination",
+				"message", "Unable to connect to destination",
+				"service_id", svc.detail.Id,
+				"addr", e.addr,
+				"error", err)
+
+			lb.dialFailed(e, err)
+			lastErr = err
+			continue
+		}
+
+		lb.dialSucceeded(e)
+		e.active.Add(1)
+
+		return conn, e, nil
+	}
+}
+
+// startHealthChecks starts the active health checks for the destinations of the service,
+// the checks stop when the service is removed or the server is shut down
+func (s *Server) startHealthChecks(id string, svc *service) {
+	hc := svc.detail.HealthCheck
+	if hc == nil || hc.Interval <= 0 {
+		return
+	}
+
+	interval := time.Duration(hc.Interval)
+
+	timeout := time.Duration(hc.Timeout)
+	if timeout <= 0 {
+		timeout = interval
+	}
+
+	unhealthy := int(hc.UnhealthyThreshold)
+	if unhealthy <= 0 {
+		unhealthy = defaultUnhealthyThreshold
+	}
+
+	healthy := int(hc.HealthyThreshold)
+	if healthy <= 0 {
+		healthy = defaultHealthyThreshold
+	}
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			if !s.serviceExists(id, svc) {
+				return
+			}
+
+			s.checkDestinations(svc, timeout, unhealthy, healthy)
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// checkDestinations checks each of the destinations for the service can be connected to
+func (s *Server) checkDestinations(svc *service, timeout time.Duration, unhealthy, healthy int) {
+	addrs, err := s.resolveDestinations(svc.detail)
+	if err != nil {
+		s.log.Error("destination", "message", "Unable to resolve destinations for health check", "service_id", svc.detail.Id, "error", err)
+		return
+	}
+
+	lb := svc.balancer()
+	wg := sync.WaitGroup{}
+
+	for _, e := range lb.update(addrs) {
+		wg.Add(1)
+
+		go func(e *endpoint) {
+			defer wg.Done()
+
+			network, addr := dialAddress(shipyard.ServiceProtocol_TCP, e.addr)
+			conn, err := net.DialTimeout(network, addr, timeout)
+			if err != nil {
+				lb.checkFailed(e, err, unhealthy)
+				return
+			}
+
+			conn.Close()
+			lb.checkPassed(e, healthy)
+		}(e)
+	}
+
+	wg.Wait()
+}
+
+// serviceExists returns true when the service is still registered with the server
+func (s *Server) serviceExists(id string, svc *service) bool {
+	si, ok := s.streams.findByServiceID(id)
+	if !ok {
+		return false
+	}
+
+	existing, ok := si.services.get(id)
+	return ok && existing == svc
+}
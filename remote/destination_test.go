@@ -0,0 +1,180 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func dialStatus(t *testing.T, c shipyard.RemoteConnectionClient, id string) *shipyard.DialStats {
+	resp, err := c.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+
+	for _, s := range resp.Services {
+		if s.Id == id {
+			return s.DialStats
+		}
+	}
+
+	return nil
+}
+
+func TestDialStatsRecordsFailuresAndRetries(t *testing.T) {
+	d := dialStats{}
+	require.Nil(t, d.status())
+
+	d.retried()
+	d.failed(errors.New("refused"))
+
+	status := d.status()
+	require.Equal(t, int64(1), status.Failures)
+	require.Equal(t, int64(1), status.Retries)
+	require.Equal(t, "refused", status.LastError)
+	require.NotZero(t, status.LastFailure)
+}
+
+func TestExposeServiceWithFailedDialReportsErrorToListener(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     freeAddress(t),
+			Type:                shipyard.ServiceType_REMOTE,
+			DialTimeout:         int64(time.Second),
+			DialRetries:         2,
+		},
+	})
+	require.NoError(t, err)
+
+	p := waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	// the connection is closed once the remote reports the failed dial
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 5))
+	require.ErrorIs(t, err, io.EOF)
+
+	// the remote connector dials the destination and counts the retries
+	remote := dialStatus(t, createClient(t, servers[1].Address), resp.Id)
+	require.NotNil(t, remote)
+	require.Equal(t, int64(1), remote.Failures)
+	require.Equal(t, int64(2), remote.Retries)
+
+	require.Eventually(t, func() bool {
+		local := dialStatus(t, c, resp.Id)
+		return local != nil && local.Failures == 1 && local.LastError != ""
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestExposeServiceWithNegativeDialRetriesReturnsError(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "localhost:8080",
+			Type:                shipyard.ServiceType_REMOTE,
+			DialRetries:         -1,
+		},
+	})
+	require.Error(t, err)
+}
+
+// blockLookup makes the integration of the remote connector block lookups for the
+// destination until release is closed, the lookup then returns addr and err
+func blockLookup(servers []*serverStruct, dest string, release chan time.Time, addr string, err error) {
+	mi := servers[1].Integration
+
+	mi.ExpectedCalls = []*mock.Call{}
+	mi.On("Register", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mi.On("Deregister", mock.Anything).Return(nil)
+	mi.On("LookupAddress", dest).WaitUntil(release).Return(addr, err)
+	mi.On("LookupAddress", mock.Anything).Return("", nil)
+}
+
+func TestExposeServiceWithBlockedDestinationDoesNotBlockOtherServices(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	release := make(chan time.Time)
+	blockLookup(servers, "blackhole.test:80", release, "", errors.New("no route to host"))
+
+	expose := func(name, dest string) int32 {
+		resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+			Service: &shipyard.Service{
+				Name:                name,
+				RemoteConnectorAddr: servers[1].Address,
+				DestinationAddr:     dest,
+				Type:                shipyard.ServiceType_REMOTE,
+			},
+		})
+		require.NoError(t, err)
+
+		return waitForAllocatedPort(t, servers[0].Server, resp.Id)
+	}
+
+	blocked := dialService(t, expose("Blocked", "blackhole.test:80"))
+	_, err := blocked.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	// the second service uses the same stream while the first is dialing
+	echo := dialService(t, expose("Echo", startEchoServer(t)))
+	echo.SetDeadline(time.Now().Add(5 * time.Second))
+	requireEcho(t, echo)
+
+	// the connection is closed when the dial fails
+	close(release)
+	requireClosed(t, blocked, 5*time.Second)
+}
+
+func TestExposeServiceSendsDataReceivedWhileDialing(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	release := make(chan time.Time)
+	blockLookup(servers, "slow.test:80", release, startEchoServer(t), nil)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Slow",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "slow.test:80",
+			Type:                shipyard.ServiceType_REMOTE,
+		},
+	})
+	require.NoError(t, err)
+
+	conn := dialService(t, waitForAllocatedPort(t, servers[0].Server, resp.Id))
+
+	// data and the close are held until the destination has been dialed
+	for _, d := range []string{"hel", "lo"} {
+		_, err = conn.Write([]byte(d))
+		require.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+	}
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	d, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(d))
+}
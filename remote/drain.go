@@ -0,0 +1,115 @@
+package remote
+
+import (
+	"net"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// DefaultDrainTimeout is the maximum time to wait for active connections to finish
+// when the server is drained
+const DefaultDrainTimeout = 30 * time.Second
+
+// drainPollInterval is the interval used to check for active connections while draining
+var drainPollInterval = 100 * time.Millisecond
+
+// Drain gracefully shuts down the server, the listeners stop accepting new connections
+// and the remote connectors are told that this connector is going away. Active connections
+// are given until the timeout to finish before the server is shut down.
+func (s *Server) Drain(timeout time.Duration) {
+	if !s.draining.CompareAndSwap(false, true) {
+		return
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	s.log.Info("drain", "message", "Draining connections", "timeout", timeout)
+
+	for _, si := range s.streams.all() {
+		si.setLinkState(shipyard.LinkState_DRAINING)
+
+		si.services.iterate(func(id string, svc *service) bool {
+			if l := svc.listener(); l != nil {
+				stopAccepting(l)
+			}
+
+			return true
+		})
+
+		gc := si.getGRPCConn()
+		if gc != nil && si.supports(CapabilityGoingAway) {
+			gc.Send(&shipyard.OpenData{
+				Message: &shipyard.OpenData_GoingAway{
+					GoingAway: &shipyard.GoingAway{Deadline: deadline.UnixNano()},
+				},
+			})
+		}
+	}
+
+	t := time.NewTicker(drainPollInterval)
+	defer t.Stop()
+
+	for {
+		active := s.activeConnections()
+		if active == 0 {
+			s.log.Info("drain", "message", "All connections finished")
+			break
+		}
+
+		if time.Now().After(deadline) {
+			s.log.Info("drain", "message", "Drain timeout reached, closing active connections", "connections", active)
+			break
+		}
+
+		<-t.C
+	}
+
+	s.Shutdown()
+}
+
+// Draining returns true when the server has started to drain
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// activeConnections returns the number of open connections for all services
+func (s *Server) activeConnections() int {
+	count := 0
+
+	for _, si := range s.streams.all() {
+		si.services.iterate(func(id string, svc *service) bool {
+			svc.tcpConnections.Range(func(k, v interface{}) bool {
+				count++
+				return true
+			})
+
+			return true
+		})
+	}
+
+	return count
+}
+
+// stopAccepting stops a listener from accepting new connections, UDP listeners keep
+// the socket open so that the existing peers can continue to send datagrams
+func stopAccepting(l net.Listener) {
+	if ul, ok := l.(*udpListener); ok {
+		ul.stopAccepting()
+		return
+	}
+
+	l.Close()
+}
+
+// handleGoingAwayMessage handles a remote which is draining, no new connections
+// are opened over the stream until it is reconnected
+func (s *Server) handleGoingAwayMessage(si *streamInfo, m *shipyard.OpenData_GoingAway) {
+	s.log.Info(
+		"drain",
+		"message", "Remote connector is going away",
+		"addr", si.addr,
+		"deadline", time.Unix(0, m.GoingAway.Deadline))
+
+	si.setLinkState(shipyard.LinkState_DRAINING)
+}
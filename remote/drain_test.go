@@ -0,0 +1,122 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainWaitsForActiveConnections(t *testing.T) {
+	c, servers := setupPoolTests(t, 1)
+	port := exposeService(t, c, servers[1].Address, startReplyAfterEOFServer(t), shipyard.ServiceType_REMOTE)
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		var err error
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	_, err := conn.Write([]byte("before drain"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return servers[0].Server.activeConnections() == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		servers[0].Server.Drain(10 * time.Second)
+		close(done)
+	}()
+
+	// the remote is told the connector is going away
+	require.Eventually(t, func() bool {
+		local, ok := servers[0].Server.streams.findByRemoteAddr(servers[1].Address)
+		if !ok {
+			return false
+		}
+
+		remote, ok := servers[1].Server.streams.findBySession(local.session)
+		return ok && remote.linkState() == shipyard.LinkState_DRAINING
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// new connections are refused
+	require.Eventually(t, func() bool {
+		c, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err != nil {
+			return true
+		}
+
+		c.Close()
+		return false
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// the active connection continues to work
+	_, err = conn.Write([]byte(" during drain"))
+	require.NoError(t, err)
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+
+	resp, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "received 25 bytes", string(resp))
+
+	require.Eventually(t, func() bool {
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.True(t, servers[0].Server.Closed())
+}
+
+func TestDrainClosesConnectionsAfterTimeout(t *testing.T) {
+	c, servers := setupPoolTests(t, 1)
+	port := exposeEchoService(t, c, servers[1].Address)
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		var err error
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		return servers[0].Server.activeConnections() == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	start := time.Now()
+	servers[0].Server.Drain(200 * time.Millisecond)
+
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.True(t, servers[0].Server.Closed())
+}
+
+func TestExposeIsRejectedWhileDraining(t *testing.T) {
+	s, _, _ := createServer(t, freeAddress(t), "server_local_1")
+	s.Drain(0)
+
+	_, err := s.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: "localhost:1",
+			SourcePort:          30000,
+			DestinationAddr:     "localhost:8080",
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.Error(t, err)
+}
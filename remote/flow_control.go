@@ -0,0 +1,195 @@
+package remote
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultWindowSize is the number of bytes which can be in flight for a single
+// connection before the sender must wait for the receiver to acknowledge them
+const DefaultWindowSize = 256 * 1024 // 256k
+
+// maxQueuedBytes is the most data which can be waiting to be written to a connection,
+// the largest send window is 4 messages of MaxMessageSize so a remote which respects
+// the window never fills the queue
+const maxQueuedBytes = 8 * MaxMessageSize
+
+var (
+	errQueueClosed = errors.New("write queue is closed")
+	errQueueFull   = errors.New("write queue is full, the remote sent more data than the window allows")
+)
+
+// sendWindow tracks the credit a connection has for sending data over the
+// stream. Credit is returned when the remote sends a WindowUpdate message
+// after the data has been written to the destination socket.
+type sendWindow struct {
+	size     int64
+	sent     int64
+	consumed int64
+	closed   bool
+	cond     *sync.Cond
+}
+
+func newSendWindow(size int64) *sendWindow {
+	return &sendWindow{size: size, cond: sync.NewCond(&sync.Mutex{})}
+}
+
+// wait blocks until the window has capacity to send n bytes, the bytes are
+// then reserved. Returns false if the window was closed while waiting.
+func (w *sendWindow) wait(n int) bool {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	// always allow a single message through an empty window, this ensures that
+	// a message larger than the window does not block forever
+	for !w.closed && w.sent > w.consumed && w.sent+int64(n)-w.consumed > w.size {
+		w.cond.Wait()
+	}
+
+	if w.closed {
+		return false
+	}
+
+	w.sent += int64(n)
+	return true
+}
+
+// update sets the total number of bytes which the remote has consumed,
+// updates are cumulative so a stale or repeated update is ignored
+func (w *sendWindow) update(consumed int64) {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	if consumed > w.consumed {
+		w.consumed = consumed
+		w.cond.Broadcast()
+	}
+}
+
+// consumedBytes returns the total number of bytes consumed by the remote
+func (w *sendWindow) consumedBytes() int64 {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	return w.consumed
+}
+
+// close the window releasing any waiting senders
+func (w *sendWindow) close() {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	w.closed = true
+	w.cond.Broadcast()
+}
+
+// writeQueue holds data received from the stream which has not yet been
+// written to the connection. Writing to the socket from the queue rather than
+// the stream receive loop ensures that a slow consumer does not block
+// other connections which share the stream. The queue is limited in size so that
+// a remote which ignores the send window can not exhaust memory.
+type writeQueue struct {
+	items    [][]byte
+	size     int64 // number of bytes in the queue
+	limit    int64 // maximum number of bytes in the queue
+	finished bool
+	aborted  bool
+	cond     *sync.Cond
+}
+
+func newWriteQueue() *writeQueue {
+	return &writeQueue{limit: maxQueuedBytes, cond: sync.NewCond(&sync.Mutex{})}
+}
+
+// push adds data to the queue, returns an error if the queue no longer accepts data
+// or when the data would exceed the limit
+func (q *writeQueue) push(data []byte) error {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	if q.finished || q.aborted {
+		return errQueueClosed
+	}
+
+	if q.size+int64(len(data)) > q.limit {
+		return errQueueFull
+	}
+
+	q.items = append(q.items, data)
+	q.size += int64(len(data))
+	q.cond.Signal()
+
+	return nil
+}
+
+// pop blocks until data is available, returns false once the queue has been
+// finished and is empty or when it has been aborted
+func (q *writeQueue) pop() ([]byte, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	for len(q.items) == 0 && !q.finished && !q.aborted {
+		q.cond.Wait()
+	}
+
+	if q.aborted || len(q.items) == 0 {
+		return nil, false
+	}
+
+	data := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	q.size -= int64(len(data))
+
+	return data, true
+}
+
+// popAll blocks until data is available and returns all the items in the queue, this
+// allows small messages to be written to the connection with a single write
+func (q *writeQueue) popAll() ([][]byte, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	for len(q.items) == 0 && !q.finished && !q.aborted {
+		q.cond.Wait()
+	}
+
+	if q.aborted || len(q.items) == 0 {
+		return nil, false
+	}
+
+	items := q.items
+	q.items = nil
+	q.size = 0
+
+	return items, true
+}
+
+// len returns the number of items waiting to be written
+func (q *writeQueue) len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	return len(q.items)
+}
+
+// finish stops the queue accepting new data, data already in the queue
+// will still be returned by pop
+func (q *writeQueue) finish() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.finished = true
+	q.cond.Broadcast()
+}
+
+// abort stops the queue and discards any pending data
+func (q *writeQueue) abort() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.aborted = true
+	q.items = nil
+	q.size = 0
+	q.cond.Broadcast()
+}
@@ -0,0 +1,120 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendWindowBlocksWhenFull(t *testing.T) {
+	w := newSendWindow(10)
+
+	require.True(t, w.wait(10))
+
+	done := make(chan bool)
+	go func() {
+		done <- w.wait(5)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected wait to block while the window is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w.update(5)
+
+	select {
+	case ok := <-done:
+		require.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("expected wait to return after the window was updated")
+	}
+}
+
+func TestSendWindowAllowsLargeMessageWhenEmpty(t *testing.T) {
+	w := newSendWindow(10)
+
+	require.True(t, w.wait(20))
+}
+
+func TestSendWindowIgnoresStaleUpdates(t *testing.T) {
+	w := newSendWindow(10)
+	w.update(8)
+	w.update(4)
+
+	require.Equal(t, int64(8), w.consumed)
+}
+
+func TestSendWindowCloseReleasesWaiters(t *testing.T) {
+	w := newSendWindow(10)
+	require.True(t, w.wait(10))
+
+	done := make(chan bool)
+	go func() {
+		done <- w.wait(5)
+	}()
+
+	w.close()
+
+	select {
+	case ok := <-done:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("expected close to release waiting senders")
+	}
+}
+
+func TestWriteQueueReturnsDataAfterFinish(t *testing.T) {
+	q := newWriteQueue()
+	require.NoError(t, q.push([]byte("a")))
+	require.NoError(t, q.push([]byte("b")))
+
+	q.finish()
+	require.ErrorIs(t, q.push([]byte("c")), errQueueClosed)
+
+	d, ok := q.pop()
+	require.True(t, ok)
+	require.Equal(t, "a", string(d))
+
+	d, ok = q.pop()
+	require.True(t, ok)
+	require.Equal(t, "b", string(d))
+
+	_, ok = q.pop()
+	require.False(t, ok)
+}
+
+func TestWriteQueueAbortDiscardsData(t *testing.T) {
+	q := newWriteQueue()
+	require.NoError(t, q.push([]byte("a")))
+
+	q.abort()
+
+	_, ok := q.pop()
+	require.False(t, ok)
+}
+
+func TestWriteQueuePopAllReturnsAllItems(t *testing.T) {
+	q := newWriteQueue()
+	q.push([]byte("a"))
+	q.push([]byte("b"))
+
+	items, ok := q.popAll()
+	require.True(t, ok)
+	require.Len(t, items, 2)
+	require.Equal(t, 0, q.len())
+}
+
+func TestWriteQueueRejectsDataOverLimit(t *testing.T) {
+	q := newWriteQueue()
+	q.limit = 4
+
+	require.NoError(t, q.push([]byte("abc")))
+	require.ErrorIs(t, q.push([]byte("de")), errQueueFull)
+
+	_, ok := q.pop()
+	require.True(t, ok)
+	require.NoError(t, q.push([]byte("de")))
+}
@@ -0,0 +1,266 @@
+package remote
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// ProtocolVersion is the version of the stream protocol implemented by the server
+const ProtocolVersion = 2
+
+// MinProtocolVersion is the oldest protocol version the server can communicate with,
+// version 1 connectors do not send a Handshake
+const MinProtocolVersion = 1
+
+// handshakeTimeout is the time a client waits for the server to reply to the Handshake
+// before assuming the server is a version 1 connector
+var handshakeTimeout = 5 * time.Second
+
+// Capabilities are optional features which are only used when both sides of a stream support them
+const (
+	CapabilityFlowControl  = "flow_control"  // WindowUpdate messages
+	CapabilitySessions     = "sessions"      // resuming interrupted streams
+	CapabilityStreamPool   = "stream_pool"   // sharding a link across multiple streams
+	CapabilityUDP          = "udp"           // UDP services
+	CapabilityHalfClose    = "half_close"    // WriteDone and ReadDone messages
+	CapabilityHeartbeat    = "heartbeat"     // Ping and Pong messages
+	CapabilityGoingAway    = "going_away"    // GoingAway messages
+	CapabilityGzip         = "gzip"          // gzip compressed Data messages
+	CapabilityDynamicPort  = "dynamic_port"  // allocated source ports sent in StatusUpdate
+	CapabilityBindAddress  = "bind_address"  // listeners bound to the service bind address
+	CapabilityUnixSocket   = "unix_socket"   // unix:// bind and destination addresses
+	CapabilityLoadBalance  = "load_balance"  // multiple destinations and health checks
+	CapabilityDialOptions  = "dial_options"  // dial timeouts and retries for destinations
+	CapabilityConnLimits   = "conn_limits"   // idle timeouts, max lifetimes and max connections
+	CapabilityHTTPRoutes   = "http_routes"   // routing HTTP requests with match rules
+	CapabilityVirtualHosts = "virtual_hosts" // listeners shared by services with virtual hosts
+	CapabilityProxy        = "proxy"         // NewConnection and Connected messages for proxy services
+	CapabilityProxyHeader  = "proxy_header"  // client addresses and PROXY protocol headers sent to destinations
+)
+
+// capabilities returns the capabilities supported by the server
+func (s *Server) capabilities() []string {
+	return []string{
+		CapabilityFlowControl,
+		CapabilitySessions,
+		CapabilityStreamPool,
+		CapabilityUDP,
+		CapabilityHalfClose,
+		CapabilityHeartbeat,
+		CapabilityGoingAway,
+		CapabilityGzip,
+		CapabilityDynamicPort,
+		CapabilityBindAddress,
+		CapabilityUnixSocket,
+		CapabilityLoadBalance,
+		CapabilityDialOptions,
+		CapabilityConnLimits,
+		CapabilityHTTPRoutes,
+		CapabilityVirtualHosts,
+		CapabilityProxy,
+		CapabilityProxyHeader,
+	}
+}
+
+func (s *Server) handshakeMessage() *shipyard.OpenData {
+	return &shipyard.OpenData{
+		Message: &shipyard.OpenData_Handshake{
+			Handshake: &shipyard.Handshake{
+				Version:      ProtocolVersion,
+				MinVersion:   MinProtocolVersion,
+				Capabilities: s.capabilities(),
+			},
+		},
+	}
+}
+
+// checkHandshake returns an error when the protocol version of the peer is not
+// compatible with the server
+func checkHandshake(h *shipyard.Handshake) error {
+	if h.Version < MinProtocolVersion {
+		return fmt.Errorf("remote connector protocol version %d is not supported, minimum version is %d", h.Version, MinProtocolVersion)
+	}
+
+	if h.MinVersion > ProtocolVersion {
+		return fmt.Errorf("remote connector requires protocol version %d or later, this connector supports version %d", h.MinVersion, ProtocolVersion)
+	}
+
+	return nil
+}
+
+// peerInfo holds the protocol version and capabilities negotiated with the remote
+type peerInfo struct {
+	version      int32
+	capabilities map[string]bool
+}
+
+// legacyPeer is used until a Handshake has been received, version 1
+// connectors do not support any capabilities
+var legacyPeer = peerInfo{version: 1}
+
+// setPeer sets the version and the capabilities supported by both sides of the stream
+func (si *streamInfo) setPeer(h *shipyard.Handshake, local []string) {
+	supported := map[string]bool{}
+	for _, c := range local {
+		supported[c] = true
+	}
+
+	caps := map[string]bool{}
+	for _, c := range h.Capabilities {
+		if supported[c] {
+			caps[c] = true
+		}
+	}
+
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	si.peer = peerInfo{version: h.Version, capabilities: caps}
+}
+
+// setPeerFrom copies the negotiated version and capabilities from another stream,
+// this is used when a session is resumed on a new stream
+func (si *streamInfo) setPeerFrom(other *streamInfo) {
+	other.updateMutex.Lock()
+	peer := other.peer
+	other.updateMutex.Unlock()
+
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	si.peer = peer
+}
+
+// supports returns true when both sides of the stream support the capability
+func (si *streamInfo) supports(capability string) bool {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	return si.peer.capabilities[capability]
+}
+
+// peerVersion returns the protocol version of the remote
+func (si *streamInfo) peerVersion() int32 {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	return si.peer.version
+}
+
+// handleHandshakeMessage handles the Handshake sent by a client when it opens a stream,
+// the server replies with its own version and capabilities. Returns an error when the
+// client is not compatible, the client is sent a StatusUpdate containing the error.
+func (s *Server) handleHandshakeMessage(si *streamInfo, m *shipyard.OpenData_Handshake) error {
+	s.log.Debug(
+		"handshake",
+		"message", "Received handshake",
+		"addr", si.addr,
+		"version", m.Handshake.Version,
+		"capabilities", m.Handshake.Capabilities)
+
+	si.grpcConn.Send(s.handshakeMessage())
+
+	err := checkHandshake(m.Handshake)
+	if err != nil {
+		s.log.Error(
+			"handshake",
+			"message", "Incompatible protocol version",
+			"addr", si.addr,
+			"error", err)
+
+		si.grpcConn.Send(&shipyard.OpenData{
+			Message: &shipyard.OpenData_StatusUpdate{
+				StatusUpdate: &shipyard.StatusUpdate{
+					Status:  shipyard.ServiceStatus_ERROR,
+					Message: err.Error(),
+				},
+			},
+		})
+
+		return err
+	}
+
+	si.setPeer(m.Handshake, s.capabilities())
+
+	return nil
+}
+
+// handleHandshakeReply handles the Handshake sent by the server in reply to the
+// client opening a stream
+func (s *Server) handleHandshakeReply(si *streamInfo, m *shipyard.OpenData_Handshake) {
+	s.log.Debug(
+		"handshake",
+		"message", "Received handshake reply",
+		"addr", si.addr,
+		"version", m.Handshake.Version,
+		"capabilities", m.Handshake.Capabilities)
+
+	err := checkHandshake(m.Handshake)
+	if err != nil {
+		s.log.Error(
+			"handshake",
+			"message", "Incompatible protocol version",
+			"addr", si.addr,
+			"error", err)
+
+		s.setLinkError(si, err.Error())
+	} else {
+		si.setPeer(m.Handshake, s.capabilities())
+	}
+
+	si.handshakeComplete()
+}
+
+// handshakeComplete releases a client waiting for the handshake reply
+func (si *streamInfo) handshakeComplete() {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	if si.handshake != nil {
+		close(si.handshake)
+		si.handshake = nil
+	}
+}
+
+// startHandshake returns a channel which is closed when the reply to the handshake is received
+func (si *streamInfo) startHandshake() <-chan struct{} {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	si.peer = legacyPeer
+	si.handshake = make(chan struct{})
+
+	return si.handshake
+}
+
+// waitForHandshake waits for the remote to reply to the handshake, remotes which
+// do not reply within the timeout are version 1 connectors
+func (s *Server) waitForHandshake(si *streamInfo, done <-chan struct{}) {
+	select {
+	case <-done:
+	case <-time.After(handshakeTimeout):
+		s.log.Info(
+			"handshake",
+			"message", "No handshake reply from remote, using protocol version 1",
+			"addr", si.addr)
+
+		si.handshakeComplete()
+	case <-s.ctx.Done():
+	}
+}
+
+// setLinkError sets the status of all the services which use the stream to error
+func (s *Server) setLinkError(si *streamInfo, message string) {
+	si.services.iterate(func(id string, svc *service) bool {
+		s.log.Error(
+			"handshake",
+			"message", "Service can not be exposed",
+			"service_id", id,
+			"error", message)
+
+		svc.setStatus(shipyard.ServiceStatus_ERROR)
+		return true
+	})
+}
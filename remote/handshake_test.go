@@ -0,0 +1,217 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// fakeRemote is a remote connector which records the messages it receives, if
+// reply is set it is sent in response to a Handshake. When reject is set the stream
+// is closed with the error after the reply.
+type fakeRemote struct {
+	shipyard.UnimplementedRemoteConnectionServer
+
+	reply  *shipyard.Handshake
+	reject error
+
+	lock     sync.Mutex
+	messages []*shipyard.OpenData
+	streams  int
+	opened   []time.Time
+}
+
+func (f *fakeRemote) OpenStream(svr shipyard.RemoteConnection_OpenStreamServer) error {
+	f.lock.Lock()
+	f.streams++
+	f.opened = append(f.opened, time.Now())
+	f.lock.Unlock()
+
+	for {
+		msg, err := svr.Recv()
+		if err != nil {
+			return err
+		}
+
+		f.lock.Lock()
+		f.messages = append(f.messages, msg)
+		f.lock.Unlock()
+
+		if _, ok := msg.Message.(*shipyard.OpenData_Handshake); ok && f.reply != nil {
+			svr.Send(&shipyard.OpenData{Message: &shipyard.OpenData_Handshake{Handshake: f.reply}})
+		}
+
+		if _, ok := msg.Message.(*shipyard.OpenData_Handshake); ok && f.reject != nil {
+			return f.reject
+		}
+	}
+}
+
+func (f *fakeRemote) received(t interface{}) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, m := range f.messages {
+		if fmt.Sprintf("%T", m.Message) == fmt.Sprintf("%T", t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *fakeRemote) streamCount() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.streams
+}
+
+// streamTimes returns the times streams were opened
+func (f *fakeRemote) streamTimes() []time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return append([]time.Time{}, f.opened...)
+}
+
+func startFakeRemote(t *testing.T, reply *shipyard.Handshake) (*fakeRemote, string) {
+	return startFakeRemoteOn(t, "localhost:0", reply)
+}
+
+func startFakeRemoteOn(t *testing.T, addr string, reply *shipyard.Handshake) (*fakeRemote, string) {
+	return startFakeRemoteWith(t, addr, &fakeRemote{reply: reply})
+}
+
+func startFakeRemoteWith(t *testing.T, addr string, f *fakeRemote) (*fakeRemote, string) {
+
+	grpcServer := grpc.NewServer()
+	shipyard.RegisterRemoteConnectionServer(grpcServer, f)
+
+	lis, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+
+	go grpcServer.Serve(lis)
+
+	t.Cleanup(func() {
+		grpcServer.Stop()
+	})
+
+	return f, lis.Addr().String()
+}
+
+func exposeToFakeRemote(t *testing.T, addr string, protocol shipyard.ServiceProtocol, opts ...Option) (*Server, string) {
+	s, _, _ := createServer(t, freeAddress(t), "server_local_1", opts...)
+
+	resp, err := s.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: addr,
+			SourcePort:          int32(freePort(t)),
+			DestinationAddr:     "localhost:8080",
+			Type:                shipyard.ServiceType_LOCAL,
+			Protocol:            protocol,
+		},
+	})
+	require.NoError(t, err)
+
+	return s, resp.Id
+}
+
+func TestCheckHandshakeAcceptsCompatibleVersions(t *testing.T) {
+	require.NoError(t, checkHandshake(&shipyard.Handshake{Version: ProtocolVersion, MinVersion: MinProtocolVersion}))
+	require.NoError(t, checkHandshake(&shipyard.Handshake{Version: ProtocolVersion + 1, MinVersion: ProtocolVersion}))
+}
+
+func TestCheckHandshakeRejectsIncompatibleVersions(t *testing.T) {
+	require.Error(t, checkHandshake(&shipyard.Handshake{Version: 0}))
+	require.Error(t, checkHandshake(&shipyard.Handshake{Version: ProtocolVersion + 1, MinVersion: ProtocolVersion + 1}))
+}
+
+func TestSetPeerOnlyEnablesCommonCapabilities(t *testing.T) {
+	si := newStreamInfo()
+	require.False(t, si.supports(CapabilityFlowControl))
+
+	si.setPeer(
+		&shipyard.Handshake{Version: ProtocolVersion, Capabilities: []string{CapabilityFlowControl, "unknown"}},
+		[]string{CapabilityFlowControl, CapabilitySessions},
+	)
+
+	require.True(t, si.supports(CapabilityFlowControl))
+	require.False(t, si.supports(CapabilitySessions))
+	require.False(t, si.supports("unknown"))
+}
+
+func TestHandshakeNegotiatesCapabilities(t *testing.T) {
+	c, servers := setupPoolTests(t, 1)
+	exposeEchoService(t, c, servers[1].Address)
+
+	require.Eventually(t, func() bool {
+		local, ok := servers[0].Server.streams.findByRemoteAddr(servers[1].Address)
+		if !ok || !local.supports(CapabilitySessions) {
+			return false
+		}
+
+		remote, ok := servers[1].Server.streams.findBySession(local.session)
+		return ok && remote.supports(CapabilitySessions) && remote.peerVersion() == ProtocolVersion
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestHandshakeFallsBackToVersion1WhenRemoteDoesNotReply(t *testing.T) {
+	handshakeTimeout = 100 * time.Millisecond
+	t.Cleanup(func() {
+		handshakeTimeout = 5 * time.Second
+	})
+
+	f, addr := startFakeRemote(t, nil)
+	s, _ := exposeToFakeRemote(t, addr, shipyard.ServiceProtocol_TCP)
+
+	require.Eventually(t, func() bool {
+		return f.received(&shipyard.OpenData_Expose{})
+	}, 5*time.Second, 50*time.Millisecond)
+
+	si, ok := s.streams.findByRemoteAddr(addr)
+	require.True(t, ok)
+	require.Equal(t, int32(1), si.peerVersion())
+	require.False(t, si.supports(CapabilitySessions))
+
+	// version 1 remotes must not be sent messages they do not understand
+	require.False(t, f.received(&shipyard.OpenData_Session{}))
+}
+
+func TestUDPServiceErrorsWhenRemoteDoesNotSupportUDP(t *testing.T) {
+	_, addr := startFakeRemote(t, &shipyard.Handshake{Version: ProtocolVersion, MinVersion: MinProtocolVersion})
+	s, id := exposeToFakeRemote(t, addr, shipyard.ServiceProtocol_UDP)
+
+	require.Eventually(t, func() bool {
+		si, ok := s.streams.findByServiceID(id)
+		if !ok {
+			return false
+		}
+
+		svc, _ := si.services.get(id)
+		return svc.status() == shipyard.ServiceStatus_ERROR
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestIncompatibleRemoteSetsServiceError(t *testing.T) {
+	_, addr := startFakeRemote(t, &shipyard.Handshake{Version: ProtocolVersion + 1, MinVersion: ProtocolVersion + 1})
+	s, id := exposeToFakeRemote(t, addr, shipyard.ServiceProtocol_TCP)
+
+	require.Eventually(t, func() bool {
+		si, ok := s.streams.findByServiceID(id)
+		if !ok {
+			return false
+		}
+
+		svc, _ := si.services.get(id)
+		return svc.status() == shipyard.ServiceStatus_ERROR
+	}, 5*time.Second, 50*time.Millisecond)
+}
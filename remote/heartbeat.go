@@ -0,0 +1,140 @@
+package remote
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// DefaultHeartbeatInterval is the time between heartbeats sent to the remote
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// DefaultHeartbeatMisses is the number of consecutive heartbeats which can be missed
+// before the remote is considered dead
+const DefaultHeartbeatMisses = 3
+
+// heartbeat tracks the pings sent to the remote and the replies received
+type heartbeat struct {
+	lock        sync.Mutex
+	outstanding bool // a ping has been sent which has not been answered
+	misses      int
+	rtt         time.Duration
+	last        time.Time
+}
+
+// tick records that a new ping is being sent, returns the number of consecutive
+// pings which have not been answered
+func (h *heartbeat) tick() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.outstanding {
+		h.misses++
+	}
+
+	h.outstanding = true
+
+	return h.misses
+}
+
+// pong records the reply to a ping
+func (h *heartbeat) pong(sent time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.last = time.Now()
+	h.rtt = h.last.Sub(sent)
+	h.outstanding = false
+	h.misses = 0
+}
+
+// reset clears the state when a new stream is opened
+func (h *heartbeat) reset() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.outstanding = false
+	h.misses = 0
+}
+
+// status returns the link status for the heartbeat
+func (h *heartbeat) status() *shipyard.LinkStatus {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	ls := &shipyard.LinkStatus{
+		RoundTripTime:    int64(h.rtt),
+		MissedHeartbeats: int32(h.misses),
+	}
+
+	if !h.last.IsZero() {
+		ls.LastHeartbeat = h.last.UnixNano()
+	}
+
+	return ls
+}
+
+// heartbeatTicker returns a ticker for sending heartbeats, when heartbeats
+// are disabled the returned channel never fires
+func (s *Server) heartbeatTicker() (<-chan time.Time, func()) {
+	if s.heartbeatInterval <= 0 {
+		return nil, func() {}
+	}
+
+	t := time.NewTicker(s.heartbeatInterval)
+
+	return t.C, t.Stop
+}
+
+// sendHeartbeat sends a ping to the remote on the given stream, returns an error
+// when too many pings have not been answered and the remote should be considered dead
+func (s *Server) sendHeartbeat(si *streamInfo, gc *grpcConn) error {
+	if !si.supports(CapabilityHeartbeat) {
+		return nil
+	}
+
+	misses := si.heartbeat.tick()
+	if misses >= s.heartbeatMisses {
+		return fmt.Errorf("remote did not reply to %d heartbeats", misses)
+	}
+
+	if misses > 0 {
+		s.log.Debug(
+			"heartbeat",
+			"message", "Heartbeat not answered",
+			"addr", si.addr,
+			"misses", misses)
+	}
+
+	// sending may block when the stream is dead, do not block the receive loop
+	go gc.Send(&shipyard.OpenData{
+		Message: &shipyard.OpenData_Ping{Ping: &shipyard.Ping{Sent: time.Now().UnixNano()}},
+	})
+
+	return nil
+}
+
+// handlePingMessage replies to a heartbeat on the stream it was received on, the
+// empty ping sent by version 1 connectors when they open a stream is ignored
+func (s *Server) handlePingMessage(gc *grpcConn, m *shipyard.OpenData_Ping) {
+	if m.Ping.Sent == 0 {
+		return
+	}
+
+	gc.Send(&shipyard.OpenData{
+		Message: &shipyard.OpenData_Pong{Pong: &shipyard.Pong{Sent: m.Ping.Sent}},
+	})
+}
+
+// handlePongMessage records the round trip time for a heartbeat
+func (s *Server) handlePongMessage(si *streamInfo, m *shipyard.OpenData_Pong) {
+	si.heartbeat.pong(time.Unix(0, m.Pong.Sent))
+
+	s.log.Trace(
+		"heartbeat",
+		"message", "Received heartbeat reply",
+		"addr", si.addr,
+		"rtt", time.Since(time.Unix(0, m.Pong.Sent)))
+}
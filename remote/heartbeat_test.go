@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeatCountsUnansweredPings(t *testing.T) {
+	h := &heartbeat{}
+
+	require.Equal(t, 0, h.tick())
+	require.Equal(t, 1, h.tick())
+	require.Equal(t, 2, h.tick())
+
+	h.pong(time.Now().Add(-10 * time.Millisecond))
+	require.Equal(t, 0, h.tick())
+
+	ls := h.status()
+	require.GreaterOrEqual(t, time.Duration(ls.RoundTripTime), 10*time.Millisecond)
+	require.NotZero(t, ls.LastHeartbeat)
+}
+
+func TestHeartbeatRecordsRoundTripTimeInListServices(t *testing.T) {
+	a1 := freeAddress(t)
+	a2 := freeAddress(t)
+
+	s1, _, _ := createServer(t, a1, "server_local_1", WithHeartbeat(50*time.Millisecond, 3))
+	createServer(t, a2, "server_remote_1", WithHeartbeat(50*time.Millisecond, 3))
+
+	exposeEchoService(t, createClient(t, a1), a2)
+
+	require.Eventually(t, func() bool {
+		resp, err := s1.ListServices(context.Background(), &shipyard.NullMessage{})
+		if err != nil || len(resp.Services) != 1 || resp.Services[0].Link == nil {
+			return false
+		}
+
+		return resp.Services[0].Link.RoundTripTime > 0 && resp.Services[0].Link.LastHeartbeat > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestDeadRemoteIsDetectedAndReconnected(t *testing.T) {
+	// the remote supports heartbeats but never replies to them
+	f, addr := startFakeRemote(t, &shipyard.Handshake{
+		Version:      ProtocolVersion,
+		MinVersion:   MinProtocolVersion,
+		Capabilities: []string{CapabilityHeartbeat},
+	})
+
+	exposeToFakeRemote(t, addr, shipyard.ServiceProtocol_TCP, WithHeartbeat(20*time.Millisecond, 2), WithBackoff(10*time.Millisecond, 10*time.Millisecond, 2, 0))
+
+	require.Eventually(t, func() bool {
+		return f.streamCount() >= 2
+	}, 5*time.Second, 20*time.Millisecond)
+}
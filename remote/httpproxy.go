@@ -0,0 +1,198 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/grpc/codes"
+)
+
+// httpProxy is a forward proxy for the connections accepted by the listener for an HTTP
+// proxy service. CONNECT requests open a tunnel to the requested destination and requests
+// with an absolute URL are sent to the host in the URL, the destinations are dialed by
+// the remote connector.
+type httpProxy struct {
+	s         *Server
+	serviceID string
+	l         net.Listener
+	conns     *connListener
+	server    *http.Server
+	transport *http.Transport
+	forward   *httputil.ReverseProxy
+}
+
+// newHTTPProxy creates a proxy for the connections accepted by the listener l
+func (s *Server) newHTTPProxy(serviceID string, l net.Listener) *httpProxy {
+	p := &httpProxy{
+		s:         s,
+		serviceID: serviceID,
+		l:         l,
+		conns:     newConnListener(l.Addr()),
+	}
+
+	p.transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return s.dialProxyStream(serviceID, l, addr)
+		},
+		DisableCompression: true,
+	}
+
+	p.forward = &httputil.ReverseProxy{
+		// requests with an absolute URL already contain the destination
+		Director:      func(req *http.Request) {},
+		Transport:     p.transport,
+		FlushInterval: -1,
+		ErrorHandler: func(rw http.ResponseWriter, req *http.Request, err error) {
+			s.log.Debug(
+				"proxy",
+				"message", "Unable to send request to destination",
+				"service_id", serviceID,
+				"url", req.URL.String(),
+				"error", err)
+
+			rw.WriteHeader(proxyStatusCode(err))
+		},
+	}
+
+	p.server = &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: proxyConnectTimeout,
+		ErrorLog:          s.log.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true}),
+	}
+
+	go p.server.Serve(p.conns)
+
+	return p
+}
+
+// ServeHTTP implements the http.Handler interface
+func (p *httpProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodConnect {
+		p.tunnel(rw, req)
+		return
+	}
+
+	if req.URL.Scheme != "http" || req.URL.Host == "" {
+		http.Error(rw, "Only CONNECT requests and requests with an absolute http URL are supported", http.StatusBadRequest)
+		return
+	}
+
+	p.s.log.Info(
+		"proxy",
+		"message", "Proxy request",
+		"service_id", p.serviceID,
+		"method", req.Method,
+		"url", req.URL.String(),
+		"client", req.RemoteAddr)
+
+	p.forward.ServeHTTP(rw, req)
+}
+
+// tunnel opens a connection over the stream to the destination of a CONNECT request,
+// once the destination has been dialed the client connection is used for the tunnel
+func (p *httpProxy) tunnel(rw http.ResponseWriter, req *http.Request) {
+	dest := req.Host
+	if _, _, err := net.SplitHostPort(dest); err != nil {
+		http.Error(rw, "CONNECT requests must be for host:port", http.StatusBadRequest)
+		return
+	}
+
+	si, svc, ok := p.s.listenerService(p.serviceID)
+	if !ok {
+		http.Error(rw, "Service does not exist", http.StatusServiceUnavailable)
+		return
+	}
+
+	hj, ok := rw.(http.Hijacker)
+	if !ok {
+		http.Error(rw, "CONNECT is not supported for the protocol", http.StatusBadRequest)
+		return
+	}
+
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		p.s.log.Debug("proxy", "message", "Unable to hijack connection", "service_id", p.serviceID, "error", err)
+		return
+	}
+
+	p.s.log.Info(
+		"proxy",
+		"message", "Proxy request",
+		"service_id", p.serviceID,
+		"method", req.Method,
+		"destination", dest,
+		"client", req.RemoteAddr)
+
+	// data sent by the client before the reply has already been read into the buffer
+	c := conn
+	if brw.Reader.Buffered() > 0 {
+		c = &replayConn{Conn: conn, r: brw.Reader}
+	}
+
+	conn.SetDeadline(time.Now().Add(proxyConnectTimeout))
+
+	p.s.openProxyConnection(p.serviceID, si, svc, p.l, c, dest, func(err error) error {
+		if err != nil {
+			code := proxyStatusCode(err)
+			_, err := fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", code, http.StatusText(code))
+			return err
+		}
+
+		_, err = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		return err
+	})
+}
+
+// serve hands a connection accepted by the listener to the HTTP server
+func (p *httpProxy) serve(conn net.Conn) {
+	p.conns.serve(conn)
+}
+
+// close stops the HTTP server, tunnels and forwarded requests are connections for the
+// service and are closed with it
+func (p *httpProxy) close() {
+	p.server.Close()
+	p.transport.CloseIdleConnections()
+}
+
+// dialProxyStream opens a connection over the stream to the destination requested by a
+// client of a proxy service
+func (s *Server) dialProxyStream(serviceID string, l net.Listener, dest string) (net.Conn, error) {
+	si, svc, ok := s.listenerService(serviceID)
+	if !ok {
+		return nil, fmt.Errorf("service %s does not exist", serviceID)
+	}
+
+	var dialErr error
+	client, conn := net.Pipe()
+
+	s.openProxyConnection(serviceID, si, svc, l, conn, dest, func(err error) error {
+		dialErr = err
+		return nil
+	})
+
+	if dialErr != nil {
+		client.Close()
+		return nil, dialErr
+	}
+
+	return client, nil
+}
+
+// proxyStatusCode returns the HTTP status for an error opening a proxy connection
+func proxyStatusCode(err error) int {
+	switch proxyErrorCode(err) {
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusBadGateway
+}
@@ -0,0 +1,94 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+// exposeHTTPProxyService exposes an HTTP proxy on servers[0] which dials destinations from
+// servers[1], returns the address of the proxy
+func exposeHTTPProxyService(t *testing.T, c shipyard.RemoteConnectionClient, servers []*serverStruct) string {
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test Proxy",
+			RemoteConnectorAddr: servers[1].Address,
+			Type:                shipyard.ServiceType_REMOTE,
+			Proxy:               shipyard.ProxyProtocol_HTTP,
+		},
+	})
+	require.NoError(t, err)
+
+	return fmt.Sprintf("localhost:%d", waitForAllocatedPort(t, servers[0].Server, resp.Id))
+}
+
+// connect sends a CONNECT request for the destination to the proxy, returns the status
+// code of the reply and the connection
+func connect(t *testing.T, proxy, dest string) (int, net.Conn) {
+	conn, err := net.Dial("tcp", proxy)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	req, _ := http.NewRequest(http.MethodConnect, "", nil)
+	req.Host = dest
+	req.URL = &url.URL{Host: dest}
+	require.NoError(t, req.Write(conn))
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	require.NoError(t, err)
+
+	return resp.StatusCode, conn
+}
+
+func TestHTTPProxyTunnelsCONNECTRequests(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	WithProxyAllowList([]string{"localhost"})(servers[1].Server)
+
+	_, port, _ := net.SplitHostPort(startEchoServer(t))
+
+	code, conn := connect(t, exposeHTTPProxyService(t, c, servers), net.JoinHostPort("localhost", port))
+	require.Equal(t, http.StatusOK, code)
+
+	requireEcho(t, conn)
+}
+
+func TestHTTPProxyForwardsAbsoluteRequests(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	WithProxyAllowList([]string{"127.0.0.0/8"})(servers[1].Server)
+
+	u, _ := url.Parse("http://" + exposeHTTPProxyService(t, c, servers))
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(u)}}
+
+	for _, name := range []string{"a", "b"} {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+startHTTPServer(t, name)+"/", nil)
+		requireResponse(t, client, req, name+" HTTP/1.1")
+	}
+}
+
+func TestHTTPProxyRefusesDeniedDestinations(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	WithProxyAllowList([]string{"*"})(servers[1].Server)
+	WithProxyDenyList([]string{"127.0.0.0/8", "::1"})(servers[1].Server)
+
+	proxy := exposeHTTPProxyService(t, c, servers)
+	_, port, _ := net.SplitHostPort(startEchoServer(t))
+
+	code, _ := connect(t, proxy, net.JoinHostPort("localhost", port))
+	require.Equal(t, http.StatusForbidden, code)
+
+	u, _ := url.Parse("http://" + proxy)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(u)}}
+
+	resp, err := client.Get("http://" + net.JoinHostPort("localhost", port) + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,140 @@
+package remote
+
+import (
+	"net"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// connLimiter limits the number of concurrent connections accepted for a service
+type connLimiter struct {
+	slots chan struct{}
+}
+
+func newConnLimiter(max int) *connLimiter {
+	return &connLimiter{slots: make(chan struct{}, max)}
+}
+
+// tryAcquire takes a slot for a new connection, returns false when every slot is in use
+func (l *connLimiter) tryAcquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// acquire waits until a slot is available for a new connection
+func (l *connLimiter) acquire() {
+	l.slots <- struct{}{}
+}
+
+// release returns the slot for a closed connection
+func (l *connLimiter) release() {
+	<-l.slots
+}
+
+// acquireConnectionSlot takes a slot for a connection accepted by the listener when the
+// service limits concurrent connections. When every slot is in use the connection is
+// refused, or for services which queue connections it waits until a connection closes.
+// Returns false when the connection must be closed.
+func (s *Server) acquireConnectionSlot(svc *service, l net.Listener) bool {
+	lim := svc.limiter()
+	if lim == nil || lim.tryAcquire() {
+		return true
+	}
+
+	if svc.detail.ConnectionLimitAction != shipyard.ConnectionLimitAction_QUEUE {
+		s.log.Debug(
+			"listener",
+			"message", "Service has reached the maximum number of connections, refusing connection",
+			"service_id", svc.detail.Id,
+			"max_connections", svc.detail.MaxConnections)
+
+		return false
+	}
+
+	s.log.Debug(
+		"listener",
+		"message", "Service has reached the maximum number of connections, waiting for a connection to close",
+		"service_id", svc.detail.Id,
+		"max_connections", svc.detail.MaxConnections)
+
+	lim.acquire()
+
+	// the connections are closed when the listener is torn down which releases
+	// the slots, the queued connection must not use a listener which is closed
+	if svc.listener() != l {
+		lim.release()
+		return false
+	}
+
+	return true
+}
+
+// watchConnection closes the connection once it has been idle, or open, for longer than
+// the limits set for the service
+func (s *Server) watchConnection(svc *service, c *bufferedConn) {
+	idle := time.Duration(svc.detail.IdleTimeout)
+	lifetime := time.Duration(svc.detail.MaxLifetime)
+
+	if idle <= 0 && lifetime <= 0 {
+		return
+	}
+
+	opened := time.Now()
+
+	// next returns the time until the connection must be checked, or the reason the
+	// connection has exceeded a limit
+	next := func() (time.Duration, string) {
+		now := time.Now()
+		wait := time.Duration(-1)
+
+		if lifetime > 0 {
+			wait = opened.Add(lifetime).Sub(now)
+			if wait <= 0 {
+				return 0, "Connection has reached the maximum lifetime, closing"
+			}
+		}
+
+		if idle > 0 {
+			d := time.Unix(0, c.lastActive.Load()).Add(idle).Sub(now)
+			if d <= 0 {
+				return 0, "Connection has been idle for longer than the idle timeout, closing"
+			}
+
+			if wait < 0 || d < wait {
+				wait = d
+			}
+		}
+
+		return wait, ""
+	}
+
+	go func() {
+		for {
+			wait, reason := next()
+			if reason != "" {
+				s.log.Debug(
+					"connection",
+					"message", reason,
+					"service_id", svc.detail.Id,
+					"connection_id", c.id)
+
+				c.Close()
+				return
+			}
+
+			t := time.NewTimer(wait)
+
+			select {
+			case <-c.done:
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,204 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+// exposeLimitedService exposes a remote echo service with the limits set in svc and
+// returns the port of the local listener
+func exposeLimitedService(t *testing.T, svc *shipyard.Service) int32 {
+	c, _, _, servers := setupTests(t)
+
+	svc.Name = "Test 1"
+	svc.RemoteConnectorAddr = servers[1].Address
+	svc.DestinationAddr = startEchoServer(t)
+	svc.Type = shipyard.ServiceType_REMOTE
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{Service: svc})
+	require.NoError(t, err)
+
+	return waitForAllocatedPort(t, servers[0].Server, resp.Id)
+}
+
+func dialService(t *testing.T, port int32) net.Conn {
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// requireClosed waits for the connection to be closed by the connector
+func requireClosed(t *testing.T, conn net.Conn, timeout time.Duration) {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	_, err := io.ReadAll(conn)
+	require.NoError(t, err)
+}
+
+func TestConnLimiterRefusesConnectionsOverLimit(t *testing.T) {
+	l := newConnLimiter(1)
+
+	require.True(t, l.tryAcquire())
+	require.False(t, l.tryAcquire())
+
+	l.release()
+	require.True(t, l.tryAcquire())
+}
+
+func TestServiceWithMaxConnectionsRejectsConnections(t *testing.T) {
+	p := exposeLimitedService(t, &shipyard.Service{MaxConnections: 1})
+
+	first := dialService(t, p)
+	requireEcho(t, first)
+
+	requireClosed(t, dialService(t, p), 5*time.Second)
+
+	// the slot is released when the first connection closes
+	first.Close()
+
+	require.Eventually(t, func() bool {
+		conn := dialService(t, p)
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(time.Second))
+		conn.Write([]byte("hello"))
+
+		_, err := io.ReadFull(conn, make([]byte, 5))
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestServiceWithMaxConnectionsQueuesConnections(t *testing.T) {
+	p := exposeLimitedService(t, &shipyard.Service{
+		MaxConnections:        1,
+		ConnectionLimitAction: shipyard.ConnectionLimitAction_QUEUE,
+	})
+
+	first := dialService(t, p)
+	requireEcho(t, first)
+
+	queued := dialService(t, p)
+	_, err := queued.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	// the queued connection is not handled while the first is open
+	queued.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err = queued.Read(make([]byte, 5))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	first.Close()
+
+	queued.SetReadDeadline(time.Now().Add(5 * time.Second))
+	d := make([]byte, 5)
+	_, err = io.ReadFull(queued, d)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(d))
+}
+
+func TestUDPServiceWithQueuedPeersForwardsDatagramsForConnectedPeers(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	p := int32(freePort(t))
+
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                  "Test 1",
+			RemoteConnectorAddr:   servers[1].Address,
+			SourcePort:            p,
+			DestinationAddr:       startUDPEchoServer(t),
+			Type:                  shipyard.ServiceType_REMOTE,
+			Protocol:              shipyard.ServiceProtocol_UDP,
+			MaxConnections:        1,
+			ConnectionLimitAction: shipyard.ConnectionLimitAction_QUEUE,
+		},
+	})
+	require.NoError(t, err)
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("udp4", fmt.Sprintf("localhost:%d", p))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		return conn
+	}
+
+	connected := dial()
+	buf := make([]byte, maxDatagramSize)
+
+	// the listener may not exist yet, keep sending until there is a reply
+	require.Eventually(t, func() bool {
+		connected.Write([]byte("ping"))
+		connected.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+
+		n, err := connected.Read(buf)
+		return err == nil && string(buf[:n]) == "ping"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// drain any duplicate replies from the retries above
+	for {
+		connected.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		if _, err := connected.Read(buf); err != nil {
+			break
+		}
+	}
+
+	// new peers wait for the connected peer to close
+	for i := 0; i < 2; i++ {
+		_, err := dial().Write([]byte("queued"))
+		require.NoError(t, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = connected.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	connected.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := connected.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(buf[:n]))
+}
+
+func TestServiceWithIdleTimeoutClosesIdleConnections(t *testing.T) {
+	p := exposeLimitedService(t, &shipyard.Service{IdleTimeout: int64(300 * time.Millisecond)})
+
+	conn := dialService(t, p)
+	requireEcho(t, conn)
+
+	start := time.Now()
+	requireClosed(t, conn, 5*time.Second)
+	require.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestServiceWithMaxLifetimeClosesActiveConnections(t *testing.T) {
+	p := exposeLimitedService(t, &shipyard.Service{MaxLifetime: int64(500 * time.Millisecond)})
+
+	conn := dialService(t, p)
+	start := time.Now()
+
+	// keep the connection active until it is closed
+	for time.Since(start) < 5*time.Second {
+		conn.SetDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Write([]byte("hello")); err != nil {
+			break
+		}
+
+		if _, err := io.ReadFull(conn, make([]byte, 5)); err != nil {
+			break
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	require.Less(t, time.Since(start), 3*time.Second)
+}
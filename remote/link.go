@@ -0,0 +1,210 @@
+package remote
+
+import (
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// remoteDialTimeout is the maximum time to wait for a connection to a remote server
+var remoteDialTimeout = 10 * time.Second
+
+// linkHealthyAfter is the time a link must stay connected after the handshake before
+// the failed attempts are reset, streams which fail sooner count as a failed attempt
+var linkHealthyAfter = 10 * time.Second
+
+// link holds the state of the connection to a remote server
+type link struct {
+	state       shipyard.LinkState
+	attempts    int // failed connection attempts since the link was last healthy
+	lastError   string
+	nextAttempt time.Time
+	connected   time.Time // when the handshake completed for the current stream
+
+	running bool          // a reconnection is in process
+	pending bool          // another reconnection was requested while one was running
+	nudge   chan struct{} // interrupts the backoff wait
+}
+
+func newLink() link {
+	return link{nudge: make(chan struct{}, 1)}
+}
+
+// beginReconnect marks the start of a reconnection, returns false if a reconnection is
+// already in process. The running reconnection checks the state of the link again before
+// it finishes, when nudge is set it is also interrupted to retry immediately.
+func (si *streamInfo) beginReconnect(nudge bool) bool {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	if si.link.running {
+		si.link.pending = true
+
+		if !nudge {
+			return false
+		}
+
+		select {
+		case si.link.nudge <- struct{}{}:
+		default:
+		}
+
+		return false
+	}
+
+	si.link.running = true
+	si.link.pending = false
+
+	return true
+}
+
+// endReconnect marks the end of a reconnection, returns false if the link was disconnected
+// or a reconnection was requested while it was running and it must run again
+func (si *streamInfo) endReconnect(force bool) bool {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	if !force && (si.link.pending || si.link.state == shipyard.LinkState_DISCONNECTED) {
+		si.link.pending = false
+		return false
+	}
+
+	si.link.running = false
+
+	return true
+}
+
+func (si *streamInfo) linkState() shipyard.LinkState {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	return si.link.state
+}
+
+func (si *streamInfo) setLinkState(state shipyard.LinkState) {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	si.link.state = state
+}
+
+// linkConnected marks the link as connected if the stream g has not already failed,
+// the failed attempts are kept until the link has been healthy for linkHealthyAfter
+func (si *streamInfo) linkConnected(g *grpcConn) {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	for _, p := range si.pool {
+		if p == g {
+			si.link.state = shipyard.LinkState_CONNECTED
+			si.link.nextAttempt = time.Time{}
+			si.link.connected = time.Now()
+
+			return
+		}
+	}
+}
+
+// linkFailed records a failed connection attempt, returns the number of failed attempts
+func (si *streamInfo) linkFailed(err error) int {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	si.link.state = shipyard.LinkState_DISCONNECTED
+	si.link.attempts++
+	si.link.lastError = err.Error()
+
+	return si.link.attempts
+}
+
+// linkDisconnected records that the stream for a connected link has failed
+func (si *streamInfo) linkDisconnected(err error) {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	si.link.state = shipyard.LinkState_DISCONNECTED
+	si.link.lastError = err.Error()
+}
+
+// linkStreamFailed records that the stream opened by the local connector has failed,
+// when the link was healthy the first attempt to reconnect is made immediately. Streams
+// which fail before the link is healthy, such as streams rejected by the remote, are a
+// failed attempt so that the reconnection waits for the backoff.
+func (si *streamInfo) linkStreamFailed(err error) {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	if !si.link.connected.IsZero() && time.Since(si.link.connected) >= linkHealthyAfter {
+		si.link.attempts = 0
+	} else {
+		si.link.attempts++
+	}
+
+	si.link.state = shipyard.LinkState_DISCONNECTED
+	si.link.lastError = err.Error()
+	si.link.connected = time.Time{}
+}
+
+// waitForReconnect waits for the backoff before the next connection attempt, the wait
+// is interrupted when the link is nudged. Returns false if the server is shutting down.
+func (s *Server) waitForReconnect(si *streamInfo) bool {
+	si.updateMutex.Lock()
+	d := s.backoff.duration(si.link.attempts)
+	si.link.nextAttempt = time.Now().Add(d)
+	nudge := si.link.nudge
+	si.updateMutex.Unlock()
+
+	if d == 0 {
+		return s.ctx.Err() == nil
+	}
+
+	s.log.Debug(
+		"local_server",
+		"message", "Waiting before reconnecting",
+		"addr", si.addr,
+		"backoff", d)
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-nudge:
+		s.log.Debug(
+			"local_server",
+			"message", "Reconnecting immediately",
+			"addr", si.addr)
+	case <-s.ctx.Done():
+		return false
+	}
+
+	return true
+}
+
+// linkStatus returns the status of the link including the heartbeat measurements
+func (si *streamInfo) linkStatus() *shipyard.LinkStatus {
+	ls := si.heartbeat.status()
+
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	ls.State = si.link.state
+
+	// the attempts are for the current outage, a connected link which is not yet healthy
+	// keeps them for the backoff
+	if si.link.state != shipyard.LinkState_CONNECTED {
+		ls.Attempts = int32(si.link.attempts)
+	}
+	ls.LastError = si.link.lastError
+
+	if !si.link.nextAttempt.IsZero() && si.link.state == shipyard.LinkState_DISCONNECTED {
+		ls.NextAttempt = si.link.nextAttempt.UnixNano()
+	}
+
+	if l := si.rateLimit.limit(); l != nil {
+		ls.RateLimit = l
+		ls.ThrottleStats = si.rateLimit.status()
+	}
+
+	return ls
+}
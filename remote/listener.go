@@ -2,18 +2,31 @@ package remote
 
 import (
 	"fmt"
+	"math/rand"
 	"net"
+	"net/netip"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jumppad-labs/connector/protos/shipyard"
 )
 
-func (s *Server) createListenerAndListen(serviceID string, port int) (net.Listener, error) {
-	s.log.Info("listener", "message", "Create Listener", "port", port)
+// DefaultBindAddress is the address listeners bind to, all IPv4 interfaces
+const DefaultBindAddress = "0.0.0.0"
 
-	// create the listener
-	l, err := net.Listen("tcp4", fmt.Sprintf(":%d", port))
+// portRange is the range of ports used to allocate listeners for services without a source port
+type portRange struct {
+	min int
+	max int
+}
+
+// createListenerAndListen creates a listener for the service bound to bind, or the server
+// default when bind is empty. When port is 0 a free port is allocated, the allocated port
+// can be found with listenerPort
+func (s *Server) createListenerAndListen(serviceID, bind string, port int, protocol shipyard.ServiceProtocol) (net.Listener, error) {
+	l, err := s.createListener(bind, port, protocol)
 	if err != nil {
-		s.log.Error("listener", "message", "Unable to create TCP listener", "error", err)
 		return nil, err
 	}
 
@@ -21,9 +34,136 @@ func (s *Server) createListenerAndListen(serviceID string, port int) (net.Listen
 	return l, nil
 }
 
+// createListener creates a listener bound to bind, or the server default when bind is empty
+func (s *Server) createListener(bind string, port int, protocol shipyard.ServiceProtocol) (net.Listener, error) {
+	if bind == "" {
+		bind = s.bindAddress
+	}
+
+	s.log.Info("listener", "message", "Create Listener", "bind_address", bind, "port", port, "protocol", protocol)
+
+	var l net.Listener
+	var err error
+
+	if port == 0 && s.portRange.max > 0 && !isUnixSocket(bind) {
+		l, err = s.listenInRange(bind, protocol)
+	} else {
+		l, err = s.listen(bind, port, protocol)
+	}
+
+	if err != nil {
+		s.log.Error("listener", "message", "Unable to create listener", "bind_address", bind, "port", port, "protocol", protocol, "error", err)
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (s *Server) listen(bind string, port int, protocol shipyard.ServiceProtocol) (net.Listener, error) {
+	if path, ok := unixSocketPath(bind); ok {
+		return listenUnix(path)
+	}
+
+	network, addr := listenAddress(protocol, bind, port)
+
+	switch protocol {
+	case shipyard.ServiceProtocol_UDP:
+		pc, err := net.ListenPacket(network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		return newUDPListener(pc, s.udpIdleTimeout), nil
+	default:
+		return net.Listen(network, addr)
+	}
+}
+
+// listenAddress returns the network and address for a listener. IPv4 addresses only
+// listen on IPv4 and IPv6 addresses only on IPv6, the unspecified IPv6 address :: is a
+// dual-stack listener on all IPv4 and IPv6 interfaces.
+func listenAddress(protocol shipyard.ServiceProtocol, bind string, port int) (string, string) {
+	network := strings.ToLower(protocol.String())
+
+	if a, err := netip.ParseAddr(bind); err == nil {
+		switch {
+		case a.Is4():
+			network += "4"
+		case !a.IsUnspecified():
+			network += "6"
+		}
+	}
+
+	return network, net.JoinHostPort(bind, strconv.Itoa(port))
+}
+
+// ValidateBindAddress returns an error when addr is not an IP address or the path of
+// a Unix socket, an empty address is valid and uses the server default
+func ValidateBindAddress(addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	if path, ok := unixSocketPath(addr); ok {
+		if path == "" {
+			return fmt.Errorf("unix socket address must include a path")
+		}
+
+		return nil
+	}
+
+	_, err := netip.ParseAddr(addr)
+	return err
+}
+
+// ValidateDefaultBindAddress returns an error when addr is not an IP address, the default
+// is shared by every service which does not set a bind address so can not be the path of
+// a Unix socket
+func ValidateDefaultBindAddress(addr string) error {
+	if isUnixSocket(addr) {
+		return fmt.Errorf("unix sockets can only be used as the bind address of a service")
+	}
+
+	_, err := netip.ParseAddr(addr)
+	return err
+}
+
+// listenInRange creates a listener on a free port from the configured range, the search
+// starts at a random port so that parallel allocations rarely try the same port
+func (s *Server) listenInRange(bind string, protocol shipyard.ServiceProtocol) (net.Listener, error) {
+	size := s.portRange.max - s.portRange.min + 1
+	start := rand.Intn(size)
+
+	for i := 0; i < size; i++ {
+		port := s.portRange.min + (start+i)%size
+
+		l, err := s.listen(bind, port, protocol)
+		if err == nil {
+			return l, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no free ports in range %d-%d", s.portRange.min, s.portRange.max)
+}
+
+// listenerPort returns the port a listener is bound to
+func listenerPort(l net.Listener) int {
+	_, p, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		return 0
+	}
+
+	port, _ := strconv.Atoi(p)
+	return port
+}
+
 func (s *Server) handleListener(serviceID string, l net.Listener) {
 	// wrap in a go func to immediately return
 	go func(serviceID string, l net.Listener) {
+		// created for the first connection when the service routes HTTP requests
+		var router *httpRouter
+		var proxy *httpProxy
+
 		for {
 			conn, err := l.Accept()
 			if err != nil {
@@ -33,37 +173,145 @@ func (s *Server) handleListener(serviceID string, l net.Listener) {
 
 			s.log.Debug("listener", "message", "Handle new connection", "service_id", serviceID)
 
-			si, ok := s.streams.findByServiceID(serviceID)
+			si, svc, ok := s.listenerService(serviceID)
 			if !ok {
-				// no service exists for this connection, close and return, this should never happen
-				s.log.Error(
-					"listener",
-					"message", "Unable to find bi-directional stream for connection",
-					"service_id", serviceID)
+				conn.Close()
+				continue
+			}
+
+			// requests are routed by the HTTP server, connections are only opened over
+			// the stream for requests which match
+			if svc.detail.Match != nil {
+				if router == nil {
+					router = s.newHTTPRouter(serviceID, l, svc.detail)
+				}
 
+				router.serve(conn)
 				continue
 			}
 
-			// set the new connection
-			svc, ok := si.services.get(serviceID)
-			if !ok {
-				s.log.Error(
-					"listener",
-					"message", "Unable to find service for connection",
-					"service_id", serviceID)
+			// the destination is requested by the client
+			if svc.detail.Proxy == shipyard.ProxyProtocol_HTTP {
+				if proxy == nil {
+					proxy = s.newHTTPProxy(serviceID, l)
+				}
+
+				proxy.serve(conn)
+				continue
+			}
+
+			if svc.detail.Proxy != shipyard.ProxyProtocol_NO_PROXY {
+				go s.acceptProxyConnection(serviceID, si, svc, l, conn)
+				continue
+			}
 
+			// queued connections wait for a slot without blocking the accept loop
+			if svc.queuesConnections() {
+				go s.acceptConnection(serviceID, si, svc, l, conn)
 				continue
 			}
 
-			// generate a unique id for the connection
-			connID := uuid.New().String()
+			s.acceptConnection(serviceID, si, svc, l, conn)
+		}
 
-			c := newBufferedConn(conn)
-			c.id = connID
-			svc.tcpConnections.Store(connID, c)
+		if router != nil {
+			router.close()
+		}
 
-			// read and immediately accept the next connection
-			go s.handleConnectionRead(serviceID, si, svc, c)
+		if proxy != nil {
+			proxy.close()
 		}
 	}(serviceID, l)
 }
+
+// listenerService returns the stream and the service for connections accepted by a listener
+func (s *Server) listenerService(serviceID string) (*streamInfo, *service, bool) {
+	si, ok := s.streams.findByServiceID(serviceID)
+	if !ok {
+		// no service exists for this connection, close and return, this should never happen
+		s.log.Error(
+			"listener",
+			"message", "Unable to find bi-directional stream for connection",
+			"service_id", serviceID)
+
+		return nil, nil, false
+	}
+
+	// set the new connection
+	svc, ok := si.services.get(serviceID)
+	if !ok {
+		s.log.Error(
+			"listener",
+			"message", "Unable to find service for connection",
+			"service_id", serviceID)
+
+		return nil, nil, false
+	}
+
+	return si, svc, true
+}
+
+// acceptConnection sends the data for a connection accepted by the listener l over the
+// stream, returns false when the connection has been closed
+func (s *Server) acceptConnection(serviceID string, si *streamInfo, svc *service, l net.Listener, conn net.Conn) bool {
+	c := s.openConnection(serviceID, si, svc, l, conn)
+	if c == nil {
+		return false
+	}
+
+	s.startConnection(serviceID, si, svc, c)
+	return true
+}
+
+// openConnection adds a connection accepted by the listener l to the service, returns nil
+// when the connection has been closed as it can not be opened over the stream
+func (s *Server) openConnection(serviceID string, si *streamInfo, svc *service, l net.Listener, conn net.Conn) *bufferedConn {
+	// queued connections wait until a slot is available
+	if !s.acquireConnectionSlot(svc, l) {
+		conn.Close()
+		return nil
+	}
+
+	// the remote is shutting down, do not open new connections over the stream
+	if si.linkState() == shipyard.LinkState_DRAINING {
+		s.log.Debug(
+			"listener",
+			"message", "Remote connector is going away, refusing connection",
+			"service_id", serviceID)
+
+		if lim := svc.limiter(); lim != nil {
+			lim.release()
+		}
+
+		conn.Close()
+		return nil
+	}
+
+	// generate a unique id for the connection
+	connID := uuid.New().String()
+
+	c := s.newServiceConn(svc, conn)
+	c.id = connID
+	c.client = newClient(conn)
+
+	if lim := svc.limiter(); lim != nil {
+		c.onClose = lim.release
+	}
+
+	// proxy connections wait for the remote to dial the destination requested by the client
+	if svc.detail.Proxy != shipyard.ProxyProtocol_NO_PROXY {
+		c.dialed = make(chan error, 1)
+	}
+
+	svc.tcpConnections.Store(connID, c)
+
+	return c
+}
+
+// startConnection starts sending the data for the connection over the stream
+func (s *Server) startConnection(serviceID string, si *streamInfo, svc *service, c *bufferedConn) {
+	// read and immediately accept the next connection
+	go s.handleConnectionRead(serviceID, si, svc, c)
+	go s.handleConnectionWrite(serviceID, si, svc, c)
+	s.watchConnection(svc, c)
+}
@@ -0,0 +1,343 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForAllocatedPort waits for the service to be complete and returns the allocated port
+func waitForAllocatedPort(t *testing.T, s *Server, id string) int32 {
+	var port int32
+
+	require.Eventually(t, func() bool {
+		si, ok := s.streams.findByServiceID(id)
+		if !ok {
+			return false
+		}
+
+		svc, ok := si.services.get(id)
+		if !ok {
+			return false
+		}
+
+		port = svc.sourcePort()
+		return svc.status() == shipyard.ServiceStatus_COMPLETE && port > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	return port
+}
+
+func TestCreateListenerWithoutPortAllocatesPort(t *testing.T) {
+	s := New(hclog.NewNullLogger(), nil, nil, nil)
+
+	l, err := s.createListenerAndListen("test", "", 0, shipyard.ServiceProtocol_TCP)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.Greater(t, listenerPort(l), 0)
+}
+
+func TestCreateListenerWithoutPortAllocatesPortFromRange(t *testing.T) {
+	min := rand.Intn(1000) + 49000
+	s := New(hclog.NewNullLogger(), nil, nil, nil, WithPortRange(min, min+10))
+
+	for _, p := range []shipyard.ServiceProtocol{shipyard.ServiceProtocol_TCP, shipyard.ServiceProtocol_UDP} {
+		l, err := s.createListenerAndListen("test", "", 0, p)
+		require.NoError(t, err)
+		defer l.Close()
+
+		require.GreaterOrEqual(t, listenerPort(l), min)
+		require.LessOrEqual(t, listenerPort(l), min+10)
+	}
+}
+
+func TestCreateListenerReturnsErrorWhenRangeExhausted(t *testing.T) {
+	used, err := net.Listen("tcp4", ":0")
+	require.NoError(t, err)
+	defer used.Close()
+
+	p := listenerPort(used)
+	s := New(hclog.NewNullLogger(), nil, nil, nil, WithPortRange(p, p))
+
+	_, err = s.createListenerAndListen("test", "", 0, shipyard.ServiceProtocol_TCP)
+	require.Error(t, err)
+}
+
+func TestExposeLocalServiceWithoutPortAllocatesRemotePort(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	ids := []string{}
+	for i := 0; i < 2; i++ {
+		resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+			Service: &shipyard.Service{
+				Name:                fmt.Sprintf("Test %d", i),
+				RemoteConnectorAddr: servers[1].Address,
+				DestinationAddr:     "localhost:19001",
+				Type:                shipyard.ServiceType_LOCAL,
+			},
+		})
+		require.NoError(t, err)
+
+		ids = append(ids, resp.Id)
+	}
+
+	p1 := waitForAllocatedPort(t, servers[0].Server, ids[0])
+	p2 := waitForAllocatedPort(t, servers[0].Server, ids[1])
+	require.NotEqual(t, p1, p2)
+
+	// the listeners are on the remote connector
+	for _, p := range []int32{p1, p2} {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+		require.NoError(t, err)
+		conn.Close()
+	}
+
+	servers[1].Integration.AssertCalled(t, "Register", ids[0], "test-0", int(p1), int(p1))
+
+	// the allocated port is returned by the API
+	resp, err := c.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+
+	for _, svc := range resp.Services {
+		require.Contains(t, []int32{p1, p2}, svc.SourcePort)
+	}
+}
+
+func TestExposeRemoteServiceWithoutPortAllocatesLocalPort(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "localhost:19001",
+			Type:                shipyard.ServiceType_REMOTE,
+		},
+	})
+	require.NoError(t, err)
+
+	p := waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+	require.NoError(t, err)
+	conn.Close()
+
+	// the allocated port is now in use by the service
+	_, err = c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 2",
+			RemoteConnectorAddr: servers[1].Address,
+			SourcePort:          p,
+			DestinationAddr:     "localhost:19001",
+			Type:                shipyard.ServiceType_REMOTE,
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestServiceWithoutPortErrorsWhenRemoteDoesNotSupportDynamicPorts(t *testing.T) {
+	_, addr := startFakeRemote(t, &shipyard.Handshake{Version: ProtocolVersion, MinVersion: MinProtocolVersion})
+	s, _, _ := createServer(t, freeAddress(t), "server_local_1")
+
+	resp, err := s.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: addr,
+			DestinationAddr:     "localhost:8080",
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		si, ok := s.streams.findByServiceID(resp.Id)
+		if !ok {
+			return false
+		}
+
+		svc, _ := si.services.get(resp.Id)
+		return svc.status() == shipyard.ServiceStatus_ERROR
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func skipWithoutIPv6(t *testing.T) {
+	l, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 is not available")
+	}
+
+	l.Close()
+}
+
+func listenerHost(t *testing.T, l net.Listener) string {
+	h, _, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+
+	return h
+}
+
+func TestListenAddressSelectsNetworkForBindAddress(t *testing.T) {
+	tests := []struct {
+		bind    string
+		network string
+		addr    string
+	}{
+		{"0.0.0.0", "tcp4", "0.0.0.0:80"},
+		{"127.0.0.1", "tcp4", "127.0.0.1:80"},
+		{"::1", "tcp6", "[::1]:80"},
+		{"fe80::1%eth0", "tcp6", "[fe80::1%eth0]:80"},
+		{"::", "tcp", "[::]:80"},
+	}
+
+	for _, tc := range tests {
+		network, addr := listenAddress(shipyard.ServiceProtocol_TCP, tc.bind, 80)
+		require.Equal(t, tc.network, network, tc.bind)
+		require.Equal(t, tc.addr, addr, tc.bind)
+	}
+
+	network, _ := listenAddress(shipyard.ServiceProtocol_UDP, "::1", 80)
+	require.Equal(t, "udp6", network)
+}
+
+func TestCreateListenerBindsToServiceAddress(t *testing.T) {
+	s := New(hclog.NewNullLogger(), nil, nil, nil)
+
+	for _, p := range []shipyard.ServiceProtocol{shipyard.ServiceProtocol_TCP, shipyard.ServiceProtocol_UDP} {
+		l, err := s.createListenerAndListen("test", "127.0.0.1", 0, p)
+		require.NoError(t, err)
+		defer l.Close()
+
+		require.Equal(t, "127.0.0.1", listenerHost(t, l))
+	}
+}
+
+func TestCreateListenerBindsToServerAddress(t *testing.T) {
+	s := New(hclog.NewNullLogger(), nil, nil, nil, WithBindAddress("127.0.0.1"))
+
+	l, err := s.createListenerAndListen("test", "", 0, shipyard.ServiceProtocol_TCP)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.Equal(t, "127.0.0.1", listenerHost(t, l))
+}
+
+func TestWithBindAddressIgnoresUnixSocket(t *testing.T) {
+	require.Error(t, ValidateDefaultBindAddress("unix:///tmp/connector.sock"))
+	require.NoError(t, ValidateDefaultBindAddress("::"))
+
+	s := New(hclog.NewNullLogger(), nil, nil, nil, WithBindAddress("unix:///tmp/connector.sock"))
+	require.Equal(t, DefaultBindAddress, s.bindAddress)
+}
+
+func TestCreateListenerBindsToIPv6Address(t *testing.T) {
+	skipWithoutIPv6(t)
+
+	s := New(hclog.NewNullLogger(), nil, nil, nil)
+
+	l, err := s.createListenerAndListen("test", "::1", 0, shipyard.ServiceProtocol_TCP)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.Equal(t, "::1", listenerHost(t, l))
+
+	// IPv4 clients can not connect
+	_, err = net.Dial("tcp4", fmt.Sprintf("127.0.0.1:%d", listenerPort(l)))
+	require.Error(t, err)
+}
+
+func TestCreateListenerWithUnspecifiedIPv6AddressIsDualStack(t *testing.T) {
+	skipWithoutIPv6(t)
+
+	s := New(hclog.NewNullLogger(), nil, nil, nil)
+
+	l, err := s.createListenerAndListen("test", "::", 0, shipyard.ServiceProtocol_TCP)
+	require.NoError(t, err)
+	defer l.Close()
+
+	for _, addr := range []string{"127.0.0.1", "::1"} {
+		conn, err := net.Dial("tcp", net.JoinHostPort(addr, fmt.Sprint(listenerPort(l))))
+		require.NoError(t, err)
+		conn.Close()
+	}
+}
+
+func TestExposeServiceWithInvalidAddressesReturnsError(t *testing.T) {
+	s, _, _ := createServer(t, freeAddress(t), "server_local_1")
+
+	_, err := s.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: "localhost:9090",
+			DestinationAddr:     "localhost:8080",
+			BindAddress:         "localhost",
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.Error(t, err)
+
+	// IPv6 destinations must be enclosed in brackets
+	_, err = s.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: "localhost:9090",
+			DestinationAddr:     "::1:8080",
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestExposeLocalServiceWithBindAddressToIPv6Destination(t *testing.T) {
+	skipWithoutIPv6(t)
+
+	c, _, _, servers := setupTests(t)
+
+	dest, err := net.Listen("tcp6", "[::1]:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { dest.Close() })
+
+	go func() {
+		for {
+			conn, err := dest.Accept()
+			if err != nil {
+				return
+			}
+
+			go io.Copy(conn, conn)
+		}
+	}()
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     dest.Addr().String(),
+			BindAddress:         "127.0.0.1",
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.NoError(t, err)
+
+	p := waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", p))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	d := make([]byte, 5)
+	_, err = io.ReadFull(conn, d)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(d))
+}
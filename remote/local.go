@@ -4,9 +4,6 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
-	"io"
-	"net"
-	"time"
 
 	"github.com/jumppad-labs/connector/protos/shipyard"
 	"google.golang.org/grpc"
@@ -45,7 +42,19 @@ func (s *Server) getClient(addr string) (shipyard.RemoteConnectionClient, error)
 		"message", "Creating Insecure client",
 		"addr", addr)
 
-	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithDefaultCallOptions())
+	// fail the dial rather than retrying forever, reconnection attempts are made
+	// with the backoff in handleReconnection
+	ctx, cancel := context.WithTimeout(s.ctx, remoteDialTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(
+		ctx,
+		addr,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithDefaultCallOptions(),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -53,10 +62,128 @@ func (s *Server) getClient(addr string) (shipyard.RemoteConnectionClient, error)
 	return shipyard.NewRemoteConnectionClient(conn), nil
 }
 
-func (s *Server) handleReconnection(conn *streamInfo) error {
+// serviceRequirement is a feature used by a service which the remote connector must support
+type serviceRequirement struct {
+	requires   func(detail *shipyard.Service) bool
+	capability string
+	feature    string
+}
+
+// serviceRequirements are checked before a service is configured on a remote connector,
+// services which need a capability the remote does not support are set to Error
+var serviceRequirements = []serviceRequirement{
+	{
+		requires: func(d *shipyard.Service) bool {
+			return d.Protocol == shipyard.ServiceProtocol_UDP
+		},
+		capability: CapabilityUDP,
+		feature:    "UDP services",
+	},
+	{
+		// remote connectors which do not support dynamic ports would listen on a random port
+		// without reporting it
+		requires: func(d *shipyard.Service) bool {
+			return d.Type == shipyard.ServiceType_LOCAL && d.SourcePort == 0 && !isUnixSocket(d.BindAddress)
+		},
+		capability: CapabilityDynamicPort,
+		feature:    "allocating a source port",
+	},
+	{
+		// remote connectors which do not support bind addresses would listen on all interfaces
+		requires: func(d *shipyard.Service) bool {
+			return d.Type == shipyard.ServiceType_LOCAL && d.BindAddress != ""
+		},
+		capability: CapabilityBindAddress,
+		feature:    "bind addresses",
+	},
+	{
+		// the remote listens on or dials the Unix socket
+		requires: func(d *shipyard.Service) bool {
+			return (d.Type == shipyard.ServiceType_LOCAL && isUnixSocket(d.BindAddress)) ||
+				(d.Type == shipyard.ServiceType_REMOTE && hasUnixDestination(d))
+		},
+		capability: CapabilityUnixSocket,
+		feature:    "Unix sockets",
+	},
+	{
+		// the remote dials the destinations for remote services
+		requires: func(d *shipyard.Service) bool {
+			return d.Type == shipyard.ServiceType_REMOTE &&
+				(len(d.DestinationAddrs) > 0 || d.HealthCheck != nil || d.LoadBalancing != shipyard.LoadBalancing_ROUND_ROBIN)
+		},
+		capability: CapabilityLoadBalance,
+		feature:    "load balancing",
+	},
+	{
+		requires: func(d *shipyard.Service) bool {
+			return d.Type == shipyard.ServiceType_REMOTE && (d.DialTimeout > 0 || d.DialRetries > 0)
+		},
+		capability: CapabilityDialOptions,
+		feature:    "dial timeouts and retries",
+	},
+	{
+		// the remote accepts the connections for local services, idle timeouts and
+		// lifetimes are also enforced by this connector
+		requires: func(d *shipyard.Service) bool {
+			return d.Type == shipyard.ServiceType_LOCAL && d.MaxConnections > 0
+		},
+		capability: CapabilityConnLimits,
+		feature:    "limiting connections",
+	},
+	{
+		// the remote dials the destinations requested by clients of proxy services
+		requires: func(d *shipyard.Service) bool {
+			return d.Proxy != shipyard.ProxyProtocol_NO_PROXY
+		},
+		capability: CapabilityProxy,
+		feature:    "proxy services",
+	},
+	{
+		// the remote sends the client addresses for local services and sends the header
+		// for remote services
+		requires: func(d *shipyard.Service) bool {
+			return d.ProxyHeader != shipyard.ProxyHeader_NO_PROXY_HEADER
+		},
+		capability: CapabilityProxyHeader,
+		feature:    "PROXY protocol headers",
+	},
+	{
+		// the remote shares the listener for local services with virtual hosts
+		requires: func(d *shipyard.Service) bool {
+			return d.Type == shipyard.ServiceType_LOCAL && len(d.Hosts) > 0
+		},
+		capability: CapabilityVirtualHosts,
+		feature:    "virtual hosts",
+	},
+	{
+		// the remote routes the requests received by the listener for local services
+		requires: func(d *shipyard.Service) bool {
+			return d.Type == shipyard.ServiceType_LOCAL && d.Match != nil
+		},
+		capability: CapabilityHTTPRoutes,
+		feature:    "routing HTTP requests",
+	},
+}
+
+// unsupportedRequirement returns the first requirement of the service which the remote
+// connector does not support
+func unsupportedRequirement(si *streamInfo, detail *shipyard.Service) (serviceRequirement, bool) {
+	for _, r := range serviceRequirements {
+		if r.requires(detail) && !si.supports(r.capability) {
+			return r, true
+		}
+	}
+
+	return serviceRequirement{}, false
+}
+
+// handleReconnection connects the link and exposes the services, when nudge is set a
+// reconnection waiting for the backoff retries immediately. Failed streams do not nudge
+// the reconnection so that streams which are rejected by the remote wait for the backoff.
+func (s *Server) handleReconnection(conn *streamInfo, nudge bool) error {
 	// if is possible that this method gets called multiple times
 	// ensure there is only one operation in process at once
-	if conn.isConnecting() {
+	if !conn.beginReconnect(nudge) {
 		s.log.Info(
 			"local_server",
 			"message", "Connection attempt already in process",
@@ -65,72 +192,96 @@ func (s *Server) handleReconnection(conn *streamInfo) error {
 		return nil
 	}
 
-	// mark we are attempting to connect
-	conn.setConnecting(true)
-	defer func() {
-		conn.setConnecting(false)
-	}()
-
 	for s.ctx.Err() == nil {
-		closed := true
-		if conn.grpcConn != nil && !conn.grpcConn.Closed {
-			closed = false
-		}
+		state := conn.linkState()
 
 		s.log.Trace(
 			"local_server",
 			"message", "Reconnecting, current connection state",
-			"grpcConn_nil", conn.grpcConn == nil,
-			"grpcConn_closed", closed,
+			"state", state,
 		)
 
 		// if we do not have a connection create one
-		if closed {
+		if state == shipyard.LinkState_DISCONNECTED {
+			// back off after failed attempts
+			if !s.waitForReconnect(conn) {
+				break
+			}
+
 			// connect to the service
 			s.log.Info(
 				"local_server",
 				"message", "Connecting to remote server",
 				"addr", conn.addr)
 
+			conn.setLinkState(shipyard.LinkState_CONNECTING)
+
 			gc, err := s.openRemoteConnection(conn.addr)
 			if err != nil {
+				attempts := conn.linkFailed(err)
+
 				s.log.Error(
 					"local_server",
 					"message",
-					"Unable to open remote connection", "error", err)
+					"Unable to open remote connection", "attempts", attempts, "error", err)
 
-				// back off and try again
-				time.Sleep(connectionBackoff)
 				continue
 			}
 
 			// set the connection
 			conn.setGRPCConn(gc)
+			handshake := conn.startHandshake()
+			conn.heartbeat.reset()
 
-			// send a ping message
+			// handle messages for this stream
+			s.handleRemoteConnection(conn, gc)
+
+			// send the handshake followed by a ping message, version 1 connectors
+			// ignore the handshake and only receive the ping
 			s.log.Debug(
 				"local_server",
 				"message", "Remote connetion estabilished, ping connection",
 				"addr", conn.addr)
 
-			conn.grpcConn.Send(&shipyard.OpenData{Message: &shipyard.OpenData_Ping{Ping: &shipyard.NullMessage{}}})
+			conn.grpcConn.Send(s.handshakeMessage())
+			conn.grpcConn.Send(&shipyard.OpenData{Message: &shipyard.OpenData_Ping{Ping: &shipyard.Ping{}}})
 
-			// handle messages for this stream
-			s.handleRemoteConnection(conn)
+			s.waitForHandshake(conn, handshake)
+
+			// send the session so that the remote can resume any interrupted connections,
+			// the session is also used to add additional streams to the pool
+			if conn.supports(CapabilitySessions) && (s.resumeGracePeriod > 0 || s.linkStreams > 1) {
+				conn.grpcConn.Send(conn.sessionMessage(false))
+			}
+
+			conn.linkConnected(gc)
 		}
 
 		// loop all services and try to reconfigure
 		conn.services.iterate(func(id string, svc *service) bool {
 			// do not attempt when status is Error
-			if svc.detail.Status == shipyard.ServiceStatus_ERROR {
+			if svc.status() == shipyard.ServiceStatus_ERROR {
+				return true
+			}
+
+			if r, ok := unsupportedRequirement(conn, svc.details()); ok {
+				s.log.Error(
+					"local_server",
+					"message", "Remote connector does not support "+r.feature,
+					"service_id", id,
+					"addr", conn.addr,
+					"version", conn.peerVersion(),
+					"capability", r.capability)
+
+				svc.setStatus(shipyard.ServiceStatus_ERROR)
 				return true
 			}
 
 			// set up all the local listeners if the type is remote and the listener does not already
 			// exist
-			if svc.detail.Type == shipyard.ServiceType_REMOTE && svc.tcpListener == nil {
+			if svc.detail.Type == shipyard.ServiceType_REMOTE && svc.listener() == nil {
 				// open the listener locally
-				l, err := s.createListenerAndListen(id, int(svc.detail.SourcePort))
+				l, err := s.createServiceListener(id, svc.detail, int(svc.sourcePort()))
 				if err != nil {
 					s.log.Error(
 						"local_server",
@@ -140,8 +291,19 @@ func (s *Server) handleReconnection(conn *streamInfo) error {
 					return true
 				}
 
+				// keep the allocated port so the service is available on the same port after a reconnect
+				if svc.sourcePort() == 0 && !isUnixSocket(svc.detail.BindAddress) {
+					s.streams.setServicePort(id, svc, int32(listenerPort(l)))
+
+					s.log.Info(
+						"local_server",
+						"message", "Allocated source port for service",
+						"service_id", id,
+						"port", svc.sourcePort())
+				}
+
 				// create the integration such as a kubernetes service
-				err = s.createIntegration(id, svc.detail.Name, int(svc.detail.SourcePort))
+				err = s.createIntegration(id, svc.detail.Name, int(svc.sourcePort()))
 				if err != nil {
 					s.log.Error(
 						"local_server",
@@ -152,7 +314,7 @@ func (s *Server) handleReconnection(conn *streamInfo) error {
 				}
 
 				// add the listener to the service
-				svc.tcpListener = l
+				svc.setListener(l)
 			}
 
 			// send the expose message to the remote so it can open
@@ -162,16 +324,22 @@ func (s *Server) handleReconnection(conn *streamInfo) error {
 				"addr", svc.detail.RemoteConnectorAddr)
 
 			req := &shipyard.OpenData{ServiceId: id}
-			req.Message = &shipyard.OpenData_Expose{Expose: &shipyard.ExposeRequest{Service: svc.detail}}
+			req.Message = &shipyard.OpenData_Expose{Expose: &shipyard.ExposeRequest{Service: svc.details()}}
 
 			conn.grpcConn.Send(req)
 
 			return true
 		})
 
-		return nil
+		// the stream may have failed or new services may have been added while
+		// the services were being exposed
+		if conn.endReconnect(false) {
+			return nil
+		}
 	}
 
+	conn.endReconnect(true)
+
 	s.log.Debug(
 		"local_server",
 		"message", "Context cancelled while waiting to reconnect",
@@ -198,8 +366,12 @@ func (s *Server) openRemoteConnection(addr string) (*grpcConn, error) {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	rc, err := c.OpenStream(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rc, err := c.OpenStream(ctx)
 	if err != nil {
+		cancel()
+
 		s.log.Error(
 			"local_server",
 			"message", "Unable to establish remote connection",
@@ -208,74 +380,145 @@ func (s *Server) openRemoteConnection(addr string) (*grpcConn, error) {
 		return nil, fmt.Errorf("Unable to open remote connection to server %s: %s", addr, err)
 	}
 
-	return newGRPCConn(rc), nil
+	// cancel the stream when the connection is closed, this releases any blocked
+	// sends when the remote is no longer responding
+	gc := newGRPCConn(rc)
+	go func() {
+		<-gc.Done()
+		cancel()
+	}()
+
+	return gc, nil
+}
+
+// openStreamPool opens the additional streams used to carry connection data for the
+// session, additional streams are added to the pool once the remote has accepted the
+// session on the primary stream gc
+func (s *Server) openStreamPool(si *streamInfo, gc *grpcConn) {
+	for i := 1; i < s.linkStreams; i++ {
+		// the primary stream failed while the pool was being opened
+		if !si.inPool(gc) {
+			return
+		}
+
+		s.log.Debug(
+			"local_server",
+			"message", "Opening additional stream",
+			"addr", si.addr,
+			"session_id", si.session,
+			"stream", i)
+
+		sc, err := s.openRemoteConnection(si.addr)
+		if err != nil {
+			s.log.Error(
+				"local_server",
+				"message", "Unable to open additional stream, continuing with a smaller pool",
+				"addr", si.addr, "stream", i, "error", err)
+
+			return
+		}
+
+		s.handleRemoteConnection(si, sc)
+
+		sc.Send(&shipyard.OpenData{
+			Message: &shipyard.OpenData_Session{Session: &shipyard.Session{Id: si.session, Stream: int32(i)}},
+		})
+
+		si.addStream(sc)
+	}
 }
 
-func (s *Server) handleRemoteConnection(si *streamInfo) {
+func (s *Server) handleRemoteConnection(si *streamInfo, gc *grpcConn) {
 	// wrap in a go func to immediately return
 	go func(si *streamInfo) {
 		newMessage := make(chan *shipyard.OpenData)
-		newError := make(chan error)
+		newError := make(chan error, 1)
 
-		go func(si *streamInfo) {
+		go func() {
 			for {
-				msg, err := si.grpcConn.Recv()
+				msg, err := gc.Recv()
 				if err != nil {
 					newError <- err
 					return
 				} else if msg != nil {
-					newMessage <- msg
+					select {
+					case newMessage <- msg:
+					case <-gc.Done():
+						return
+					}
 				} else {
 					return
 				}
 			}
-		}(si)
+		}()
+
+		// heartbeats are only sent on the primary stream, the streams in a pool fail together
+		heartbeats, stop := s.heartbeatTicker()
+		defer stop()
+
+		if si.getGRPCConn() != gc {
+			heartbeats = nil
+		}
 
 		for {
 			s.log.Trace(
 				"local_server",
 				"message", "Waiting for remote client message")
 
+			var err error
+
 			select {
 			case msg := <-newMessage:
-				s.handleRemoteMessage(si, msg)
-			case err := <-newError:
+				s.handleRemoteMessage(si, gc, msg)
+			case <-heartbeats:
+				err = s.sendHeartbeat(si, gc)
+			case err = <-newError:
+			case <-gc.Done():
+				s.log.Debug(
+					"local_server",
+					"message", "Connection context cancelled",
+					"addr", si.addr)
+				return
+			}
+
+			if err != nil {
 				s.log.Error(
 					"local_server",
 					"message", "Error receiving message from remote connection",
 					"addr", si.addr, "error", err)
 
 				// if the connection has not been closed reconnect
-				// and if the server is not shutting down
-				if !si.grpcConn.Closed && !s.Closed() {
+				// and if the server is not shutting down, all the streams in the
+				// pool are closed when any of them fails
+				if si.closePool(gc) && !s.Closed() {
+					si.linkStreamFailed(err)
+
 					s.log.Debug(
 						"local_server",
 						"message", "Connection closed, attempt reconection",
 						"addr", si.addr)
 
-					// mark the internal structure as closed
-					si.grpcConn.Closed = true
-
 					// We need to tear down any listeners related to this request and clean up resources
-					// the downstream should attempt to re-establish the connection and resend the expose requests
-					s.teardownConnection(si)
-					s.handleReconnection(si)
+					// the downstream should attempt to re-establish the connection and resend the expose requests.
+					// When sessions are enabled the connections are kept open until the grace period expires
+					// giving the stream chance to resume
+					if s.resumeGracePeriod > 0 && si.supports(CapabilitySessions) {
+						s.suspendStream(si, func() { s.teardownConnection(si) })
+					} else {
+						s.teardownConnection(si)
+					}
+
+					s.handleReconnection(si, false)
 				}
 
 				return // exit this loop as handleReconnection will recall ths function when a connection is established
-			case <-si.grpcConn.Done():
-				s.log.Debug(
-					"local_server",
-					"message", "Connection context cancelled",
-					"addr", si.addr)
-				return
 			}
 
 		}
 	}(si)
 }
 
-func (s *Server) handleRemoteMessage(si *streamInfo, msg *shipyard.OpenData) {
+func (s *Server) handleRemoteMessage(si *streamInfo, gc *grpcConn, msg *shipyard.OpenData) {
 	s.log.Debug(
 		"local_server",
 		"message", "Received message",
@@ -283,162 +526,58 @@ func (s *Server) handleRemoteMessage(si *streamInfo, msg *shipyard.OpenData) {
 		"connectionID", msg.ConnectionId)
 
 	switch m := msg.Message.(type) {
-	case *shipyard.OpenData_Data:
-		s.log.Trace(
-			"local_server",
-			"message", "Received data from remote",
-			"message_id", m.Data.Id,
-			"service_id", msg.ServiceId,
-			"msg", msg)
+	case *shipyard.OpenData_Data, *shipyard.OpenData_Closed, *shipyard.OpenData_WriteDone,
+		*shipyard.OpenData_ReadDone, *shipyard.OpenData_WindowUpdate, *shipyard.OpenData_Error:
+		s.handleConnectionMessage(si, msg)
 
-		// if we get data find the connection for the message
-		// if we do not have a connection create one
-		svc, _ := si.services.get(msg.ServiceId)
-		c, ok := svc.getTCPConnection(msg.ConnectionId)
-		if !ok {
-			// is this a message for an upstream and if there is no connection
-			// assume the upstream has disconnected and ignore the message
-			if svc.detail.Type == shipyard.ServiceType_REMOTE {
-				s.log.Error(
-					"local_server",
-					"message", "No connection for data, ignore message",
-					"port", svc.detail.SourcePort,
-					"service_id", msg.ServiceId,
-					"connection_id", msg.ConnectionId)
+	case *shipyard.OpenData_Connected:
+		s.handleConnectedMessage(si, msg)
 
-				return
-			}
+	case *shipyard.OpenData_Session:
+		s.handleSessionReply(si, m)
 
-			s.log.Trace(
-				"local_server",
-				"message", "Create new upstream connection for data",
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId,
-				"addr", svc.detail.DestinationAddr)
+		if s.linkStreams > 1 && si.supports(CapabilityStreamPool) {
+			go s.openStreamPool(si, si.getGRPCConn())
+		}
 
-			// otherwise create a new upstream connection
-			var err error
+	case *shipyard.OpenData_Handshake:
+		s.handleHandshakeReply(si, m)
 
-			newCon, err := net.Dial("tcp", svc.detail.DestinationAddr)
-			if err != nil {
-				s.log.Error(
-					"local_server",
-					"message", "Unable to create connection to upstream",
-					"service_id", msg.ServiceId,
-					"connection_id", msg.ConnectionId,
-					"addr", svc.detail.DestinationAddr)
-
-				si.grpcConn.Send(
-					&shipyard.OpenData{
-						ServiceId:    msg.ServiceId,
-						ConnectionId: msg.ConnectionId,
-						Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
-					},
-				)
-				return
-			}
+	case *shipyard.OpenData_Ping:
+		s.handlePingMessage(gc, m)
 
-			// set the connection
-			c = newBufferedConn(newCon)
-			c.id = msg.ConnectionId
-			svc.setTCPConnection(msg.ConnectionId, c)
+	case *shipyard.OpenData_Pong:
+		s.handlePongMessage(si, m)
 
-			// start read handler and don't block
-			go s.handleConnectionRead(msg.ServiceId, si, svc, c)
-		}
+	case *shipyard.OpenData_GoingAway:
+		s.handleGoingAwayMessage(si, m)
 
+	case *shipyard.OpenData_StatusUpdate:
 		s.log.Trace(
 			"local_server",
-			"message", "Writing data to local connection",
+			"message", "Received status message",
 			"service_id", msg.ServiceId,
-			"connection_id", msg.ConnectionId)
+			"status", m.StatusUpdate.Status)
 
-		i, err := c.Write(m.Data.Data)
-		if err != nil {
-			if err == io.EOF {
-				s.log.Debug(
-					"local_server",
-					"message", "Connection closed",
-					"service_id", msg.ServiceId,
-					"connection_id", msg.ConnectionId)
-			} else {
-				s.log.Error(
-					"local_server",
-					"message", "Error writing to connection",
-					"service_id", msg.ServiceId,
-					"connection_id", msg.ConnectionId,
-					"error", err,
-				)
+		// status updates without a service apply to the whole link
+		if msg.ServiceId == "" {
+			if m.StatusUpdate.Status == shipyard.ServiceStatus_ERROR {
+				s.setLinkError(si, m.StatusUpdate.Message)
 			}
 
-			// send closed message
-			si.grpcConn.Send(
-				&shipyard.OpenData{
-					ServiceId:    msg.ServiceId,
-					ConnectionId: msg.ConnectionId,
-					Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
-				},
-			)
-		}
-
-		s.log.Trace(
-			"local_server",
-			"message", "Data written to local connection",
-			"service_id", msg.ServiceId,
-			"connection_id", msg.ConnectionId)
-
-		// if the size of the data is less than the max buffer
-		// all writing has been completed for the connection switch to read mode
-		if i < MessageSize {
-			s.log.Trace(
-				"local_server",
-				"message", "All data written to local connection, start read",
-				"data_written", i,
-				"message_size", MessageSize,
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId)
-
+			return
 		}
 
-	case *shipyard.OpenData_Closed:
-		s.log.Trace(
-			"local_server",
-			"message", "Received close connection message",
-			"service_id", msg.ServiceId,
-			"connection_id", msg.ConnectionId)
-
-		svc, _ := si.services.get(msg.ServiceId)
-		if svc == nil {
-			s.log.Error(
-				"local_server",
-				"message", "Service does not exist",
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId)
-
+		svc, ok := si.services.get(msg.ServiceId)
+		if !ok {
 			return
 		}
 
-		c, ok := svc.getTCPConnection(msg.ConnectionId)
-		if ok {
-			s.log.Trace(
-				"local_server",
-				"message", "Closing connection",
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId)
+		svc.setStatus(m.StatusUpdate.Status)
 
-			// we have a connection close it
-			c.Close()
-			svc.removeTCPConnection(msg.ConnectionId)
+		// the remote allocated the port for the listener
+		if m.StatusUpdate.SourcePort > 0 && svc.sourcePort() != m.StatusUpdate.SourcePort {
+			s.streams.setServicePort(msg.ServiceId, svc, m.StatusUpdate.SourcePort)
 		}
-
-	case *shipyard.OpenData_StatusUpdate:
-		s.log.Trace(
-			"local_server",
-			"message", "Received status message",
-			"service_id", msg.ServiceId,
-			"status", m.StatusUpdate.Status)
-
-		svc, _ := si.services.get(msg.ServiceId)
-		svc.detail.Status = m.StatusUpdate.Status
 	}
 }
@@ -0,0 +1,170 @@
+package remote
+
+import (
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// DefaultResumeGracePeriod is the time an interrupted stream is held open
+// waiting for the remote to reconnect before its connections are closed
+const DefaultResumeGracePeriod = 30 * time.Second
+
+// Option configures optional behaviour of the Server
+type Option func(s *Server)
+
+// WithResumeGracePeriod sets the time an interrupted stream will wait to be resumed
+// before the connections which use it are closed, a value of 0 disables resuming
+// streams
+func WithResumeGracePeriod(d time.Duration) Option {
+	return func(s *Server) {
+		s.resumeGracePeriod = d
+	}
+}
+
+// WithStreams sets the number of parallel gRPC streams a link to a remote server is
+// sharded across, connections are distributed across the streams so that a large
+// transfer does not delay other connections which share the link
+func WithStreams(n int) Option {
+	return func(s *Server) {
+		if n < 1 {
+			n = 1
+		}
+
+		s.linkStreams = n
+	}
+}
+
+// WithUDPIdleTimeout sets the time a UDP peer can be inactive before the
+// connection for the peer is closed
+func WithUDPIdleTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.udpIdleTimeout = d
+	}
+}
+
+// WithHeartbeat sets the interval between heartbeats sent to the remote and the number
+// of consecutive heartbeats which can be missed before the stream is considered dead,
+// an interval of 0 disables heartbeats
+func WithHeartbeat(interval time.Duration, misses int) Option {
+	return func(s *Server) {
+		if misses < 1 {
+			misses = 1
+		}
+
+		s.heartbeatInterval = interval
+		s.heartbeatMisses = misses
+	}
+}
+
+// WithBackoff sets the backoff between attempts to connect to a remote server, the wait
+// starts at initial and is increased by multiplier after each failed attempt up to max.
+// A random jitter of +/- jitter * wait is added to each wait.
+func WithBackoff(initial, max time.Duration, multiplier, jitter float64) Option {
+	return func(s *Server) {
+		if multiplier < 1 {
+			multiplier = 1
+		}
+
+		s.backoff = backoff{initial: initial, max: max, multiplier: multiplier, jitter: jitter}
+	}
+}
+
+// WithMessageSize sets the maximum data payload read from a connection and sent as a single
+// message, larger messages reduce the overhead for bulk transfers. The size can be overridden
+// for a service.
+func WithMessageSize(n int) Option {
+	return func(s *Server) {
+		if n <= 0 {
+			n = MessageSize
+		}
+
+		if n > MaxMessageSize {
+			n = MaxMessageSize
+		}
+
+		s.messageSize = n
+	}
+}
+
+// WithPortRange sets the range of ports used for listeners of services which do not
+// specify a source port, when not set a free port is allocated by the operating system.
+func WithPortRange(min, max int) Option {
+	return func(s *Server) {
+		if min <= 0 || max < min || max > 65535 {
+			return
+		}
+
+		s.portRange = portRange{min: min, max: max}
+	}
+}
+
+// WithBindAddress sets the address listeners bind to when a service does not set a bind
+// address, 0.0.0.0 listens on all IPv4 interfaces and :: on all IPv4 and IPv6 interfaces.
+func WithBindAddress(addr string) Option {
+	return func(s *Server) {
+		if ValidateDefaultBindAddress(addr) != nil {
+			return
+		}
+
+		s.bindAddress = addr
+	}
+}
+
+// WithLinkRateLimit sets the bandwidth limit in bytes per second shared by all the services
+// which use a link to a remote connector, burst is the number of bytes which can be sent at
+// once and defaults to the rate. A rate of 0 does not limit links, the limit for a link
+// can be changed with SetRateLimit.
+func WithLinkRateLimit(bytesPerSecond, burst int64) Option {
+	return func(s *Server) {
+		if bytesPerSecond <= 0 {
+			s.linkRateLimit = nil
+			return
+		}
+
+		s.linkRateLimit = &shipyard.RateLimit{BytesPerSecond: bytesPerSecond, Burst: burst}
+	}
+}
+
+// WithDialTimeout sets the time to wait for a connection to the destination of a service
+// when the service does not set a dial timeout
+func WithDialTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		if d <= 0 {
+			d = DefaultDialTimeout
+		}
+
+		s.dialTimeout = d
+	}
+}
+
+// WithProxyAllowList sets the destinations which can be dialed for clients of proxy services
+// exposed by remote connectors. A rule is a CIDR, an IP address or a hostname,
+// *.example.com matches any subdomain and * allows every destination. Proxy connections
+// are refused when no rules are set.
+func WithProxyAllowList(rules []string) Option {
+	return func(s *Server) {
+		if len(rules) == 0 {
+			s.proxyAllow = nil
+			return
+		}
+
+		s.proxyAllow = newDestinationRules(rules)
+	}
+}
+
+// WithProxyDenyList sets the destinations which can not be dialed for clients of proxy
+// services even when they are allowed, the rules use the same format as WithProxyAllowList.
+// A hostname is denied when it matches a hostname rule. The addresses a hostname resolves
+// to which are in the CIDRs are never dialed, the other allowed addresses are, so the
+// hostname is only refused when all of its addresses are denied.
+func WithProxyDenyList(rules []string) Option {
+	return func(s *Server) {
+		if len(rules) == 0 {
+			s.proxyDeny = nil
+			return
+		}
+
+		s.proxyDeny = newDestinationRules(rules)
+	}
+}
@@ -0,0 +1,64 @@
+package remote
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// minPooledBuffer and maxPooledBuffer are the smallest and largest buffers held by the
+// buffer pool, buffers are pooled in power of two sizes between the two
+const (
+	minPooledBuffer = 1 << 10
+	maxPooledBuffer = MaxMessageSize
+)
+
+var bufferPools = newBufferPools()
+
+func newBufferPools() []*sync.Pool {
+	pools := []*sync.Pool{}
+
+	for size := minPooledBuffer; size <= maxPooledBuffer; size <<= 1 {
+		n := size
+		pools = append(pools, &sync.Pool{
+			New: func() interface{} {
+				b := make([]byte, n)
+				return &b
+			},
+		})
+	}
+
+	return pools
+}
+
+// poolIndex returns the index of the smallest pool which holds buffers of at least n bytes
+func poolIndex(n int) int {
+	if n <= minPooledBuffer {
+		return 0
+	}
+
+	return bits.Len(uint(n-1)) - bits.Len(uint(minPooledBuffer-1))
+}
+
+// getBuffer returns a buffer of length n, the buffer should be returned
+// with putBuffer once it is no longer used
+func getBuffer(n int) []byte {
+	if n > maxPooledBuffer {
+		return make([]byte, n)
+	}
+
+	b := bufferPools[poolIndex(n)].Get().(*[]byte)
+
+	return (*b)[:n]
+}
+
+// putBuffer returns a buffer to the pool, only buffers returned by getBuffer which are
+// no longer referenced can be returned. Buffers larger than the pooled sizes are discarded.
+func putBuffer(b []byte) {
+	c := cap(b)
+	if c < minPooledBuffer || c > maxPooledBuffer || c&(c-1) != 0 {
+		return
+	}
+
+	b = b[:c]
+	bufferPools[poolIndex(c)].Put(&b)
+}
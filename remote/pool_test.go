@@ -0,0 +1,48 @@
+package remote
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBufferReturnsBufferOfLength(t *testing.T) {
+	for _, n := range []int{1, minPooledBuffer, MessageSize + 1, maxDatagramSize, MaxMessageSize} {
+		b := getBuffer(n)
+		require.Len(t, b, n)
+		require.GreaterOrEqual(t, cap(b), n)
+
+		putBuffer(b)
+	}
+}
+
+func TestGetBufferAllocatesLargeBuffers(t *testing.T) {
+	b := getBuffer(MaxMessageSize + 1)
+	require.Len(t, b, MaxMessageSize+1)
+}
+
+func TestPoolIndexUsesSmallestBuffer(t *testing.T) {
+	require.Equal(t, 0, poolIndex(1))
+	require.Equal(t, 0, poolIndex(minPooledBuffer))
+	require.Equal(t, 1, poolIndex(minPooledBuffer+1))
+	require.Equal(t, 2, poolIndex(MessageSize))
+	require.Equal(t, len(bufferPools)-1, poolIndex(MaxMessageSize))
+}
+
+func TestWriteBuffersDoesNotPoolReceivedData(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go io.Copy(io.Discard, client)
+
+	// data received from the stream is still referenced by the message
+	data := make([]byte, minPooledBuffer)
+	_, err := newBufferedConn(server).writeBuffers([][]byte{data, data})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		b := getBuffer(minPooledBuffer)
+		require.NotSame(t, &data[0], &b[0])
+	}
+}
@@ -0,0 +1,374 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// proxyConnectTimeout is the time a proxy client has to send its request and for the
+// remote to dial the requested destination
+var proxyConnectTimeout = 30 * time.Second
+
+// lookupNetIP resolves the destinations requested by proxy clients
+var lookupNetIP = net.DefaultResolver.LookupNetIP
+
+// destinationRules match the destinations requested by proxy clients, a rule is a CIDR,
+// an IP address or a hostname, *.example.com matches any subdomain and * matches every
+// destination
+type destinationRules struct {
+	hosts []string
+	cidrs []netip.Prefix
+}
+
+func newDestinationRules(rules []string) *destinationRules {
+	d := &destinationRules{}
+
+	for _, r := range rules {
+		r = strings.TrimSpace(r)
+
+		if p, err := netip.ParsePrefix(r); err == nil {
+			d.cidrs = append(d.cidrs, p.Masked())
+			continue
+		}
+
+		if a, err := netip.ParseAddr(r); err == nil {
+			d.cidrs = append(d.cidrs, netip.PrefixFrom(a, a.BitLen()))
+			continue
+		}
+
+		if r != "" {
+			d.hosts = append(d.hosts, normalizeHost(r))
+		}
+	}
+
+	return d
+}
+
+// matchHost returns true when the hostname matches a rule
+func (d *destinationRules) matchHost(host string) bool {
+	if d == nil {
+		return false
+	}
+
+	host = normalizeHost(host)
+
+	for _, h := range d.hosts {
+		if h == "*" || h == host || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchAddr returns true when the address is in one of the CIDRs
+func (d *destinationRules) matchAddr(a netip.Addr) bool {
+	if d == nil {
+		return false
+	}
+
+	for _, p := range d.cidrs {
+		if p.Contains(a.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateProxy returns an error when the service can not be used as a proxy
+func validateProxy(detail *shipyard.Service) error {
+	if detail.Proxy == shipyard.ProxyProtocol_NO_PROXY {
+		return nil
+	}
+
+	if detail.Type != shipyard.ServiceType_REMOTE {
+		return fmt.Errorf("proxy services must be remote services, destinations are dialed by the remote connector")
+	}
+
+	if detail.Protocol != shipyard.ServiceProtocol_TCP {
+		return fmt.Errorf("proxy services must use TCP")
+	}
+
+	if detail.DestinationAddr != "" || len(detail.DestinationAddrs) > 0 || detail.HealthCheck != nil {
+		return fmt.Errorf("destinations and health checks are not supported, the destination is requested by the client")
+	}
+
+	if detail.Match != nil || len(detail.Hosts) > 0 {
+		return fmt.Errorf("match rules and hosts are not supported for proxy services")
+	}
+
+	return nil
+}
+
+// acceptProxyConnection handles a connection to a proxy service, the destination for the
+// connection is requested by the client
+func (s *Server) acceptProxyConnection(serviceID string, si *streamInfo, svc *service, l net.Listener, conn net.Conn) {
+	// clients must complete the proxy handshake before the connection is used
+	conn.SetDeadline(time.Now().Add(proxyConnectTimeout))
+
+	switch svc.detail.Proxy {
+	case shipyard.ProxyProtocol_SOCKS5:
+		s.handleSOCKSConnection(serviceID, si, svc, l, conn)
+	default:
+		conn.Close()
+	}
+}
+
+// openProxyConnection opens a connection over the stream for a proxy client, the remote
+// dials the destination and replies with Connected or an Error. The result is sent to the
+// client with reply before any data is sent for the connection.
+func (s *Server) openProxyConnection(serviceID string, si *streamInfo, svc *service, l net.Listener, conn net.Conn, dest string, reply func(err error) error) {
+	c := s.openConnection(serviceID, si, svc, l, conn)
+	if c == nil {
+		reply(status.Errorf(codes.Unavailable, "unable to open connection to remote connector"))
+		return
+	}
+
+	s.log.Debug(
+		"proxy",
+		"message", "Request destination from remote",
+		"service_id", serviceID,
+		"connection_id", c.id,
+		"destination", dest)
+
+	si.sendConnectionMessage(
+		&shipyard.OpenData{
+			ServiceId:    serviceID,
+			ConnectionId: c.id,
+			Message: &shipyard.OpenData_NewConnection{
+				NewConnection: &shipyard.NewConnection{DestinationAddr: dest, Client: c.client},
+			},
+		},
+	)
+
+	var err error
+	select {
+	case err = <-c.dialed:
+	case <-c.done:
+		err = fmt.Errorf("connection closed")
+	case <-time.After(proxyConnectTimeout):
+		err = status.Errorf(codes.DeadlineExceeded, "timeout waiting for remote to dial %s", dest)
+	}
+
+	if err == nil {
+		conn.SetDeadline(time.Time{})
+		err = reply(nil)
+	} else {
+		reply(err)
+	}
+
+	if err != nil {
+		s.log.Debug(
+			"proxy",
+			"message", "Unable to open proxy connection",
+			"service_id", serviceID,
+			"connection_id", c.id,
+			"destination", dest,
+			"error", err)
+
+		s.closeConnection(svc, c)
+		si.sendConnectionMessage(
+			&shipyard.OpenData{
+				ServiceId:    serviceID,
+				ConnectionId: c.id,
+				Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
+			},
+		)
+
+		return
+	}
+
+	s.startConnection(serviceID, si, svc, c)
+}
+
+// handleConnectedMessage notifies a proxy connection that the remote has dialed the
+// destination
+func (s *Server) handleConnectedMessage(si *streamInfo, msg *shipyard.OpenData) {
+	svc, ok := si.services.get(msg.ServiceId)
+	if !ok {
+		return
+	}
+
+	if c, ok := svc.getTCPConnection(msg.ConnectionId); ok {
+		c.dialResult(nil)
+	}
+}
+
+// handleNewConnectionMessage dials the destination requested by a proxy client, the
+// destination is dialed without blocking the stream
+func (s *Server) handleNewConnectionMessage(si *streamInfo, msg *shipyard.OpenData, m *shipyard.OpenData_NewConnection) {
+	svc, ok := si.services.get(msg.ServiceId)
+	if !ok || !si.dialsUpstream(svc) || svc.detail.Proxy == shipyard.ProxyProtocol_NO_PROXY {
+		s.log.Error(
+			"proxy",
+			"message", "Service is not a proxy, ignoring connection",
+			"service_id", msg.ServiceId,
+			"connection_id", msg.ConnectionId)
+
+		s.sendConnectionError(si, msg.ServiceId, msg.ConnectionId, codes.NotFound, fmt.Errorf("service %s is not a proxy", msg.ServiceId))
+		return
+	}
+
+	go s.dialProxyDestination(si, svc, msg.ServiceId, msg.ConnectionId, m.NewConnection)
+}
+
+// dialProxyDestination opens a connection to the destination and replies with Connected,
+// the connection is closed with an error when the destination is not allowed
+func (s *Server) dialProxyDestination(si *streamInfo, svc *service, serviceID, connectionID string, nc *shipyard.NewConnection) {
+	dest := nc.DestinationAddr
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.serviceDialTimeout(svc.detail))
+	defer cancel()
+
+	addrs, code, err := s.proxyDestination(ctx, dest)
+	if err != nil {
+		s.log.Error(
+			"proxy",
+			"message", "Unable to open proxy connection",
+			"service_id", serviceID,
+			"connection_id", connectionID,
+			"destination", dest,
+			"error", err)
+
+		if code == codes.Unavailable {
+			svc.dials.failed(err)
+		}
+
+		s.sendConnectionError(si, serviceID, connectionID, code, err)
+		return
+	}
+
+	// try each of the allowed addresses in the order returned by the resolver
+	var conn net.Conn
+	var addr string
+
+	d := net.Dialer{}
+	for _, addr = range addrs {
+		conn, err = d.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		s.log.Error(
+			"proxy",
+			"message", "Unable to connect to proxy destination",
+			"service_id", serviceID,
+			"connection_id", connectionID,
+			"destination", dest,
+			"addrs", addrs,
+			"error", err)
+
+		svc.dials.failed(err)
+		s.sendConnectionError(si, serviceID, connectionID, codes.Unavailable, fmt.Errorf("unable to connect to destination %s: %s", dest, err))
+		return
+	}
+
+	if err := s.writeProxyHeader(svc, conn, nc.Client); err != nil {
+		conn.Close()
+		s.sendConnectionError(si, serviceID, connectionID, codes.Unavailable, fmt.Errorf("unable to send PROXY protocol header to %s: %s", dest, err))
+		return
+	}
+
+	s.log.Info(
+		"proxy",
+		"message", "Opened proxy connection",
+		"service_id", serviceID,
+		"connection_id", connectionID,
+		"destination", dest,
+		"addr", addr)
+
+	c := s.newServiceConn(svc, conn)
+	c.id = connectionID
+
+	svc.connMutex.Lock()
+	svc.setTCPConnection(connectionID, c)
+	svc.connMutex.Unlock()
+
+	// the client is told the destination is connected before any data is sent
+	si.sendConnectionMessage(
+		&shipyard.OpenData{
+			ServiceId:    serviceID,
+			ConnectionId: connectionID,
+			Message:      &shipyard.OpenData_Connected{Connected: &shipyard.Connected{}},
+		},
+	)
+
+	s.startConnection(serviceID, si, svc, c)
+}
+
+// proxyDestination returns the addresses to dial for the destination requested by a proxy
+// client. The destination is resolved using the integration, it must match a hostname in
+// the allow list or resolve to an address in one of the allowed CIDRs, and must not match
+// the deny list. The resolved addresses are dialed so that the name can not resolve to a
+// different address later.
+func (s *Server) proxyDestination(ctx context.Context, dest string) ([]string, codes.Code, error) {
+	host, _, err := net.SplitHostPort(dest)
+	if err != nil {
+		return nil, codes.InvalidArgument, fmt.Errorf("invalid destination %s: %s", dest, err)
+	}
+
+	if s.proxyDeny.matchHost(host) {
+		return nil, codes.PermissionDenied, fmt.Errorf("destination %s is denied", dest)
+	}
+
+	addr, err := s.lookupIntegration(dest)
+	if err != nil {
+		return nil, codes.Unavailable, fmt.Errorf("unable to find address for destination %s: %s", dest, err)
+	}
+
+	if addr == "" {
+		addr = dest
+	}
+
+	h, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, codes.Unavailable, fmt.Errorf("invalid address %s for destination %s: %s", addr, dest, err)
+	}
+
+	ips, err := lookupNetIP(ctx, "ip", h)
+	if err != nil {
+		return nil, codes.Unavailable, fmt.Errorf("unable to resolve destination %s: %s", dest, err)
+	}
+
+	allowed := s.proxyAllow.matchHost(host)
+
+	var addrs []string
+	for _, ip := range ips {
+		if s.proxyDeny.matchAddr(ip) {
+			continue
+		}
+
+		if allowed || s.proxyAllow.matchAddr(ip) {
+			addrs = append(addrs, net.JoinHostPort(ip.Unmap().String(), port))
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, codes.PermissionDenied, fmt.Errorf("destination %s is not allowed", dest)
+	}
+
+	return addrs, codes.OK, nil
+}
+
+// proxyErrorCode returns the code for an error opening a proxy connection, errors wrapped
+// by the HTTP transport are unwrapped
+func proxyErrorCode(err error) codes.Code {
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus().Code()
+	}
+
+	return codes.Unknown
+}
@@ -0,0 +1,140 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exposeSOCKSService exposes a SOCKS5 proxy on servers[0] which dials destinations from
+// servers[1], returns the address of the proxy
+func exposeSOCKSService(t *testing.T, c shipyard.RemoteConnectionClient, servers []*serverStruct) string {
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test Proxy",
+			RemoteConnectorAddr: servers[1].Address,
+			Type:                shipyard.ServiceType_REMOTE,
+			Proxy:               shipyard.ProxyProtocol_SOCKS5,
+		},
+	})
+	require.NoError(t, err)
+
+	return fmt.Sprintf("localhost:%d", waitForAllocatedPort(t, servers[0].Server, resp.Id))
+}
+
+func TestDestinationRules(t *testing.T) {
+	d := newDestinationRules([]string{"10.0.0.0/8", "192.168.1.10", "api.example.com", "*.svc.cluster.local"})
+
+	require.True(t, d.matchAddr(netip.MustParseAddr("10.1.2.3")))
+	require.True(t, d.matchAddr(netip.MustParseAddr("::ffff:10.1.2.3")))
+	require.True(t, d.matchAddr(netip.MustParseAddr("192.168.1.10")))
+	require.False(t, d.matchAddr(netip.MustParseAddr("192.168.1.11")))
+
+	require.True(t, d.matchHost("API.example.com"))
+	require.True(t, d.matchHost("web.default.svc.cluster.local"))
+	require.False(t, d.matchHost("svc.cluster.local"))
+	require.False(t, d.matchHost("example.com"))
+
+	require.True(t, newDestinationRules([]string{"*"}).matchHost("10.1.2.3"))
+
+	var none *destinationRules
+	require.False(t, none.matchHost("api.example.com"))
+}
+
+func TestExposeProxyServiceWithDestinationReturnsError(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	tests := []*shipyard.Service{
+		{DestinationAddr: "localhost:8080", Type: shipyard.ServiceType_REMOTE},
+		{Type: shipyard.ServiceType_LOCAL},
+		{Type: shipyard.ServiceType_REMOTE, Protocol: shipyard.ServiceProtocol_UDP},
+	}
+
+	for _, svc := range tests {
+		svc.Name = "Test Proxy"
+		svc.RemoteConnectorAddr = servers[1].Address
+		svc.Proxy = shipyard.ProxyProtocol_SOCKS5
+
+		_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{Service: svc})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestSOCKSProxyDialsDestinationFromRemote(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	WithProxyAllowList([]string{"127.0.0.0/8"})(servers[1].Server)
+
+	d, err := proxy.SOCKS5("tcp", exposeSOCKSService(t, c, servers), nil, proxy.Direct)
+	require.NoError(t, err)
+
+	// each connection can request a different destination
+	for i := 0; i < 2; i++ {
+		_, port, _ := net.SplitHostPort(startEchoServer(t))
+
+		conn, err := d.Dial("tcp", net.JoinHostPort("localhost", port))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		requireEcho(t, conn)
+	}
+}
+
+func TestSOCKSProxyRefusesDestinationNotAllowed(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	WithProxyAllowList([]string{"10.0.0.0/8"})(servers[1].Server)
+
+	conn, err := net.Dial("tcp", exposeSOCKSService(t, c, servers))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, port, _ := net.SplitHostPort(startEchoServer(t))
+	p, _ := netip.ParseAddrPort("127.0.0.1:" + port)
+
+	// no authentication then CONNECT to 127.0.0.1
+	_, err = conn.Write([]byte{5, 1, 0})
+	require.NoError(t, err)
+
+	req := append([]byte{5, 1, 0, 1, 127, 0, 0, 1}, byte(p.Port()>>8), byte(p.Port()))
+	_, err = conn.Write(req)
+	require.NoError(t, err)
+
+	reply := make([]byte, 12)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+
+	require.Equal(t, []byte{5, 0}, reply[:2])
+	require.Equal(t, byte(socksNotAllowed), reply[3])
+}
+
+func TestProxyDestinationOnlyDialsAddressesWhichAreNotDenied(t *testing.T) {
+	lookup := lookupNetIP
+	t.Cleanup(func() { lookupNetIP = lookup })
+
+	lookupNetIP = func(ctx context.Context, network, host string) ([]netip.Addr, error) {
+		return []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("192.168.1.1")}, nil
+	}
+
+	s := New(hclog.NewNullLogger(), nil, nil, nil, WithProxyAllowList([]string{"*"}), WithProxyDenyList([]string{"10.0.0.0/8"}))
+
+	// the denied address is removed, the allowed address is dialed
+	addrs, _, err := s.proxyDestination(context.Background(), "api.example.com:80")
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.168.1.1:80"}, addrs)
+
+	// the hostname is refused when all of its addresses are denied
+	WithProxyDenyList([]string{"10.0.0.0/8", "192.168.0.0/16"})(s)
+
+	_, code, err := s.proxyDestination(context.Background(), "api.example.com:80")
+	require.Error(t, err)
+	require.Equal(t, codes.PermissionDenied, code)
+}
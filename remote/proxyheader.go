@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// proxyHeaderSignature starts every PROXY protocol v2 header
+var proxyHeaderSignature = []byte{0x0d, 0x0a, 0x0d, 0x0a, 0x00, 0x0d, 0x0a, 0x51, 0x55, 0x49, 0x54, 0x0a}
+
+// PROXY protocol v2 commands and address families
+const (
+	proxyHeaderLocal    = 0x20 // version 2, the connection was not proxied
+	proxyHeaderProxy    = 0x21 // version 2, the connection was proxied for the client
+	proxyHeaderUnspec   = 0x00
+	proxyHeaderTCPIPv4  = 0x11
+	proxyHeaderTCPIPv6  = 0x21
+	proxyHeaderIPv4Size = 12
+	proxyHeaderIPv6Size = 36
+)
+
+// newClient returns the addresses for the client of a connection accepted by a listener,
+// connections which are not TCP such as Unix sockets and the connections for requests
+// routed by match rules send the address strings which are sent in the header as unknown
+func newClient(conn net.Conn) *shipyard.Client {
+	c := &shipyard.Client{}
+
+	if a := conn.RemoteAddr(); a != nil {
+		c.Addr = a.String()
+	}
+
+	if a := conn.LocalAddr(); a != nil {
+		c.LocalAddr = a.String()
+	}
+
+	return c
+}
+
+// writeProxyHeader sends the PROXY protocol header for the service to a connection
+// which has been dialed for the client
+func (s *Server) writeProxyHeader(svc *service, conn net.Conn, client *shipyard.Client) error {
+	if svc.detail.ProxyHeader == shipyard.ProxyHeader_NO_PROXY_HEADER {
+		return nil
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(s.serviceDialTimeout(svc.detail)))
+	defer conn.SetWriteDeadline(time.Time{})
+
+	_, err := conn.Write(proxyHeader(svc.detail.ProxyHeader, client))
+	return err
+}
+
+// proxyHeader returns the PROXY protocol header for the client, the addresses are unknown
+// when the client is nil or the addresses can not be parsed
+func proxyHeader(version shipyard.ProxyHeader, client *shipyard.Client) []byte {
+	src, srcErr := netip.ParseAddrPort(client.GetAddr())
+	dst, dstErr := netip.ParseAddrPort(client.GetLocalAddr())
+	known := srcErr == nil && dstErr == nil
+
+	// addresses of different families are both sent as IPv6
+	ipv4 := known && src.Addr().Unmap().Is4() && dst.Addr().Unmap().Is4()
+
+	if version == shipyard.ProxyHeader_PROXY_V1 {
+		switch {
+		case !known:
+			return []byte("PROXY UNKNOWN\r\n")
+		case ipv4:
+			return []byte(fmt.Sprintf("PROXY TCP4 %s %s %d %d\r\n", src.Addr().Unmap(), dst.Addr().Unmap(), src.Port(), dst.Port()))
+		default:
+			return []byte(fmt.Sprintf("PROXY TCP6 %s %s %d %d\r\n", ipv6(src.Addr()), ipv6(dst.Addr()), src.Port(), dst.Port()))
+		}
+	}
+
+	buf := bytes.NewBuffer(append([]byte{}, proxyHeaderSignature...))
+
+	switch {
+	case !known:
+		buf.Write([]byte{proxyHeaderLocal, proxyHeaderUnspec, 0, 0})
+	case ipv4:
+		buf.Write([]byte{proxyHeaderProxy, proxyHeaderTCPIPv4})
+		binary.Write(buf, binary.BigEndian, uint16(proxyHeaderIPv4Size))
+
+		s, d := src.Addr().Unmap().As4(), dst.Addr().Unmap().As4()
+		buf.Write(s[:])
+		buf.Write(d[:])
+	default:
+		buf.Write([]byte{proxyHeaderProxy, proxyHeaderTCPIPv6})
+		binary.Write(buf, binary.BigEndian, uint16(proxyHeaderIPv6Size))
+
+		s, d := src.Addr().As16(), dst.Addr().As16()
+		buf.Write(s[:])
+		buf.Write(d[:])
+	}
+
+	if known {
+		binary.Write(buf, binary.BigEndian, src.Port())
+		binary.Write(buf, binary.BigEndian, dst.Port())
+	}
+
+	return buf.Bytes()
+}
+
+// ipv6 returns the IPv6 form of an address, IPv4 addresses are mapped
+func ipv6(a netip.Addr) netip.Addr {
+	return netip.AddrFrom16(a.As16())
+}
@@ -0,0 +1,161 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startProxyHeaderServer starts a server which replies with the PROXY protocol v1 header
+// sent for each connection and then echoes the data
+func startProxyHeaderServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer c.Close()
+
+				br := bufio.NewReader(c)
+				line, err := br.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				io.WriteString(c, line)
+				io.Copy(c, br)
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func TestProxyHeaderV1(t *testing.T) {
+	client := &shipyard.Client{Addr: "192.168.1.10:51234", LocalAddr: "10.0.0.1:8080"}
+	require.Equal(t, "PROXY TCP4 192.168.1.10 10.0.0.1 51234 8080\r\n", string(proxyHeader(shipyard.ProxyHeader_PROXY_V1, client)))
+
+	client = &shipyard.Client{Addr: "[2001:db8::1]:51234", LocalAddr: "10.0.0.1:8080"}
+	require.Equal(t, "PROXY TCP6 2001:db8::1 ::ffff:10.0.0.1 51234 8080\r\n", string(proxyHeader(shipyard.ProxyHeader_PROXY_V1, client)))
+
+	require.Equal(t, "PROXY UNKNOWN\r\n", string(proxyHeader(shipyard.ProxyHeader_PROXY_V1, nil)))
+}
+
+func TestProxyHeaderV2(t *testing.T) {
+	client := &shipyard.Client{Addr: "192.168.1.10:51234", LocalAddr: "10.0.0.1:8080"}
+
+	h := proxyHeader(shipyard.ProxyHeader_PROXY_V2, client)
+	require.Equal(t, proxyHeaderSignature, h[:12])
+	require.Equal(t, []byte{0x21, 0x11, 0, 12, 192, 168, 1, 10, 10, 0, 0, 1, 0xc8, 0x22, 0x1f, 0x90}, h[12:])
+
+	h = proxyHeader(shipyard.ProxyHeader_PROXY_V2, nil)
+	require.Equal(t, []byte{0x20, 0x00, 0, 0}, h[12:])
+}
+
+func TestExposeUDPServiceWithProxyHeaderReturnsError(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "localhost:53",
+			Type:                shipyard.ServiceType_REMOTE,
+			Protocol:            shipyard.ServiceProtocol_UDP,
+			ProxyHeader:         shipyard.ProxyHeader_PROXY_V1,
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestExposeServiceSendsProxyHeaderWithClientAddress(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     startProxyHeaderServer(t),
+			Type:                shipyard.ServiceType_REMOTE,
+			ProxyHeader:         shipyard.ProxyHeader_PROXY_V1,
+		},
+	})
+	require.NoError(t, err)
+
+	p := waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", p))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the destination is dialed when the first data is received
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	line, err := br.ReadString('\n')
+	require.NoError(t, err)
+
+	_, clientPort, _ := net.SplitHostPort(conn.LocalAddr().String())
+	require.Equal(t, fmt.Sprintf("PROXY TCP4 127.0.0.1 127.0.0.1 %s %d\r\n", clientPort, p), line)
+
+	d := make([]byte, 5)
+	_, err = io.ReadFull(br, d)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(d))
+}
+
+func TestExposeUnixSocketServiceSendsUnknownProxyHeader(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	path := filepath.Join(t.TempDir(), "listener.sock")
+
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     startProxyHeaderServer(t),
+			BindAddress:         "unix://" + path,
+			Type:                shipyard.ServiceType_REMOTE,
+			ProxyHeader:         shipyard.ProxyHeader_PROXY_V1,
+		},
+	})
+	require.NoError(t, err)
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", path)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	// the client of a Unix socket has no IP address
+	br := bufio.NewReader(conn)
+	line, err := br.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "PROXY UNKNOWN\r\n", line)
+
+	d := make([]byte, 5)
+	_, err = io.ReadFull(br, d)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(d))
+}
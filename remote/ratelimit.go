@@ -0,0 +1,162 @@
+package remote
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// tokenBucket limits the rate data is sent and received. Each byte takes a token from
+// the bucket which is refilled at the configured rate up to the burst size, when the
+// bucket is empty data waits until enough tokens have been added.
+type tokenBucket struct {
+	lock    sync.Mutex
+	rate    float64 // bytes per second, 0 is unlimited
+	burst   float64
+	tokens  float64 // negative when data is waiting for tokens
+	last    time.Time
+	changed chan struct{} // closed when the limit changes to release waiting data
+
+	bytes     int64
+	throttled time.Duration
+	waiting   int32
+}
+
+func newTokenBucket(l *shipyard.RateLimit) *tokenBucket {
+	b := &tokenBucket{changed: make(chan struct{})}
+	b.set(l)
+
+	return b
+}
+
+// set changes the limit, data which is waiting for the previous limit is released
+func (b *tokenBucket) set(l *shipyard.RateLimit) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	wasLimited := b.rate > 0
+
+	b.rate = 0
+	b.burst = 0
+
+	if l != nil && l.BytesPerSecond > 0 {
+		b.rate = float64(l.BytesPerSecond)
+		b.burst = float64(l.Burst)
+
+		if b.burst <= 0 {
+			b.burst = b.rate
+		}
+	}
+
+	if !wasLimited || b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+
+	b.last = time.Now()
+
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// limit returns the current limit, nil when the bucket is unlimited
+func (b *tokenBucket) limit() *shipyard.RateLimit {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.rate <= 0 {
+		return nil
+	}
+
+	return &shipyard.RateLimit{BytesPerSecond: int64(b.rate), Burst: int64(b.burst)}
+}
+
+// wait takes n tokens from the bucket, waiting until the bucket has been refilled when
+// there are not enough tokens. Returns false when done is closed while waiting.
+func (b *tokenBucket) wait(n int, done <-chan struct{}) bool {
+	b.lock.Lock()
+	b.bytes += int64(n)
+
+	if b.rate <= 0 {
+		b.lock.Unlock()
+		return true
+	}
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	// data larger than the bucket is allowed by taking the tokens which will be
+	// added while waiting
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		b.lock.Unlock()
+		return true
+	}
+
+	d := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	changed := b.changed
+	b.waiting++
+	b.lock.Unlock()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	ok := true
+	select {
+	case <-t.C:
+	case <-changed:
+	case <-done:
+		ok = false
+	}
+
+	b.lock.Lock()
+	b.waiting--
+	b.throttled += time.Since(now)
+	b.lock.Unlock()
+
+	return ok
+}
+
+// status returns the totals for the bucket
+func (b *tokenBucket) status() *shipyard.ThrottleStats {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return &shipyard.ThrottleStats{
+		Bytes:         b.bytes,
+		ThrottledTime: int64(b.throttled),
+		Waiting:       b.waiting,
+	}
+}
+
+// bufferedBytes returns the total size of the buffers
+func bufferedBytes(data [][]byte) int {
+	n := 0
+	for _, d := range data {
+		n += len(d)
+	}
+
+	return n
+}
+
+// validateRateLimit returns an error when the rate or burst is negative
+func validateRateLimit(l *shipyard.RateLimit) error {
+	if l != nil && (l.BytesPerSecond < 0 || l.Burst < 0) {
+		return fmt.Errorf("bytes per second and burst must not be negative")
+	}
+
+	return nil
+}
+
+// waitForRateLimit waits until the rate limits for the service and the link allow n bytes
+// to be sent or received, returns false when the connection is closed while waiting
+func (s *Server) waitForRateLimit(si *streamInfo, svc *service, c *bufferedConn, n int) bool {
+	if !svc.rateLimiter().wait(n, c.done) {
+		return false
+	}
+
+	return si.rateLimit.wait(n, c.done)
+}
@@ -0,0 +1,141 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTokenBucketWaitsWhenEmpty(t *testing.T) {
+	b := newTokenBucket(&shipyard.RateLimit{BytesPerSecond: 1000})
+
+	start := time.Now()
+	require.True(t, b.wait(1000, nil))
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	require.True(t, b.wait(500, nil))
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	st := b.status()
+	require.Equal(t, int64(1500), st.Bytes)
+	require.Greater(t, st.ThrottledTime, int64(0))
+	require.Equal(t, int32(0), st.Waiting)
+}
+
+func TestTokenBucketWithoutLimitDoesNotWait(t *testing.T) {
+	b := newTokenBucket(nil)
+
+	require.Nil(t, b.limit())
+	require.True(t, b.wait(1<<30, nil))
+}
+
+func TestTokenBucketReleasesWaitingDataWhenLimitChanges(t *testing.T) {
+	b := newTokenBucket(&shipyard.RateLimit{BytesPerSecond: 10, Burst: 10})
+	b.wait(10, nil)
+
+	done := make(chan bool)
+	go func() { done <- b.wait(1000, nil) }()
+
+	require.Eventually(t, func() bool { return b.status().Waiting == 1 }, time.Second, 10*time.Millisecond)
+
+	b.set(nil)
+	require.True(t, <-done)
+}
+
+func TestTokenBucketReturnsFalseWhenClosedWhileWaiting(t *testing.T) {
+	b := newTokenBucket(&shipyard.RateLimit{BytesPerSecond: 10, Burst: 10})
+	b.wait(10, nil)
+
+	closed := make(chan struct{})
+	close(closed)
+
+	require.False(t, b.wait(1000, closed))
+}
+
+func TestServiceWithRateLimitThrottlesData(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     startEchoServer(t),
+			Type:                shipyard.ServiceType_REMOTE,
+			RateLimit:           &shipyard.RateLimit{BytesPerSecond: 32 * 1024, Burst: 16 * 1024},
+		},
+	})
+	require.NoError(t, err)
+
+	conn := dialService(t, waitForAllocatedPort(t, servers[0].Server, resp.Id))
+
+	// the data is counted when it is read from the client and again when the
+	// reply is written, 64k at 32k/s with a 16k burst takes at least 1.5s
+	data := bytes.Repeat([]byte("a"), 32*1024)
+	start := time.Now()
+
+	go conn.Write(data)
+
+	reply := make([]byte, len(data))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	list, err := c.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+	require.Equal(t, int64(64*1024), list.Services[0].ThrottleStats.Bytes)
+	require.Greater(t, list.Services[0].ThrottleStats.ThrottledTime, int64(0))
+}
+
+func TestSetRateLimitChangesLimitForServiceAndLink(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     startEchoServer(t),
+			Type:                shipyard.ServiceType_REMOTE,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = c.SetRateLimit(context.Background(), &shipyard.RateLimitRequest{
+		ServiceId: resp.Id,
+		Limit:     &shipyard.RateLimit{BytesPerSecond: 1024},
+	})
+	require.NoError(t, err)
+
+	_, err = c.SetRateLimit(context.Background(), &shipyard.RateLimitRequest{
+		RemoteConnectorAddr: servers[1].Address,
+		Limit:               &shipyard.RateLimit{BytesPerSecond: 2048, Burst: 4096},
+	})
+	require.NoError(t, err)
+
+	list, err := c.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+
+	svc := list.Services[0]
+	require.Equal(t, int64(1024), svc.RateLimit.BytesPerSecond)
+	require.Equal(t, int64(1024), svc.RateLimit.Burst)
+	require.NotNil(t, svc.ThrottleStats)
+	require.Equal(t, int64(4096), svc.Link.RateLimit.Burst)
+	require.NotNil(t, svc.Link.ThrottleStats)
+
+	// removing the limit
+	_, err = c.SetRateLimit(context.Background(), &shipyard.RateLimitRequest{ServiceId: resp.Id})
+	require.NoError(t, err)
+
+	list, err = c.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+	require.Nil(t, list.Services[0].RateLimit)
+
+	_, err = c.SetRateLimit(context.Background(), &shipyard.RateLimitRequest{RemoteConnectorAddr: "localhost:1"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
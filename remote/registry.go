@@ -0,0 +1,359 @@
+package remote
+
+import (
+	"sync"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// listenerKey identifies a port with a listener on this server
+type listenerKey struct {
+	protocol shipyard.ServiceProtocol
+	port     int32
+}
+
+// remotePortKey identifies a port on a remote connector
+type remotePortKey struct {
+	addr     string
+	protocol shipyard.ServiceProtocol
+	port     int32
+}
+
+// serviceEntry is the index entry for a service
+type serviceEntry struct {
+	stream   *streamInfo
+	listener *listenerKey   // set for services which listen on this server
+	remote   *remotePortKey // not set until a port has been allocated
+	hosts    []string       // virtual hosts indexed for the ports
+}
+
+// portUsers are the services using a port, services with virtual hosts share a port
+// when none of their hosts are the same
+type portUsers struct {
+	exclusive int            // services without virtual hosts
+	hosts     map[string]int // virtual hosts of the services sharing the port
+}
+
+// available returns true when a service with the virtual hosts can use the port
+func (p *portUsers) available(hosts []string) bool {
+	if p == nil {
+		return true
+	}
+
+	// services without virtual hosts need the port to themselves
+	if p.exclusive > 0 || len(hosts) == 0 {
+		return false
+	}
+
+	for _, h := range hosts {
+		if p.hosts[h] > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *portUsers) add(hosts []string) {
+	if len(hosts) == 0 {
+		p.exclusive++
+		return
+	}
+
+	for _, h := range hosts {
+		p.hosts[h]++
+	}
+}
+
+func (p *portUsers) remove(hosts []string) {
+	if len(hosts) == 0 {
+		p.exclusive--
+		return
+	}
+
+	for _, h := range hosts {
+		p.hosts[h]--
+		if p.hosts[h] <= 0 {
+			delete(p.hosts, h)
+		}
+	}
+}
+
+// empty returns true when no services use the port
+func (p *portUsers) empty() bool {
+	return p.exclusive <= 0 && len(p.hosts) == 0
+}
+
+// registry holds the streams for a server, streams are indexed by the remote address,
+// session and the ids of their services. The ports used by the services are indexed
+// to detect services which would use the same port.
+type registry struct {
+	lock sync.RWMutex
+
+	streams   map[*streamInfo]struct{}
+	byAddr    map[string]*streamInfo // outbound streams by remote connector address
+	bySession map[string]*streamInfo
+	byService map[string]*serviceEntry
+
+	listenerPorts map[listenerKey]*portUsers
+	remotePorts   map[remotePortKey]*portUsers
+}
+
+func newRegistry() *registry {
+	return &registry{
+		streams:       map[*streamInfo]struct{}{},
+		byAddr:        map[string]*streamInfo{},
+		bySession:     map[string]*streamInfo{},
+		byService:     map[string]*serviceEntry{},
+		listenerPorts: map[listenerKey]*portUsers{},
+		remotePorts:   map[remotePortKey]*portUsers{},
+	}
+}
+
+// add a stream to the registry
+func (r *registry) add(si *streamInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.streams[si] = struct{}{}
+
+	if !si.inbound {
+		r.byAddr[si.addr] = si
+	}
+
+	if si.session != "" {
+		r.bySession[si.session] = si
+	}
+}
+
+// remove a stream and its services from the registry
+func (r *registry) remove(si *streamInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.streams[si]; !ok {
+		return
+	}
+
+	delete(r.streams, si)
+
+	if r.byAddr[si.addr] == si {
+		delete(r.byAddr, si.addr)
+	}
+
+	if si.session != "" && r.bySession[si.session] == si {
+		delete(r.bySession, si.session)
+	}
+
+	for id, e := range r.byService {
+		if e.stream == si {
+			r.unindexService(id, e)
+		}
+	}
+}
+
+// all returns the streams in the registry
+func (r *registry) all() []*streamInfo {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	streams := make([]*streamInfo, 0, len(r.streams))
+	for si := range r.streams {
+		streams = append(streams, si)
+	}
+
+	return streams
+}
+
+// findOrAddRemote returns the outbound stream for the remote address, if the stream
+// does not exist it is created and added to the registry
+func (r *registry) findOrAddRemote(addr string, create func() *streamInfo) (*streamInfo, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if si, ok := r.byAddr[addr]; ok {
+		return si, true
+	}
+
+	si := create()
+	r.streams[si] = struct{}{}
+	r.byAddr[addr] = si
+
+	if si.session != "" {
+		r.bySession[si.session] = si
+	}
+
+	return si, false
+}
+
+func (r *registry) findByRemoteAddr(addr string) (*streamInfo, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	si, ok := r.byAddr[addr]
+	return si, ok
+}
+
+func (r *registry) findBySession(id string) (*streamInfo, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	si, ok := r.bySession[id]
+	return si, ok
+}
+
+func (r *registry) findByServiceID(id string) (*streamInfo, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	e, ok := r.byService[id]
+	if !ok {
+		return nil, false
+	}
+
+	return e.stream, true
+}
+
+// setSession sets the session id for a stream
+func (r *registry) setSession(si *streamInfo, id string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if si.session != "" && r.bySession[si.session] == si {
+		delete(r.bySession, si.session)
+	}
+
+	si.session = id
+
+	if _, ok := r.streams[si]; ok {
+		r.bySession[id] = si
+	}
+}
+
+// addService adds a service to the stream, returns false when the port for the
+// service is already used by another service. Services exposed by the remote on
+// an inbound stream are not checked, they are validated by creating the listener.
+func (r *registry) addService(si *streamInfo, id string, svc *service) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !si.inbound && r.portInUse(svc.detail) {
+		return false
+	}
+
+	e := &serviceEntry{stream: si}
+	r.indexPorts(e, svc.detail)
+	r.byService[id] = e
+
+	si.services.add(id, svc)
+
+	return true
+}
+
+// setServicePort sets the source port allocated for a service which was exposed without
+// a port and updates the port index
+func (r *registry) setServicePort(id string, svc *service, port int32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	svc.setSourcePort(port)
+
+	e, ok := r.byService[id]
+	if !ok {
+		return
+	}
+
+	r.unindexPorts(e)
+	r.indexPorts(e, svc.detail)
+}
+
+// removeService removes a service from the stream
+func (r *registry) removeService(si *streamInfo, id string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	si.services.delete(id)
+
+	if e, ok := r.byService[id]; ok && e.stream == si {
+		r.unindexService(id, e)
+	}
+}
+
+// portInUse returns true when a service already uses the port required by the service,
+// services using different protocols or different virtual hosts can use the same port
+func (r *registry) portInUse(detail *shipyard.Service) bool {
+	// the port is allocated when the listener is created
+	if detail.SourcePort == 0 {
+		return false
+	}
+
+	// check to see if we already have a listener defined for this port
+	if !r.listenerPorts[listenerKey{detail.Protocol, detail.SourcePort}].available(detail.Hosts) {
+		return true
+	}
+
+	// check to see if there is a listener defined on the remote server for this port
+	return detail.Type == shipyard.ServiceType_LOCAL &&
+		!r.remotePorts[remotePortKey{detail.RemoteConnectorAddr, detail.Protocol, detail.SourcePort}].available(detail.Hosts)
+}
+
+func (r *registry) unindexService(id string, e *serviceEntry) {
+	delete(r.byService, id)
+	r.unindexPorts(e)
+}
+
+// indexPorts adds the ports used by a service to the index, services without a
+// port are indexed once the port has been allocated
+func (r *registry) indexPorts(e *serviceEntry, detail *shipyard.Service) {
+	if detail.SourcePort == 0 {
+		return
+	}
+
+	e.hosts = detail.Hosts
+
+	if detail.Type == shipyard.ServiceType_REMOTE {
+		e.listener = &listenerKey{detail.Protocol, detail.SourcePort}
+		r.listenerPort(*e.listener).add(e.hosts)
+	}
+
+	e.remote = &remotePortKey{detail.RemoteConnectorAddr, detail.Protocol, detail.SourcePort}
+	r.remotePort(*e.remote).add(e.hosts)
+}
+
+func (r *registry) listenerPort(k listenerKey) *portUsers {
+	if _, ok := r.listenerPorts[k]; !ok {
+		r.listenerPorts[k] = &portUsers{hosts: map[string]int{}}
+	}
+
+	return r.listenerPorts[k]
+}
+
+func (r *registry) remotePort(k remotePortKey) *portUsers {
+	if _, ok := r.remotePorts[k]; !ok {
+		r.remotePorts[k] = &portUsers{hosts: map[string]int{}}
+	}
+
+	return r.remotePorts[k]
+}
+
+func (r *registry) unindexPorts(e *serviceEntry) {
+	if e.listener != nil {
+		p := r.listenerPorts[*e.listener]
+		p.remove(e.hosts)
+		if p.empty() {
+			delete(r.listenerPorts, *e.listener)
+		}
+
+		e.listener = nil
+	}
+
+	if e.remote != nil {
+		p := r.remotePorts[*e.remote]
+		p.remove(e.hosts)
+		if p.empty() {
+			delete(r.remotePorts, *e.remote)
+		}
+
+		e.remote = nil
+	}
+}
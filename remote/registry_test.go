@@ -0,0 +1,219 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(addr string, port int32, t shipyard.ServiceType) *service {
+	svc := newService()
+	svc.detail = &shipyard.Service{
+		RemoteConnectorAddr: addr,
+		SourcePort:          port,
+		Type:                t,
+	}
+
+	return svc
+}
+
+func TestRegistryFindsStreamsByIndex(t *testing.T) {
+	r := newRegistry()
+
+	si, found := r.findOrAddRemote("remote:9090", func() *streamInfo {
+		si := newStreamInfo()
+		si.addr = "remote:9090"
+		si.session = "abc"
+
+		return si
+	})
+	require.False(t, found)
+
+	existing, found := r.findOrAddRemote("remote:9090", nil)
+	require.True(t, found)
+	require.Equal(t, si, existing)
+
+	require.True(t, r.addService(si, "svc1", newTestService("remote:9090", 9000, shipyard.ServiceType_LOCAL)))
+
+	s, ok := r.findByRemoteAddr("remote:9090")
+	require.True(t, ok)
+	require.Equal(t, si, s)
+
+	s, ok = r.findBySession("abc")
+	require.True(t, ok)
+	require.Equal(t, si, s)
+
+	s, ok = r.findByServiceID("svc1")
+	require.True(t, ok)
+	require.Equal(t, si, s)
+}
+
+func TestRegistryDoesNotIndexInboundStreamsByAddress(t *testing.T) {
+	r := newRegistry()
+
+	si := newStreamInfo()
+	si.addr = "remote:9090"
+	si.inbound = true
+	r.add(si)
+
+	_, ok := r.findByRemoteAddr("remote:9090")
+	require.False(t, ok)
+
+	r.setSession(si, "abc")
+
+	s, ok := r.findBySession("abc")
+	require.True(t, ok)
+	require.Equal(t, si, s)
+}
+
+func TestRegistryRejectsServicesUsingTheSamePort(t *testing.T) {
+	r := newRegistry()
+
+	si, _ := r.findOrAddRemote("remote:9090", func() *streamInfo { return newStreamInfo() })
+
+	require.True(t, r.addService(si, "svc1", newTestService("remote:9090", 9000, shipyard.ServiceType_REMOTE)))
+	require.True(t, r.addService(si, "svc2", newTestService("remote:9090", 9001, shipyard.ServiceType_LOCAL)))
+
+	require.False(t, r.addService(si, "svc3", newTestService("other:9090", 9000, shipyard.ServiceType_REMOTE)))
+	require.False(t, r.addService(si, "svc4", newTestService("remote:9090", 9001, shipyard.ServiceType_LOCAL)))
+	require.True(t, r.addService(si, "svc5", newTestService("other:9090", 9001, shipyard.ServiceType_LOCAL)))
+}
+
+func TestRegistryAllowsServicesWithDifferentHostsToSharePort(t *testing.T) {
+	r := newRegistry()
+
+	si, _ := r.findOrAddRemote("remote:9090", func() *streamInfo { return newStreamInfo() })
+
+	svc := func(port int32, hosts ...string) *service {
+		s := newTestService("remote:9090", port, shipyard.ServiceType_REMOTE)
+		s.detail.Hosts = hosts
+
+		return s
+	}
+
+	require.True(t, r.addService(si, "svc1", svc(9000, "a.example.com")))
+	require.True(t, r.addService(si, "svc2", svc(9000, "b.example.com", "c.example.com")))
+
+	require.False(t, r.addService(si, "svc3", svc(9000, "c.example.com")))
+	require.False(t, r.addService(si, "svc4", svc(9000)))
+
+	require.True(t, r.addService(si, "svc5", svc(9001)))
+	require.False(t, r.addService(si, "svc6", svc(9001, "d.example.com")))
+
+	// the hosts are released when the service is removed
+	r.removeService(si, "svc2")
+	require.True(t, r.addService(si, "svc7", svc(9000, "c.example.com")))
+}
+
+func TestRegistryRemoveServiceReleasesPort(t *testing.T) {
+	r := newRegistry()
+
+	si, _ := r.findOrAddRemote("remote:9090", func() *streamInfo { return newStreamInfo() })
+
+	require.True(t, r.addService(si, "svc1", newTestService("remote:9090", 9000, shipyard.ServiceType_REMOTE)))
+	r.removeService(si, "svc1")
+
+	_, ok := r.findByServiceID("svc1")
+	require.False(t, ok)
+
+	_, ok = si.services.get("svc1")
+	require.False(t, ok)
+
+	require.True(t, r.addService(si, "svc2", newTestService("remote:9090", 9000, shipyard.ServiceType_REMOTE)))
+}
+
+func TestRegistryRemoveStreamRemovesIndexes(t *testing.T) {
+	r := newRegistry()
+
+	si, _ := r.findOrAddRemote("remote:9090", func() *streamInfo {
+		si := newStreamInfo()
+		si.addr = "remote:9090"
+		si.session = "abc"
+
+		return si
+	})
+
+	require.True(t, r.addService(si, "svc1", newTestService("remote:9090", 9000, shipyard.ServiceType_REMOTE)))
+	r.remove(si)
+
+	_, ok := r.findByRemoteAddr("remote:9090")
+	require.False(t, ok)
+
+	_, ok = r.findBySession("abc")
+	require.False(t, ok)
+
+	_, ok = r.findByServiceID("svc1")
+	require.False(t, ok)
+
+	require.Len(t, r.all(), 0)
+	require.False(t, r.portInUse(newTestService("remote:9090", 9000, shipyard.ServiceType_REMOTE).detail))
+}
+
+func TestConcurrentExposeAndDestroyServices(t *testing.T) {
+	// nothing is listening on the remote addresses, the services stay pending
+	remotes := []string{}
+	for i := 0; i < 4; i++ {
+		lis, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		remotes = append(remotes, lis.Addr().String())
+		lis.Close()
+	}
+
+	s, _, _ := createServer(t, freeAddress(t), "server_local_1", WithBackoff(time.Minute, time.Minute, 2, 0))
+
+	services := 2000
+	workers := 20
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := w; i < services; i += workers {
+				resp, err := s.ExposeService(context.Background(), &shipyard.ExposeRequest{
+					Service: &shipyard.Service{
+						Name:                fmt.Sprintf("Test %d", i),
+						RemoteConnectorAddr: remotes[i%len(remotes)],
+						SourcePort:          int32(20000 + i),
+						DestinationAddr:     "localhost:8080",
+						Type:                shipyard.ServiceType(i % 2),
+					},
+				})
+				require.NoError(t, err)
+
+				_, ok := s.streams.findByServiceID(resp.Id)
+				require.True(t, ok)
+
+				_, err = s.ListServices(context.Background(), &shipyard.NullMessage{})
+				require.NoError(t, err)
+
+				// keep every other service
+				if i%4 < 2 {
+					continue
+				}
+
+				_, err = s.DestroyService(context.Background(), &shipyard.DestroyRequest{Id: resp.Id})
+				require.NoError(t, err)
+
+				_, ok = s.streams.findByServiceID(resp.Id)
+				require.False(t, ok)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	require.Len(t, s.streams.all(), len(remotes))
+
+	resp, err := s.ListServices(context.Background(), &shipyard.NullMessage{})
+	require.NoError(t, err)
+	require.Len(t, resp.Services, services/2)
+}
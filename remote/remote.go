@@ -1,7 +1,6 @@
 package remote
 
 import (
-	"io"
 	"net"
 
 	"github.com/jumppad-labs/connector/protos/shipyard"
@@ -15,29 +14,106 @@ func (s *Server) newRemoteStream(svr shipyard.RemoteConnection_OpenStreamServer)
 	gc := newGRPCConn(svr)
 	si := newStreamInfo()
 	si.addr = "localhost" // this is an inbound connection
-	si.grpcConn = gc
+	si.inbound = true
+	si.rateLimit.set(s.linkRateLimit)
+	si.setGRPCConn(gc)
+	si.setLinkState(shipyard.LinkState_CONNECTED)
 
 	s.streams.add(si)
 
+	newMessage := make(chan *shipyard.OpenData)
+	newError := make(chan error, 1)
+
+	go func() {
+		for {
+			msg, err := svr.Recv()
+			if err != nil {
+				newError <- err
+				return
+			}
+
+			select {
+			case newMessage <- msg:
+			case <-gc.Done():
+				return
+			}
+		}
+	}()
+
+	heartbeats, stop := s.heartbeatTicker()
+	defer stop()
+
 	for {
 		s.log.Trace(
 			"remote_server",
 			"message", "Waiting for remote client message")
 
-		msg, err := svr.Recv()
+		var msg *shipyard.OpenData
+		var err error
+
+		select {
+		case msg = <-newMessage:
+		case err = <-newError:
+		case <-heartbeats:
+			// heartbeats are only sent on the primary stream, the streams in a pool fail together
+			if si.getGRPCConn() == gc {
+				err = s.sendHeartbeat(si, gc)
+			}
+		case <-gc.Done():
+			s.log.Debug(
+				"remote_server",
+				"message", "Stream closed",
+				"session_id", si.session)
+
+			return nil
+		}
 
 		if err != nil {
+			// the session has been resumed on a new stream or another stream in the pool has
+			// already failed, there is nothing to clean up
+			if !si.closePool(gc) {
+				s.log.Debug(
+					"remote_server",
+					"message", "Stream is no longer part of the session, closing",
+					"session_id", si.session, "error", err)
+
+				return nil
+			}
+
+			si.linkDisconnected(err)
+
+			teardown := func() {
+				// We need to tear down any listeners related to this request and clean up resources
+				// the downstream should attempt to re-establish the connection and resend the expose requests
+				s.teardownConnection(si)
+				s.streams.remove(si)
+			}
+
+			// if the client supports sessions keep the connections open to allow
+			// the client to reconnect and resume the stream
+			if si.session != "" && s.resumeGracePeriod > 0 && !s.Closed() {
+				s.log.Error(
+					"remote_server",
+					"message", "Error receiving message from remote connection, assume connection problem. Waiting for resume",
+					"addr", si.addr, "session_id", si.session, "error", err)
+
+				s.suspendStream(si, teardown)
+				return nil
+			}
+
 			s.log.Error(
 				"remote_server",
 				"message", "Error receiving message from remote connection, assume connection problem. Tearing down connection",
 				"addr", si.addr, "error", err)
 
-			// We need to tear down any listeners related to this request and clean up resources
-			// the downstream should attempt to re-establish the connection and resend the expose requests
-			s.teardownConnection(si)
+			teardown()
 			return nil
 		}
 
+		if msg == nil {
+			continue
+		}
+
 		s.log.Debug(
 			"remote_server",
 			"message", "Received message",
@@ -45,17 +121,40 @@ func (s *Server) newRemoteStream(svr shipyard.RemoteConnection_OpenStreamServer)
 			"connectionID", msg.ConnectionId)
 
 		switch m := msg.Message.(type) {
+		case *shipyard.OpenData_Handshake:
+			err := s.handleHandshakeMessage(si, m)
+			if err != nil {
+				si.closePool(gc)
+				s.teardownConnection(si)
+				s.streams.remove(si)
+
+				return err
+			}
+
+		case *shipyard.OpenData_Session:
+			si = s.handleSessionMessage(si, m)
+
 		case *shipyard.OpenData_Expose:
 			s.handleExposeMessage(si, msg, svr, m)
 
 		case *shipyard.OpenData_Destroy:
 			s.handleDestroyMessage(si, msg)
 
-		case *shipyard.OpenData_Data:
-			s.handleDataMessage(si, msg, svr, m)
+		case *shipyard.OpenData_Data, *shipyard.OpenData_Closed, *shipyard.OpenData_WriteDone,
+			*shipyard.OpenData_ReadDone, *shipyard.OpenData_WindowUpdate, *shipyard.OpenData_Error:
+			s.handleConnectionMessage(si, msg)
+
+		case *shipyard.OpenData_NewConnection:
+			s.handleNewConnectionMessage(si, msg, m)
+
+		case *shipyard.OpenData_Ping:
+			s.handlePingMessage(gc, m)
 
-		case *shipyard.OpenData_Closed:
-			s.handleCloseMessage(si, msg)
+		case *shipyard.OpenData_Pong:
+			s.handlePongMessage(si, m)
+
+		case *shipyard.OpenData_GoingAway:
+			s.handleGoingAwayMessage(si, m)
 		}
 	}
 }
@@ -94,7 +193,7 @@ func (s *Server) handleExposeMessage(si *streamInfo, msg *shipyard.OpenData, svr
 
 		var listener net.Listener
 		var err error
-		listener, err = s.createListenerAndListen(msg.ServiceId, int(m.Expose.Service.SourcePort))
+		listener, err = s.createServiceListener(msg.ServiceId, m.Expose.Service, int(m.Expose.Service.SourcePort))
 		if err != nil {
 			s.log.Error(
 				"remote_server",
@@ -117,6 +216,11 @@ func (s *Server) handleExposeMessage(si *streamInfo, msg *shipyard.OpenData, svr
 			return
 		}
 
+		// the port was allocated for the listener, it is reported to the remote in the status update
+		if m.Expose.Service.SourcePort == 0 && !isUnixSocket(m.Expose.Service.BindAddress) {
+			m.Expose.Service.SourcePort = int32(listenerPort(listener))
+		}
+
 		// create the integration such as a kubernetes service
 		err = s.createIntegration(msg.ServiceId, m.Expose.Service.Name, int(m.Expose.Service.SourcePort))
 		if err != nil {
@@ -139,12 +243,20 @@ func (s *Server) handleExposeMessage(si *streamInfo, msg *shipyard.OpenData, svr
 			return
 		}
 
-		svc.tcpListener = listener
+		svc.setListener(listener)
 	}
 
 	svc.detail = m.Expose.Service
 	svc.detail.Status = shipyard.ServiceStatus_COMPLETE
-	si.services.add(msg.ServiceId, svc)
+
+	// rate limits are enforced by the connector where the service was exposed
+	svc.detail.RateLimit = nil
+	s.streams.addService(si, msg.ServiceId, svc)
+
+	// remote services are dialled by this server
+	if svc.detail.Type == shipyard.ServiceType_REMOTE {
+		s.startHealthChecks(msg.ServiceId, svc)
+	}
 
 	s.log.Trace(
 		"remote_server",
@@ -157,7 +269,8 @@ func (s *Server) handleExposeMessage(si *streamInfo, msg *shipyard.OpenData, svr
 		ServiceId: msg.ServiceId,
 		Message: &shipyard.OpenData_StatusUpdate{
 			StatusUpdate: &shipyard.StatusUpdate{
-				Status: shipyard.ServiceStatus_COMPLETE,
+				Status:     shipyard.ServiceStatus_COMPLETE,
+				SourcePort: m.Expose.Service.SourcePort,
 			},
 		},
 	})
@@ -177,186 +290,6 @@ func (s *Server) handleDestroyMessage(si *streamInfo, msg *shipyard.OpenData) {
 		return
 	}
 
+	s.streams.removeService(si, msg.ServiceId)
 	s.teardownService(svc)
-	si.services.delete(msg.ServiceId)
-}
-
-func (s *Server) handleDataMessage(si *streamInfo, msg *shipyard.OpenData, svr shipyard.RemoteConnection_OpenStreamServer, m *shipyard.OpenData_Data) {
-	s.log.Trace(
-		"remote_server",
-		"message", "Received data message",
-		"message_id", m.Data.Id,
-		"service_id", msg.ServiceId,
-		"msg", msg)
-
-	// get the service for this data
-	svc, ok := si.services.get(msg.ServiceId)
-	if !ok {
-		// if there is no service for this message ignore it
-		s.log.Error(
-			"remote_server",
-			"message", "Service does not exist for message, ignoring",
-			"service_id", msg.ServiceId)
-
-		return
-	}
-
-	// get the connection
-	c, ok := svc.getTCPConnection(msg.ConnectionId)
-
-	// no connection exists, if this is a remote service try to establish a new connection to the upstream service
-	// otherwise ignore as the connection should have been created by the local listener
-	if !ok {
-		if svc.detail.Type == shipyard.ServiceType_LOCAL {
-			s.log.Error(
-				"remote_server",
-				"message", "No connection for data, ignore message",
-				"port", svc.detail.SourcePort,
-				"serviceID", msg.ServiceId,
-				"connectionID", msg.ConnectionId)
-
-			return
-		}
-
-		// open a new connection
-		s.log.Trace(
-			"remote_server",
-			"message", "Create new upstream connection for data",
-			"service_id", msg.ServiceId,
-			"connection_id", msg.ConnectionId,
-			"addr", svc.detail.DestinationAddr)
-
-		addr, err := s.lookupIntegration(svc.detail.DestinationAddr)
-		if err != nil {
-			s.log.Error(
-				"local_server",
-				"message", "Unable to find address for upstream",
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId,
-				"addr", svc.detail.DestinationAddr,
-				"error", err,
-			)
-
-			si.grpcConn.Send(
-				&shipyard.OpenData{
-					ServiceId:    msg.ServiceId,
-					ConnectionId: msg.ConnectionId,
-					Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
-				},
-			)
-			return
-		}
-
-		// get the service address
-		newConn, err := net.Dial("tcp", addr)
-		if err != nil {
-			s.log.Error(
-				"remote_server",
-				"message", "Unable to create connection to upstream",
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId,
-				"addr", addr)
-
-			svr.Send(
-				&shipyard.OpenData{
-					ServiceId:    msg.ServiceId,
-					ConnectionId: msg.ConnectionId,
-					Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
-				},
-			)
-			return
-		}
-
-		c = newBufferedConn(newConn)
-		c.id = msg.ConnectionId
-		svc.setTCPConnection(msg.ConnectionId, c)
-
-		// start read handler and don't block
-		go s.handleConnectionRead(msg.ServiceId, si, svc, c)
-	}
-
-	s.log.Trace(
-		"remote_server",
-		"message", "Writing data to local connection",
-		"service_id", msg.ServiceId,
-		"connection_id", msg.ConnectionId)
-
-	i, err := c.Write(m.Data.Data)
-	if err != nil {
-		if err == io.EOF {
-			s.log.Debug(
-				"remote_server",
-				"message", "Connection closed",
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId)
-		} else {
-			s.log.Error(
-				"remote_server",
-				"message", "Error writing to connection",
-				"service_id", msg.ServiceId,
-				"connection_id", msg.ConnectionId,
-				"error", err,
-			)
-
-		}
-
-		// send closed message
-		si.grpcConn.Send(
-			&shipyard.OpenData{
-				ServiceId:    msg.ServiceId,
-				ConnectionId: msg.ConnectionId,
-				Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
-			},
-		)
-	}
-
-	s.log.Trace(
-		"remote_server",
-		"message", "Data written to local connection",
-		"service_id", msg.ServiceId,
-		"connection_id", msg.ConnectionId)
-
-	// if the size of the data is less than the max buffer
-	// all writing has been completed for the connection switch to read mode
-	if i < MessageSize {
-		s.log.Trace(
-			"remote_server",
-			"message", "All data written to local connection, start read",
-			"data_written", i,
-			"message_size", MessageSize,
-			"service_id", msg.ServiceId,
-			"connection_id", msg.ConnectionId)
-	}
-}
-
-func (s *Server) handleCloseMessage(si *streamInfo, msg *shipyard.OpenData) {
-	s.log.Trace(
-		"local_server",
-		"message", "Received close connection message",
-		"service_id", msg.ServiceId,
-		"connection_id", msg.ConnectionId)
-
-	svc, _ := si.services.get(msg.ServiceId)
-	if svc == nil {
-		s.log.Error(
-			"local_server",
-			"message", "Service does not exist",
-			"service_id", msg.ServiceId,
-			"connection_id", msg.ConnectionId)
-
-		return
-	}
-
-	c, ok := svc.getTCPConnection(msg.ConnectionId)
-	if ok {
-		s.log.Trace(
-			"local_server",
-			"message", "Closing connection",
-			"service_id", msg.ServiceId,
-			"connection_id", msg.ConnectionId)
-
-		// we have a connection close it
-		c.Close()
-		svc.removeTCPConnection(msg.ConnectionId)
-	}
 }
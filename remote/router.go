@@ -0,0 +1,318 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// httpRouter serves the HTTP requests received by the listener for a service with match
+// rules, requests which match are sent over the stream to the destination for the service
+// and all other requests are sent to the default destination
+type httpRouter struct {
+	log       hclog.Logger
+	serviceID string
+	match     *shipyard.Match
+	conns     *connListener
+	server    *http.Server
+	stream    *httputil.ReverseProxy
+	fallback  *httputil.ReverseProxy
+}
+
+// newHTTPRouter creates a router for the connections accepted by the listener l, HTTP/1.1
+// and HTTP/2 without TLS are supported
+func (s *Server) newHTTPRouter(serviceID string, l net.Listener, detail *shipyard.Service) *httpRouter {
+	r := &httpRouter{
+		log:       s.log,
+		serviceID: serviceID,
+		match:     detail.Match,
+		conns:     newConnListener(l.Addr()),
+	}
+
+	r.stream = r.proxy(func(ctx context.Context) (net.Conn, error) {
+		return s.dialServiceStream(serviceID, l)
+	})
+
+	timeout := s.serviceDialTimeout(detail)
+	network, addr := dialAddress(shipyard.ServiceProtocol_TCP, detail.DefaultDestinationAddr)
+
+	r.fallback = r.proxy(func(ctx context.Context) (net.Conn, error) {
+		d := net.Dialer{Timeout: timeout}
+		return d.DialContext(ctx, network, addr)
+	})
+
+	r.server = &http.Server{
+		Handler:  h2c.NewHandler(r, &http2.Server{}),
+		ErrorLog: s.log.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true}),
+	}
+
+	go r.server.Serve(r.conns)
+
+	return r
+}
+
+// proxy returns a reverse proxy which sends requests to the connections opened by dial
+func (r *httpRouter) proxy(dial func(ctx context.Context) (net.Conn, error)) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = req.Host
+
+			// HTTP/1.0 requests do not need a host
+			if req.URL.Host == "" {
+				req.URL.Host = "localhost"
+			}
+		},
+		Transport:     newProtocolTransport(dial),
+		FlushInterval: -1,
+		ErrorHandler: func(rw http.ResponseWriter, req *http.Request, err error) {
+			r.log.Debug(
+				"router",
+				"message", "Unable to send request to destination",
+				"service_id", r.serviceID,
+				"path", req.URL.Path,
+				"error", err)
+
+			rw.WriteHeader(http.StatusBadGateway)
+		},
+	}
+}
+
+// ServeHTTP implements the http.Handler interface
+func (r *httpRouter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	matched := matchRequest(r.match, req)
+
+	r.log.Trace(
+		"router",
+		"message", "Route request",
+		"service_id", r.serviceID,
+		"path", req.URL.Path,
+		"matched", matched)
+
+	if matched {
+		r.stream.ServeHTTP(rw, req)
+		return
+	}
+
+	r.fallback.ServeHTTP(rw, req)
+}
+
+// serve hands a connection accepted by the listener to the HTTP server
+func (r *httpRouter) serve(conn net.Conn) {
+	r.conns.serve(conn)
+}
+
+// close stops the HTTP server and closes all connections
+func (r *httpRouter) close() {
+	r.server.Close()
+}
+
+// dialServiceStream opens a connection over the stream to the destination for the service,
+// the connection is handled in the same way as connections accepted by the listener
+func (s *Server) dialServiceStream(serviceID string, l net.Listener) (net.Conn, error) {
+	si, svc, ok := s.listenerService(serviceID)
+	if !ok {
+		return nil, fmt.Errorf("service %s does not exist", serviceID)
+	}
+
+	client, conn := net.Pipe()
+	if !s.acceptConnection(serviceID, si, svc, l, conn) {
+		client.Close()
+		return nil, fmt.Errorf("unable to open a connection for service %s", serviceID)
+	}
+
+	return client, nil
+}
+
+// protocolTransport sends HTTP/2 requests using HTTP/2 without TLS and all other requests
+// using HTTP/1.1, the destination receives the protocol used by the client
+type protocolTransport struct {
+	h1 *http.Transport
+	h2 *http2.Transport
+}
+
+func newProtocolTransport(dial func(ctx context.Context) (net.Conn, error)) *protocolTransport {
+	return &protocolTransport{
+		h1: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dial(ctx)
+			},
+			DisableCompression: true,
+		},
+		h2: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return dial(ctx)
+			},
+			DisableCompression: true,
+		},
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *protocolTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.ProtoMajor == 2 {
+		return t.h2.RoundTrip(r)
+	}
+
+	return t.h1.RoundTrip(r)
+}
+
+// connListener is a net.Listener for connections which have been accepted by another listener
+type connListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{addr: addr, conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+// serve returns the connection from Accept, the connection is closed when the listener
+// has been closed
+func (c *connListener) serve(conn net.Conn) {
+	select {
+	case c.conns <- conn:
+	case <-c.done:
+		conn.Close()
+	}
+}
+
+// Accept implements the net.Listener interface
+func (c *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-c.conns:
+		return conn, nil
+	case <-c.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements the net.Listener interface
+func (c *connListener) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return nil
+}
+
+// Addr implements the net.Listener interface
+func (c *connListener) Addr() net.Addr {
+	return c.addr
+}
+
+// matchRequest returns true when the request matches the http and grpc rules
+func matchRequest(m *shipyard.Match, r *http.Request) bool {
+	if h := m.GetHttp(); h != nil && !matchHTTP(h, r) {
+		return false
+	}
+
+	if g := m.GetGrpc(); g != nil && !matchGRPC(g, r) {
+		return false
+	}
+
+	return true
+}
+
+func matchHTTP(h *shipyard.Http, r *http.Request) bool {
+	if h.PathExact != "" && r.URL.Path != h.PathExact {
+		return false
+	}
+
+	if !strings.HasPrefix(r.URL.Path, h.PathPrefix) {
+		return false
+	}
+
+	for _, hdr := range h.Headers {
+		values := r.Header.Values(hdr.Name)
+
+		// the host is not included in the request headers
+		if strings.EqualFold(hdr.Name, "host") {
+			values = []string{r.Host}
+		}
+
+		if !matchHeader(hdr, values) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchHeader returns true when any of the values for the header match
+func matchHeader(h *shipyard.Header, values []string) bool {
+	for _, v := range values {
+		switch {
+		case h.Exact != "":
+			if v == h.Exact {
+				return true
+			}
+		case h.Prefix != "":
+			if strings.HasPrefix(v, h.Prefix) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchGRPC(g *shipyard.Grpc, r *http.Request) bool {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		return false
+	}
+
+	// the path for gRPC requests is /package.Service/Method
+	service, method, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	return (g.Service == "" || g.Service == service) && (g.Method == "" || g.Method == method)
+}
+
+// validateMatch returns an error when the match rules or the default destination for the
+// service are not valid
+func validateMatch(detail *shipyard.Service) error {
+	m := detail.Match
+	if m == nil {
+		if detail.DefaultDestinationAddr != "" {
+			return fmt.Errorf("default destination must only be set with match rules")
+		}
+
+		return nil
+	}
+
+	if detail.Protocol != shipyard.ServiceProtocol_TCP {
+		return fmt.Errorf("match rules are only supported for TCP services")
+	}
+
+	if m.Http == nil && m.Grpc == nil {
+		return fmt.Errorf("match must contain http or grpc rules")
+	}
+
+	for _, h := range m.GetHttp().GetHeaders() {
+		if h.Name == "" {
+			return fmt.Errorf("header name must not be empty")
+		}
+
+		if h.Exact != "" && h.Prefix != "" {
+			return fmt.Errorf("header %s must only set exact or prefix", h.Name)
+		}
+	}
+
+	if detail.DefaultDestinationAddr == "" {
+		return fmt.Errorf("default destination is required with match rules")
+	}
+
+	return validateDestination(detail.DefaultDestinationAddr)
+}
@@ -0,0 +1,199 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startHTTPServer starts a server which replies with the name and the protocol of the
+// request, HTTP/2 is supported without TLS
+func startHTTPServer(t *testing.T, name string) string {
+	h := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(rw, "%s %s", name, r.Proto)
+	})
+
+	ts := httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+	t.Cleanup(ts.Close)
+
+	return ts.Listener.Addr().String()
+}
+
+func requireResponse(t *testing.T, c *http.Client, req *http.Request, body string) {
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	d, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, body, string(d))
+}
+
+func TestMatchRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   *shipyard.Match
+		path    string
+		headers map[string]string
+		matched bool
+	}{
+		{
+			name:    "exact header",
+			match:   &shipyard.Match{Http: &shipyard.Http{Headers: []*shipyard.Header{{Name: "x-dev-user", Exact: "alice"}}}},
+			headers: map[string]string{"X-Dev-User": "alice"},
+			matched: true,
+		},
+		{
+			name:    "exact header with other value",
+			match:   &shipyard.Match{Http: &shipyard.Http{Headers: []*shipyard.Header{{Name: "x-dev-user", Exact: "alice"}}}},
+			headers: map[string]string{"X-Dev-User": "bob"},
+		},
+		{
+			name:    "header prefix",
+			match:   &shipyard.Match{Http: &shipyard.Http{Headers: []*shipyard.Header{{Name: "x-dev-user", Prefix: "al"}}}},
+			headers: map[string]string{"X-Dev-User": "alice"},
+			matched: true,
+		},
+		{
+			name:    "header present",
+			match:   &shipyard.Match{Http: &shipyard.Http{Headers: []*shipyard.Header{{Name: "x-dev-user"}}}},
+			headers: map[string]string{"X-Dev-User": "bob"},
+			matched: true,
+		},
+		{
+			name:  "header missing",
+			match: &shipyard.Match{Http: &shipyard.Http{Headers: []*shipyard.Header{{Name: "x-dev-user"}}}},
+		},
+		{
+			name:    "path prefix",
+			match:   &shipyard.Match{Http: &shipyard.Http{PathPrefix: "/api/"}},
+			path:    "/api/users",
+			matched: true,
+		},
+		{
+			name:  "path exact",
+			match: &shipyard.Match{Http: &shipyard.Http{PathExact: "/api"}},
+			path:  "/api/users",
+		},
+		{
+			name: "header and path",
+			match: &shipyard.Match{Http: &shipyard.Http{
+				PathPrefix: "/api/",
+				Headers:    []*shipyard.Header{{Name: "x-dev-user", Exact: "alice"}},
+			}},
+			path:    "/web",
+			headers: map[string]string{"X-Dev-User": "alice"},
+		},
+		{
+			name:    "grpc method",
+			match:   &shipyard.Match{Grpc: &shipyard.Grpc{Service: "helloworld.Greeter", Method: "SayHello"}},
+			path:    "/helloworld.Greeter/SayHello",
+			headers: map[string]string{"Content-Type": "application/grpc"},
+			matched: true,
+		},
+		{
+			name:    "grpc other method",
+			match:   &shipyard.Match{Grpc: &shipyard.Grpc{Service: "helloworld.Greeter", Method: "SayHello"}},
+			path:    "/helloworld.Greeter/SayGoodbye",
+			headers: map[string]string{"Content-Type": "application/grpc"},
+		},
+		{
+			name:  "grpc without content type",
+			match: &shipyard.Match{Grpc: &shipyard.Grpc{Service: "helloworld.Greeter"}},
+			path:  "/helloworld.Greeter/SayHello",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.path == "" {
+				tc.path = "/"
+			}
+
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			require.Equal(t, tc.matched, matchRequest(tc.match, r))
+		})
+	}
+}
+
+func TestExposeServiceWithInvalidMatchReturnsError(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	tests := []*shipyard.Service{
+		{Match: &shipyard.Match{}, DefaultDestinationAddr: "localhost:8080"},
+		{Match: &shipyard.Match{Http: &shipyard.Http{PathPrefix: "/api"}}},
+		{Match: &shipyard.Match{Http: &shipyard.Http{Headers: []*shipyard.Header{{Exact: "alice"}}}}, DefaultDestinationAddr: "localhost:8080"},
+		{DefaultDestinationAddr: "localhost:8080"},
+	}
+
+	for _, svc := range tests {
+		svc.Name = "Test 1"
+		svc.RemoteConnectorAddr = servers[1].Address
+		svc.DestinationAddr = "localhost:8080"
+
+		_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{Service: svc})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestExposeServiceWithMatchRoutesRequests(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                   "Test 1",
+			RemoteConnectorAddr:    servers[1].Address,
+			DestinationAddr:        startHTTPServer(t, "laptop"),
+			DefaultDestinationAddr: startHTTPServer(t, "cluster"),
+			Type:                   shipyard.ServiceType_LOCAL,
+			Match: &shipyard.Match{
+				Http: &shipyard.Http{Headers: []*shipyard.Header{{Name: "x-dev-user", Exact: "alice"}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	url := fmt.Sprintf("http://localhost:%d/", waitForAllocatedPort(t, servers[0].Server, resp.Id))
+
+	h1 := &http.Client{}
+	h2 := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	matched, _ := http.NewRequest(http.MethodGet, url, nil)
+	matched.Header.Set("x-dev-user", "alice")
+
+	other, _ := http.NewRequest(http.MethodGet, url, nil)
+	other.Header.Set("x-dev-user", "bob")
+
+	requireResponse(t, h1, matched, "laptop HTTP/1.1")
+	requireResponse(t, h1, other, "cluster HTTP/1.1")
+
+	// requests on the same connection are routed separately
+	requireResponse(t, h1, matched, "laptop HTTP/1.1")
+
+	requireResponse(t, h2, matched, "laptop HTTP/2.0")
+	requireResponse(t, h2, other, "cluster HTTP/2.0")
+}
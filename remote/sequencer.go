@@ -0,0 +1,74 @@
+package remote
+
+import (
+	"fmt"
+)
+
+// maxPendingMessages is the number of out of order messages which will be
+// buffered for a connection before the sequence is considered broken, when a link
+// uses multiple streams messages for a connection may arrive on different streams
+const maxPendingMessages = 1024
+
+// sequencer orders the data messages received for a connection using the
+// message id set by the sender. Messages which arrive out of order are buffered
+// until the missing messages arrive, duplicates and gaps which can not be
+// filled are returned as errors. The ids wrap around on long lived connections.
+type sequencer struct {
+	next       int32
+	pending    map[int32][]byte
+	maxPending int
+}
+
+func newSequencer() *sequencer {
+	return &sequencer{pending: map[int32][]byte{}, maxPending: maxPendingMessages}
+}
+
+// push adds a message to the sequencer and returns the data which can now be
+// delivered in order, this may be empty when the message is ahead of the sequence
+func (s *sequencer) push(id int32, data []byte) ([][]byte, error) {
+	if idBefore(id, s.next) {
+		return nil, fmt.Errorf("duplicate message %d, next expected message is %d", id, s.next)
+	}
+
+	if id != s.next {
+		if _, ok := s.pending[id]; ok {
+			return nil, fmt.Errorf("duplicate message %d, message is already buffered", id)
+		}
+
+		if int(id-s.next) > s.maxPending || len(s.pending) >= s.maxPending {
+			return nil, fmt.Errorf("gap in message sequence, expected message %d, received %d", s.next, id)
+		}
+
+		s.pending[id] = data
+		return nil, nil
+	}
+
+	ready := [][]byte{data}
+	s.next++
+
+	// release any buffered messages which are now in sequence
+	for {
+		d, ok := s.pending[s.next]
+		if !ok {
+			break
+		}
+
+		delete(s.pending, s.next)
+		ready = append(ready, d)
+		s.next++
+	}
+
+	return ready, nil
+}
+
+// nextID returns the id of the next message expected in the sequence
+func (s *sequencer) nextID() int32 {
+	return s.next
+}
+
+// idBefore returns true when the message id a comes before b, ids are compared using
+// serial number arithmetic so that the order is kept when the id wraps around after
+// 2^31 messages
+func idBefore(a, b int32) bool {
+	return a-b < 0
+}
@@ -0,0 +1,96 @@
+package remote
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequencerReturnsInOrderMessages(t *testing.T) {
+	s := newSequencer()
+
+	d, err := s.push(0, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a")}, d)
+
+	d, err = s.push(1, []byte("b"))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("b")}, d)
+}
+
+func TestSequencerReordersMessages(t *testing.T) {
+	s := newSequencer()
+
+	d, err := s.push(2, []byte("c"))
+	require.NoError(t, err)
+	require.Empty(t, d)
+
+	d, err = s.push(1, []byte("b"))
+	require.NoError(t, err)
+	require.Empty(t, d)
+
+	d, err = s.push(0, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, d)
+}
+
+func TestSequencerReturnsErrorOnDuplicate(t *testing.T) {
+	s := newSequencer()
+
+	_, err := s.push(0, []byte("a"))
+	require.NoError(t, err)
+
+	_, err = s.push(0, []byte("a"))
+	require.Error(t, err)
+}
+
+func TestSequencerReturnsErrorOnBufferedDuplicate(t *testing.T) {
+	s := newSequencer()
+
+	_, err := s.push(1, []byte("b"))
+	require.NoError(t, err)
+
+	_, err = s.push(1, []byte("b"))
+	require.Error(t, err)
+}
+
+func TestSequencerReturnsErrorOnGap(t *testing.T) {
+	s := newSequencer()
+
+	_, err := s.push(maxPendingMessages+1, []byte("a"))
+	require.Error(t, err)
+}
+
+func TestSequencerReturnsErrorWhenPendingFull(t *testing.T) {
+	s := newSequencer()
+	s.maxPending = 2
+
+	_, err := s.push(1, []byte("b"))
+	require.NoError(t, err)
+
+	_, err = s.push(2, []byte("c"))
+	require.NoError(t, err)
+
+	// the next message would exceed the buffer
+	_, err = s.push(3, []byte("d"))
+	require.Error(t, err)
+}
+
+func TestSequencerOrdersMessagesWhenIDWraps(t *testing.T) {
+	s := newSequencer()
+	s.next = math.MaxInt32
+
+	d, err := s.push(math.MinInt32, []byte("b"))
+	require.NoError(t, err)
+	require.Empty(t, d)
+
+	d, err = s.push(math.MaxInt32, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, d)
+	require.Equal(t, int32(math.MinInt32+1), s.nextID())
+
+	// messages from before the wrap are duplicates
+	_, err = s.push(math.MaxInt32, []byte("a"))
+	require.Error(t, err)
+}
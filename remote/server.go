@@ -4,8 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"net"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,12 +15,15 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-const MessageSize = 4096 // 4k data payload
+const MessageSize = 4096 // default data payload, 4k
+
+// MaxMessageSize is the largest data payload which can be configured for a server or service
+const MaxMessageSize = 1 << 20 // 1MB
 
 type Server struct {
 	log hclog.Logger
 	// Collection which listeners and tcp connections for a Server stream
-	streams streams
+	streams *registry
 
 	certPool *x509.CertPool
 	cert     *tls.Certificate
@@ -30,10 +32,26 @@ type Server struct {
 	cf  context.CancelFunc
 
 	integration integrations.Integration
+
+	resumeGracePeriod time.Duration
+	linkStreams       int // number of streams used for a link to a remote server
+	udpIdleTimeout    time.Duration
+	heartbeatInterval time.Duration
+	heartbeatMisses   int
+	backoff           backoff
+	draining          atomic.Bool
+	messageSize       int // maximum data payload read from a connection for a single message
+	portRange         portRange
+	bindAddress       string              // address listeners bind to when the service does not set one
+	dialTimeout       time.Duration       // time to wait for a connection to a destination when the service does not set one
+	linkRateLimit     *shipyard.RateLimit // bandwidth limit for new links, nil is unlimited
+	vhosts            *virtualHosts       // listeners shared by services with virtual hosts
+	proxyAllow        *destinationRules   // destinations proxy services can dial, nil allows none
+	proxyDeny         *destinationRules   // destinations proxy services can not dial even when allowed
 }
 
 // New creates a new gRPC remote connector server
-func New(l hclog.Logger, certPool *x509.CertPool, cert *tls.Certificate, integr integrations.Integration) *Server {
+func New(l hclog.Logger, certPool *x509.CertPool, cert *tls.Certificate, integr integrations.Integration, opts ...Option) *Server {
 	if certPool != nil && cert != nil {
 		l.Info("Creating new Server with mTLS")
 	} else {
@@ -42,14 +60,31 @@ func New(l hclog.Logger, certPool *x509.CertPool, cert *tls.Certificate, integr
 
 	ctx, cf := context.WithCancel(context.Background())
 
-	return &Server{
-		l,
-		streams{},
-		certPool,
-		cert,
-		ctx, cf,
-		integr,
+	s := &Server{
+		log:               l,
+		streams:           newRegistry(),
+		certPool:          certPool,
+		cert:              cert,
+		ctx:               ctx,
+		cf:                cf,
+		integration:       integr,
+		resumeGracePeriod: DefaultResumeGracePeriod,
+		linkStreams:       1,
+		udpIdleTimeout:    DefaultUDPIdleTimeout,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		heartbeatMisses:   DefaultHeartbeatMisses,
+		backoff:           defaultBackoff,
+		messageSize:       MessageSize,
+		bindAddress:       DefaultBindAddress,
+		dialTimeout:       DefaultDialTimeout,
+		vhosts:            newVirtualHosts(),
+	}
+
+	for _, o := range opts {
+		o(s)
 	}
+
+	return s
 }
 
 // OpenStream is a called by a remote server to open a bidirectional stream between two
@@ -64,37 +99,145 @@ func (s *Server) ExposeService(ctx context.Context, r *shipyard.ExposeRequest) (
 	id := uuid.New().String()
 	s.log.Info("Expose Service", "req", r, "service_id", id)
 
-	svc := newService()
-	svc.detail = r.Service
-	svc.detail.Status = shipyard.ServiceStatus_PENDING
+	if s.Draining() {
+		return nil, status.Errorf(codes.Unavailable, "Unable to expose service, server is shutting down")
+	}
+
+	if r.Service.SourcePort < 0 || r.Service.SourcePort > 65535 {
+		return nil, status.Errorf(codes.InvalidArgument, "Source port must be between 0 and 65535, 0 allocates a free port")
+	}
 
-	// validate that there is not already a service
-	for _, s := range s.streams {
-		if s.services.contains(svc) {
-			return nil, status.Errorf(codes.InvalidArgument, "Unable to expose remote service on port %d, port already in use", r.Service.SourcePort)
+	if err := ValidateBindAddress(r.Service.BindAddress); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid bind address: %s", err)
+	}
+
+	// the destinations for proxy services are requested by the client
+	if r.Service.Proxy == shipyard.ProxyProtocol_NO_PROXY {
+		for _, d := range destinations(r.Service) {
+			if err := validateDestination(d); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Invalid destination %s: %s", d, err)
+			}
+
+			if isUnixSocket(d) && r.Service.Protocol != shipyard.ServiceProtocol_TCP {
+				return nil, status.Errorf(codes.InvalidArgument, "Unix sockets are only supported for TCP services")
+			}
 		}
 	}
 
-	// find a remote connection
-	si, ok := s.streams.findByRemoteAddr(r.Service.RemoteConnectorAddr)
-	if !ok {
-		si = newStreamInfo()
-		si.addr = r.Service.RemoteConnectorAddr
+	if isUnixSocket(r.Service.BindAddress) && r.Service.Protocol != shipyard.ServiceProtocol_TCP {
+		return nil, status.Errorf(codes.InvalidArgument, "Unix sockets are only supported for TCP services")
+	}
 
-		// add the new stream to the collection
-		s.streams.add(si)
+	if r.Service.HealthCheck != nil && r.Service.Protocol != shipyard.ServiceProtocol_TCP {
+		return nil, status.Errorf(codes.InvalidArgument, "Health checks are only supported for TCP services")
 	}
 
-	// add the service to the connection
+	if isUnixSocket(r.Service.BindAddress) && r.Service.SourcePort != 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Source port must not be set for services listening on a Unix socket")
+	}
+
+	if r.Service.MessageSize < 0 || r.Service.MessageSize > MaxMessageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "Message size must be between 0 and %d bytes", MaxMessageSize)
+	}
+
+	if r.Service.DialTimeout < 0 || r.Service.DialRetries < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Dial timeout and dial retries must not be negative")
+	}
+
+	if r.Service.IdleTimeout < 0 || r.Service.MaxLifetime < 0 || r.Service.MaxConnections < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Idle timeout, max lifetime and max connections must not be negative")
+	}
+
+	if err := validateRateLimit(r.Service.RateLimit); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid rate limit: %s", err)
+	}
+
+	if err := validateMatch(r.Service); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid match: %s", err)
+	}
+
+	if err := validateHosts(r.Service); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid hosts: %s", err)
+	}
+
+	if err := validateProxy(r.Service); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid proxy: %s", err)
+	}
+
+	if r.Service.ProxyHeader != shipyard.ProxyHeader_NO_PROXY_HEADER && r.Service.Protocol != shipyard.ServiceProtocol_TCP {
+		return nil, status.Errorf(codes.InvalidArgument, "PROXY protocol headers are only supported for TCP services")
+	}
+
+	for i, h := range r.Service.Hosts {
+		r.Service.Hosts[i] = normalizeHost(h)
+	}
+
+	svc := newService()
+	svc.detail = r.Service
+	svc.detail.Status = shipyard.ServiceStatus_PENDING
 	svc.detail.Id = id
-	si.services.add(id, svc)
+
+	// find a remote connection, or add a new one to the collection
+	si, _ := s.streams.findOrAddRemote(r.Service.RemoteConnectorAddr, func() *streamInfo {
+		si := newStreamInfo()
+		si.addr = r.Service.RemoteConnectorAddr
+		si.session = uuid.New().String()
+		si.rateLimit.set(s.linkRateLimit)
+
+		return si
+	})
+
+	// add the service to the connection, validating that there is not already a service
+	if !s.streams.addService(si, id, svc) {
+		return nil, status.Errorf(codes.InvalidArgument, "Unable to expose remote service on port %d, port already in use", r.Service.SourcePort)
+	}
+
+	// local services are dialled by this server
+	if svc.detail.Type == shipyard.ServiceType_LOCAL {
+		s.startHealthChecks(id, svc)
+	}
 
 	// establish a connection to the remote endpoint and setup listeners
-	go s.handleReconnection(si)
+	go s.handleReconnection(si, true)
 
 	return &shipyard.ExposeResponse{Id: id}, nil
 }
 
+// SetRateLimit is the public gRPC API method to change the bandwidth limit for a service,
+// or for the link to a remote connector when the service id is not set
+func (s *Server) SetRateLimit(ctx context.Context, r *shipyard.RateLimitRequest) (*shipyard.NullMessage, error) {
+	s.log.Info("Set rate limit", "req", r)
+
+	if err := validateRateLimit(r.Limit); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid rate limit: %s", err)
+	}
+
+	if r.ServiceId != "" {
+		si, ok := s.streams.findByServiceID(r.ServiceId)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "Service with ID: %s, does not exist", r.ServiceId)
+		}
+
+		svc, ok := si.services.get(r.ServiceId)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "Service with ID: %s, does not exist", r.ServiceId)
+		}
+
+		svc.setRateLimit(r.Limit)
+
+		return &shipyard.NullMessage{}, nil
+	}
+
+	si, ok := s.streams.findByRemoteAddr(r.RemoteConnectorAddr)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Link to remote connector: %s, does not exist", r.RemoteConnectorAddr)
+	}
+
+	si.rateLimit.set(r.Limit)
+
+	return &shipyard.NullMessage{}, nil
+}
+
 // DestroyService is the public gRPC API method to remove a service
 func (s *Server) DestroyService(ctx context.Context, dr *shipyard.DestroyRequest) (*shipyard.NullMessage, error) {
 	s.log.Info("Destroy service", "id", dr.Id)
@@ -106,17 +249,20 @@ func (s *Server) DestroyService(ctx context.Context, dr *shipyard.DestroyRequest
 		return nil, status.Errorf(codes.NotFound, "Service with ID: %s, does not exist", dr.Id)
 	}
 
-	svc, _ := si.services.get(dr.Id)
+	svc, ok := si.services.get(dr.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Service with ID: %s, does not exist", dr.Id)
+	}
+
+	// delete the service
+	s.streams.removeService(si, dr.Id)
 	s.teardownService(svc)
 
 	// send a message to the remote end that the service has been removed
-	if si.grpcConn != nil {
-		si.grpcConn.Send(&shipyard.OpenData{ServiceId: dr.Id, Message: &shipyard.OpenData_Destroy{Destroy: &shipyard.DestroyRequest{Id: dr.Id}}})
+	if gc := si.getGRPCConn(); gc != nil {
+		gc.Send(&shipyard.OpenData{ServiceId: dr.Id, Message: &shipyard.OpenData_Destroy{Destroy: &shipyard.DestroyRequest{Id: dr.Id}}})
 	}
 
-	// delete the service
-	si.services.delete(dr.Id)
-
 	return &shipyard.NullMessage{}, nil
 }
 
@@ -126,9 +272,25 @@ func (s *Server) ListServices(ctx context.Context, m *shipyard.NullMessage) (*sh
 
 	services := []*shipyard.Service{}
 
-	for _, stream := range s.streams {
+	for _, stream := range s.streams.all() {
+		link := stream.linkStatus()
+
 		stream.services.iterate(func(id string, svc *service) bool {
-			services = append(services, svc.detail)
+			detail := svc.details()
+			detail.Link = link
+			detail.Endpoints = svc.endpoints()
+
+			if detail.Compression != shipyard.Compression_NONE {
+				detail.CompressionStats = svc.compression.status()
+			}
+
+			detail.DialStats = svc.dials.status()
+
+			if detail.RateLimit != nil {
+				detail.ThrottleStats = svc.rateLimiter().status()
+			}
+
+			services = append(services, detail)
 
 			// return true to continue iterating
 			return true
@@ -156,47 +318,33 @@ func (s *Server) Shutdown() {
 
 	// close all listeners
 	s.log.Info("Closing all TCPListeners and Connections")
-	for _, t := range s.streams {
-		if t != nil {
-			s.teardownConnection(t)
-			t.closeGRPCConn()
-		}
+	for _, t := range s.streams.all() {
+		s.teardownConnection(t)
+		t.closeGRPCConn()
 	}
 }
 
-var connectionBackoff = 10 * time.Second
-
 func (s *Server) teardownConnection(si *streamInfo) {
 	si.services.iterate(func(id string, svc *service) bool {
 		// close any open connections
 		s.teardownService(svc)
-		svc.detail.Status = shipyard.ServiceStatus_PENDING
+		svc.setStatus(shipyard.ServiceStatus_PENDING)
 
 		return true
 	})
 }
 
-var teardownSync = sync.Mutex{}
-
 func (s *Server) teardownService(svc *service) {
-	teardownSync.Lock()
-	defer teardownSync.Unlock()
+	svc.teardownMutex.Lock()
+	defer svc.teardownMutex.Unlock()
 
 	// close any open TCP connections
-	svc.tcpConnections.Range(func(k interface{}, v interface{}) bool {
-		conn := v.(net.Conn)
-		conn.Close()
-
-		svc.removeTCPConnection(k.(string))
-
-		return true
-	})
+	svc.closeTCPConnections()
 
 	// close the listener
-	if svc.tcpListener != nil {
-		s.log.Debug("Closing TCP Listener", "addr", svc.tcpListener.Addr())
-		svc.tcpListener.Close()
-		svc.tcpListener = nil
+	if l := svc.setListener(nil); l != nil {
+		s.log.Debug("Closing TCP Listener", "addr", l.Addr())
+		l.Close()
 	}
 
 	// are there any integrations to remove
@@ -207,7 +355,8 @@ func (s *Server) teardownService(svc *service) {
 }
 
 func (s *Server) createIntegration(id, name string, port int) error {
-	if s.integration != nil {
+	// services listening on a Unix socket do not have a port to register
+	if s.integration != nil && port > 0 {
 		name = integrations.SanitizeName(name)
 		return s.integration.Register(id, name, port, port)
 	}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,7 +20,7 @@ import (
 	"google.golang.org/grpc"
 )
 
-func createServer(t *testing.T, addr, name string) (*Server, *integrations.Mock, func()) {
+func createServer(t testing.TB, addr, name string, opts ...Option) (*Server, *integrations.Mock, func()) {
 	//certificate, err := tls.LoadX509KeyPair("/tmp/certs/leaf.cert", "/tmp/certs/leaf.key")
 	//require.NoError(t, err)
 
@@ -39,14 +39,16 @@ func createServer(t *testing.T, addr, name string) (*Server, *integrations.Mock,
 	mi.On("LookupAddress", mock.Anything).Return("", nil)
 
 	output := ioutil.Discard
+	level := hclog.Error
 
 	if os.Getenv("DEBUG") == "true" {
 		output = os.Stdout
+		level = hclog.Trace
 	}
 
 	l := hclog.New(
 		&hclog.LoggerOptions{
-			Level:  hclog.Trace,
+			Level:  level,
 			Name:   name,
 			Output: output,
 		},
@@ -54,7 +56,7 @@ func createServer(t *testing.T, addr, name string) (*Server, *integrations.Mock,
 
 	// start the gRPC server
 	//s := New(l, certPool, &certificate, mi)
-	s := New(l, nil, nil, mi)
+	s := New(l, nil, nil, mi, opts...)
 
 	//creds := credentials.NewTLS(&tls.Config{
 	//	ClientAuth:   tls.RequireAndVerifyClientCert,
@@ -117,13 +119,25 @@ type serverStruct struct {
 	Cleanup     func()
 }
 
-func setupServers(t *testing.T) (string, *string, []*serverStruct) {
-	randSource := rand.NewSource(time.Now().UnixNano())
-	randGen := rand.New(randSource)
+// freePort returns a port which nothing is listening on, random ports can collide
+// with ports allocated by the OS for other connections
+func freePort(t testing.TB) int {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// freeAddress returns a localhost address which nothing is listening on
+func freeAddress(t testing.TB) string {
+	return fmt.Sprintf("localhost:%d", freePort(t))
+}
 
-	p1 := randGen.Intn(2000) + 40000
-	p2 := randGen.Intn(2000) + 40000
-	p3 := randGen.Intn(2000) + 40000
+func setupServers(t *testing.T) (string, *string, []*serverStruct) {
+	p1 := freePort(t)
+	p2 := freePort(t)
+	p3 := freePort(t)
 
 	a1 := fmt.Sprintf("localhost:%d", p1)
 	a2 := fmt.Sprintf("localhost:%d", p2)
@@ -145,7 +159,7 @@ func setupServers(t *testing.T) (string, *string, []*serverStruct) {
 	return addr, body, servers
 }
 
-func createClient(t *testing.T, addr string) shipyard.RemoteConnectionClient {
+func createClient(t testing.TB, addr string) shipyard.RemoteConnectionClient {
 	//certificate, err := tls.LoadX509KeyPair("/tmp/certs/leaf.cert", "/tmp/certs/leaf.key")
 	//require.NoError(t, err)
 
@@ -179,7 +193,7 @@ func setupTests(t *testing.T) (shipyard.RemoteConnectionClient, string, *string,
 func TestExposeRemoteServiceCreatesLocalListener(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -204,7 +218,7 @@ func TestExposeRemoteServiceCreatesLocalListener(t *testing.T) {
 func TestExposeRemoteServiceCallsIntegration(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -227,7 +241,7 @@ func TestExposeRemoteServiceCallsIntegration(t *testing.T) {
 func TestShutdownRemovesLocalListener(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -260,7 +274,7 @@ func TestShutdownRemovesLocalListener(t *testing.T) {
 func TestShutdownRemovesRemoteListener(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -293,7 +307,7 @@ func TestShutdownRemovesRemoteListener(t *testing.T) {
 func TestExposeRemoteServiceCreatesLocalListener2(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -318,7 +332,7 @@ func TestExposeRemoteServiceCreatesLocalListener2(t *testing.T) {
 func TestExposeRemoteServiceUpdatesStatus(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -354,7 +368,7 @@ func TestExposeRemoteServiceUpdatesStatus(t *testing.T) {
 func TestReconfigureRemoteServiceUpdatesListener(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -451,7 +465,7 @@ func TestReconfigureRemoteServiceUpdatesListener(t *testing.T) {
 func TestExposeRemoteDuplicateReturnsError(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -482,7 +496,7 @@ func TestExposeRemoteDuplicateReturnsError(t *testing.T) {
 func TestExposeLocalDuplicateReturnsError(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -513,7 +527,7 @@ func TestExposeLocalDuplicateReturnsError(t *testing.T) {
 func TestExposeLocalDifferentServersReturnsOK(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -545,7 +559,7 @@ func TestExposeLocalDifferentConnectionsReturnsError(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 	c2 := createClient(t, servers[1].Address)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -610,7 +624,7 @@ func TestExposeLocalDifferentConnectionsReturnsError(t *testing.T) {
 func TestDestroyRemoteServiceRemovesLocalListener(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -656,7 +670,7 @@ func TestDestroyRemoteServiceRemovesLocalListener(t *testing.T) {
 func TestDisconnectRemovesRemoteListenerThenReconnects(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -700,7 +714,7 @@ func TestDisconnectRemovesRemoteListenerThenReconnects(t *testing.T) {
 func TestDestroyRemoteServiceRemovesLocalIntegration(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -744,7 +758,7 @@ func TestDestroyRemoteServiceRemovesLocalIntegration(t *testing.T) {
 func TestExposeLocalServiceCreatesRemoteListener(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -769,7 +783,7 @@ func TestExposeLocalServiceCreatesRemoteListener(t *testing.T) {
 func TestExposeLocalServiceCallsRemoteIntegration(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -792,7 +806,7 @@ func TestExposeLocalServiceCallsRemoteIntegration(t *testing.T) {
 func TestDestroyLocalServiceRemovesRemoteListener(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -825,7 +839,7 @@ func TestDestroyLocalServiceRemovesRemoteListener(t *testing.T) {
 func TestDestroyLocalServiceRemovesRemoteIntegration(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -864,7 +878,7 @@ func TestExposeRemoteServiceCreatesRemoteConnection(t *testing.T) {
 func TestMessageToRemoteEndpointCallsLocalService(t *testing.T) {
 	c, tsAddr, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -897,7 +911,7 @@ func TestMessageToRemoteEndpointCallsLocalService(t *testing.T) {
 func TestMessageToRemoteEndpointCallsLocalServiceAndCallsAddressLookup(t *testing.T) {
 	c, tsAddr, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -933,7 +947,7 @@ func TestMessageToRemoteEndpointCallsLocalServiceAndReceivesErrorFromAddressLook
 	servers[1].Integration.On("Deregister", mock.Anything).Return(nil)
 	servers[1].Integration.On("LookupAddress", mock.Anything).Return("", fmt.Errorf("unable to locate address"))
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -964,7 +978,7 @@ func TestMessageToRemoteEndpointCallsLocalServiceAndReceivesErrorFromAddressLook
 func TestMessageToLocalEndpointCallsRemoteService(t *testing.T) {
 	c, tsAddr, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -992,11 +1006,46 @@ func TestMessageToLocalEndpointCallsRemoteService(t *testing.T) {
 	require.Equal(t, http.StatusOK, httpResp.StatusCode)
 }
 
+func TestLargeResponseLargerThanWindowIsReceived(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	body := strings.Repeat("a", DefaultWindowSize*4)
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+
+	p := int32(freePort(t))
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			SourcePort:          p,
+			DestinationAddr:     ts.Listener.Addr().String(),
+			Type:                shipyard.ServiceType_REMOTE,
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Id)
+
+	// wait while to ensure all setup
+	time.Sleep(100 * time.Millisecond)
+
+	httpResp, err := http.DefaultClient.Get(fmt.Sprintf("http://localhost:%d", p))
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(httpResp.Body)
+	require.NoError(t, err)
+	require.Equal(t, len(body), len(data))
+}
+
 func TestMessageToNonExistantEndpointRetrurnsError(t *testing.T) {
 	c, _, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
-	p2 := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
+	p2 := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -1022,7 +1071,7 @@ func TestMessageToNonExistantEndpointRetrurnsError(t *testing.T) {
 func TestListServices(t *testing.T) {
 	c, tsAddr, _, servers := setupTests(t)
 
-	p := int32(rand.Intn(10000) + 30000)
+	p := int32(freePort(t))
 
 	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
 		Service: &shipyard.Service{
@@ -1152,3 +1201,20 @@ They picked up the gear from the boat. The old man carried the mast on his shoul
 
 They walked up the road together to the old man's shack and went in through its open door. The old man leaned the mast with its wrapped sail against the wall and the boy put the box and the other gear beside it. The mast was nearly as long as the one room of the shack. The shack was made of the tough bud-shields of the royal palm which are called guano and in it there was a bed, a table, one chair, and a place on the dirt floor to cook with charcoal. On the brown walls of the flattened, overlapping leaves of the sturdy fibered guano there was a picture in color of the Sacred Heart of Jesus and another of the Virgin of Cobre. These were relics of his wife. Once there had been a tinted photograph of his wife on the wall but he had taken it down because it made him too lonely to see it and it was on the shelf in the corner under his clean shirt.
 `
+
+func TestExposeServiceWithInvalidMessageSizeReturnsError(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			SourcePort:          30002,
+			DestinationAddr:     "localhost:8080",
+			Type:                shipyard.ServiceType_LOCAL,
+			MessageSize:         MaxMessageSize + 1,
+		},
+	})
+
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"net"
 	"sync"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/jumppad-labs/connector/protos/shipyard"
 )
 
@@ -41,28 +42,6 @@ func (s *services) delete(key string) {
 	delete(s.svcs, key)
 }
 
-func (s *services) contains(svc *service) bool {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-
-	for _, r := range s.svcs {
-		// check to see if we already have a listener defined for this port
-		if svc.detail.SourcePort == r.detail.SourcePort &&
-			r.detail.Type == shipyard.ServiceType_REMOTE {
-			return true
-		}
-
-		// check to see if there is a listener defined on the remote server for this port
-		if svc.detail.Type == shipyard.ServiceType_LOCAL &&
-			svc.detail.RemoteConnectorAddr == r.detail.RemoteConnectorAddr &&
-			svc.detail.SourcePort == r.detail.SourcePort {
-			return true
-		}
-	}
-
-	return false
-}
-
 func (s *services) iterate(c func(k string, svc *service) bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -77,9 +56,141 @@ func (s *services) iterate(c func(k string, svc *service) bool) {
 }
 
 type service struct {
-	detail         *shipyard.Service
+	detail         *shipyard.Service // the status must only be accessed with the methods
 	tcpListener    net.Listener
 	tcpConnections sync.Map
+	connMutex      sync.Mutex               // guards creating connections for the service
+	dialing        map[string][]heldMessage // messages for connections which are being dialed, guarded by connMutex
+	teardownMutex  sync.Mutex               // serializes closing the listener and removing the integration
+	compression    compressionStats
+	dials          dialStats
+
+	lock  sync.Mutex // guards the status, the listener, the balancer and the limiters
+	lb    *balancer
+	limit *connLimiter
+	rate  *tokenBucket
+}
+
+// rateLimiter returns the bandwidth limit for the service
+func (s *service) rateLimiter() *tokenBucket {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.rate == nil {
+		s.rate = newTokenBucket(s.detail.RateLimit)
+	}
+
+	return s.rate
+}
+
+// setRateLimit changes the bandwidth limit for the service, a nil limit removes the limit
+func (s *service) setRateLimit(l *shipyard.RateLimit) {
+	rate := s.rateLimiter()
+	rate.set(l)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.detail.RateLimit = rate.limit()
+}
+
+// limiter returns the limiter for concurrent connections, nil when the service does
+// not limit connections
+func (s *service) limiter() *connLimiter {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.limit == nil && s.detail.MaxConnections > 0 {
+		s.limit = newConnLimiter(int(s.detail.MaxConnections))
+	}
+
+	return s.limit
+}
+
+// queuesConnections returns true when connections over the limit for the service wait
+// for a slot rather than being refused
+func (s *service) queuesConnections() bool {
+	return s.limiter() != nil && s.detail.ConnectionLimitAction == shipyard.ConnectionLimitAction_QUEUE
+}
+
+// balancer returns the balancer for the destinations of the service
+func (s *service) balancer() *balancer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.lb == nil {
+		s.lb = newBalancer(s.detail.LoadBalancing)
+	}
+
+	return s.lb
+}
+
+// endpoints returns the state of the destinations, nil when the service has not
+// connected to a destination
+func (s *service) endpoints() []*shipyard.EndpointStatus {
+	s.lock.Lock()
+	lb := s.lb
+	s.lock.Unlock()
+
+	if lb == nil {
+		return nil
+	}
+
+	return lb.status()
+}
+
+func (s *service) status() shipyard.ServiceStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.detail.Status
+}
+
+func (s *service) setStatus(st shipyard.ServiceStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.detail.Status = st
+}
+
+// details returns a copy of the service definition
+func (s *service) details() *shipyard.Service {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return proto.Clone(s.detail).(*shipyard.Service)
+}
+
+func (s *service) sourcePort() int32 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.detail.SourcePort
+}
+
+func (s *service) setSourcePort(port int32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.detail.SourcePort = port
+}
+
+func (s *service) listener() net.Listener {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.tcpListener
+}
+
+// setListener sets the listener for the service, returns the previous listener
+func (s *service) setListener(l net.Listener) net.Listener {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	old := s.tcpListener
+	s.tcpListener = l
+
+	return old
 }
 
 func (s *service) getTCPConnection(key string) (*bufferedConn, bool) {
@@ -99,6 +210,18 @@ func (s *service) removeTCPConnection(key string) {
 	s.tcpConnections.Delete(key)
 }
 
+// closeTCPConnections closes and removes all the connections for the service
+func (s *service) closeTCPConnections() {
+	s.tcpConnections.Range(func(k interface{}, v interface{}) bool {
+		conn := v.(net.Conn)
+		conn.Close()
+
+		s.removeTCPConnection(k.(string))
+
+		return true
+	})
+}
+
 func newService() *service {
 	return &service{tcpConnections: sync.Map{}}
 }
@@ -0,0 +1,400 @@
+package remote
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// replayBuffer holds the data messages sent for a connection which have not yet
+// been acknowledged by the remote. When a stream is resumed the messages the remote
+// did not receive are sent again.
+type replayBuffer struct {
+	lock     sync.Mutex
+	messages []replayMessage
+	offset   int64 // total number of bytes added to the buffer
+}
+
+type replayMessage struct {
+	id     int32
+	end    int64 // offset of the end of the message in the connection data
+	data   []byte
+	pooled bool // data was allocated by getBuffer and is returned to the pool once acknowledged
+}
+
+func newReplayBuffer() *replayBuffer {
+	return &replayBuffer{}
+}
+
+// add a sent message to the buffer, pooled data is returned to the buffer pool when the
+// message is acknowledged
+func (r *replayBuffer) add(id int32, data []byte, pooled bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.offset += int64(len(data))
+	r.messages = append(r.messages, replayMessage{id, r.offset, data, pooled})
+}
+
+// ack removes all messages which have been completely consumed by the remote
+func (r *replayBuffer) ack(consumed int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := 0
+	for i < len(r.messages) && r.messages[i].end <= consumed {
+		if r.messages[i].pooled {
+			putBuffer(r.messages[i].data)
+		}
+
+		r.messages[i].data = nil
+		i++
+	}
+
+	r.messages = r.messages[i:]
+}
+
+// since returns the buffered messages with an id greater or equal to the given id
+func (r *replayBuffer) since(id int32) []replayMessage {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	msgs := []replayMessage{}
+	for _, m := range r.messages {
+		if !idBefore(m.id, id) {
+			msgs = append(msgs, m)
+		}
+	}
+
+	return msgs
+}
+
+// sendData sends a data message for a connection, the message is retained until the remote
+// acknowledges it. While the stream is suspended messages are only retained and will be
+// sent when the stream resumes. Returns true when the data has been retained and the
+// buffer must not be reused, pooled buffers are returned to the pool once acknowledged.
+func (si *streamInfo) sendData(conn *bufferedConn, serviceID string, d *shipyard.Data, pooled bool) bool {
+	si.sendMutex.RLock()
+	defer si.sendMutex.RUnlock()
+
+	// the destination is dialed when the first message is received
+	d.Client = conn.clientFor(d.Id)
+
+	// data is only retained when the remote can resume the stream
+	retained := si.supports(CapabilitySessions)
+	if retained {
+		conn.replay.add(d.Id, d.Data, pooled)
+	}
+
+	if si.suspended {
+		return retained
+	}
+
+	// the replay buffer holds the uncompressed data, replayed data is sent uncompressed
+	if conn.compress != nil && si.supports(CapabilityGzip) {
+		d = conn.compress.compress(d)
+	}
+
+	si.connFor(conn.id).Send(
+		&shipyard.OpenData{
+			ServiceId:    serviceID,
+			ConnectionId: conn.id,
+			Message:      &shipyard.OpenData_Data{Data: d},
+		},
+	)
+
+	return retained
+}
+
+// sendConnectionMessage sends a control message for a connection, while the stream is
+// suspended the message is discarded as the state of the connection will be reconciled
+// when the stream resumes
+func (si *streamInfo) sendConnectionMessage(msg *shipyard.OpenData) {
+	si.sendMutex.RLock()
+	defer si.sendMutex.RUnlock()
+
+	if si.suspended {
+		return
+	}
+
+	si.connFor(msg.ConnectionId).Send(msg)
+}
+
+func (si *streamInfo) isSuspended() bool {
+	si.sendMutex.RLock()
+	defer si.sendMutex.RUnlock()
+
+	return si.suspended
+}
+
+// suspendStream marks the stream as interrupted, the connections for the stream are kept
+// open for the grace period allowing the remote to reconnect and resume the session. If the
+// stream has not been resumed once the grace period has elapsed expired is called.
+func (s *Server) suspendStream(si *streamInfo, expired func()) {
+	si.sendMutex.Lock()
+	si.suspended = true
+	si.suspensions++
+	suspension := si.suspensions
+	si.sendMutex.Unlock()
+
+	s.log.Debug(
+		"session",
+		"message", "Stream suspended, waiting for resume",
+		"addr", si.addr,
+		"session_id", si.session,
+		"grace_period", s.resumeGracePeriod)
+
+	time.AfterFunc(s.resumeGracePeriod, func() {
+		si.sendMutex.RLock()
+		ok := si.suspended && si.suspensions == suspension
+		si.sendMutex.RUnlock()
+
+		if ok {
+			s.log.Info(
+				"session",
+				"message", "Stream was not resumed within the grace period",
+				"addr", si.addr,
+				"session_id", si.session)
+
+			expired()
+		}
+	})
+}
+
+// sessionMessage builds a Session message containing the state of all the
+// connections and services for the stream
+func (si *streamInfo) sessionMessage(resumed bool) *shipyard.OpenData {
+	sess := &shipyard.Session{Id: si.session, Resumed: resumed}
+
+	si.services.iterate(func(id string, svc *service) bool {
+		sess.ServiceIds = append(sess.ServiceIds, id)
+
+		svc.tcpConnections.Range(func(k, v interface{}) bool {
+			c := v.(*bufferedConn)
+			sess.Connections = append(sess.Connections, &shipyard.ConnectionState{
+				ServiceId:     id,
+				ConnectionId:  c.id,
+				NextMessageId: c.sequence.nextID(),
+				Consumed:      c.consumed.Load(),
+			})
+
+			return true
+		})
+
+		return true
+	})
+
+	return &shipyard.OpenData{Message: &shipyard.OpenData_Session{Session: sess}}
+}
+
+// resumeStream reconciles the connections for a stream with the state sent by the
+// remote. Data which the remote has not received is sent again, connections which only
+// exist on one side of the stream are closed.
+func (s *Server) resumeStream(si *streamInfo, state []*shipyard.ConnectionState) {
+	si.sendMutex.Lock()
+	defer si.sendMutex.Unlock()
+
+	s.log.Info(
+		"session",
+		"message", "Resuming stream",
+		"addr", si.addr,
+		"session_id", si.session,
+		"connections", len(state))
+
+	remote := map[string]*shipyard.ConnectionState{}
+	for _, cs := range state {
+		remote[cs.ConnectionId] = cs
+	}
+
+	si.services.iterate(func(id string, svc *service) bool {
+		svc.tcpConnections.Range(func(k, v interface{}) bool {
+			c := v.(*bufferedConn)
+
+			cs, ok := remote[c.id]
+			if !ok {
+				cs = &shipyard.ConnectionState{ServiceId: id, ConnectionId: c.id}
+
+				// the remote does not have the connection, if the remote has never consumed any data
+				// it may not have been created yet, replay the data so that the remote dials the upstream
+				if si.dialsUpstream(svc) || c.window.consumedBytes() > 0 {
+					s.log.Debug(
+						"session",
+						"message", "Connection does not exist on remote, closing",
+						"service_id", id,
+						"connection_id", c.id)
+
+					c.Close()
+					svc.removeTCPConnection(c.id)
+
+					return true
+				}
+			}
+
+			delete(remote, c.id)
+
+			c.window.update(cs.Consumed)
+			c.replay.ack(cs.Consumed)
+
+			msgs := c.replay.since(cs.NextMessageId)
+			s.log.Trace(
+				"session",
+				"message", "Replaying data for connection",
+				"service_id", id,
+				"connection_id", c.id,
+				"messages", len(msgs))
+
+			gc := si.connFor(c.id)
+			for _, m := range msgs {
+				gc.Send(
+					&shipyard.OpenData{
+						ServiceId:    id,
+						ConnectionId: c.id,
+						Message:      &shipyard.OpenData_Data{Data: &shipyard.Data{Id: m.id, Data: m.data, Client: c.clientFor(m.id)}},
+					},
+				)
+			}
+
+			// the WriteDone message may have been lost when the stream was interrupted
+			if c.readDone.Load() {
+				gc.Send(
+					&shipyard.OpenData{
+						ServiceId:    id,
+						ConnectionId: c.id,
+						Message:      &shipyard.OpenData_WriteDone{WriteDone: &shipyard.WriteDone{Messages: c.messages.Load()}},
+					},
+				)
+			}
+
+			return true
+		})
+
+		return true
+	})
+
+	// any connections left only exist on the remote, unless the remote is waiting for data
+	// to be replayed to a new connection it should close them
+	for _, cs := range remote {
+		svc, ok := si.services.get(cs.ServiceId)
+		if ok && si.dialsUpstream(svc) && cs.Consumed == 0 {
+			continue
+		}
+
+		si.connFor(cs.ConnectionId).Send(
+			&shipyard.OpenData{
+				ServiceId:    cs.ServiceId,
+				ConnectionId: cs.ConnectionId,
+				Message:      &shipyard.OpenData_Closed{Closed: &shipyard.Closed{}},
+			},
+		)
+	}
+
+	si.suspended = false
+}
+
+// handleSessionMessage handles the Session message sent by a client when it opens a stream.
+// If the session is held by the server the interrupted stream is resumed and returned, otherwise
+// a new session is started on the current stream.
+func (s *Server) handleSessionMessage(si *streamInfo, m *shipyard.OpenData_Session) *streamInfo {
+	existing, ok := s.streams.findBySession(m.Session.Id)
+
+	// additional streams join the pool for an existing session
+	if m.Session.Stream > 0 {
+		if !ok || existing == si {
+			s.log.Error(
+				"session",
+				"message", "Unable to find session for additional stream",
+				"session_id", m.Session.Id,
+				"stream", m.Session.Stream)
+
+			return si
+		}
+
+		s.log.Debug(
+			"session",
+			"message", "Adding stream to session",
+			"session_id", m.Session.Id,
+			"stream", m.Session.Stream)
+
+		s.streams.remove(si)
+		existing.addStream(si.grpcConn)
+
+		return existing
+	}
+
+	if !ok || existing == si {
+		s.log.Debug(
+			"session",
+			"message", "Starting new session",
+			"session_id", m.Session.Id)
+
+		s.streams.setSession(si, m.Session.Id)
+		si.grpcConn.Send(si.sessionMessage(false))
+
+		return si
+	}
+
+	// move the new stream to the existing session, the previous stream
+	// may not yet have detected that it has been interrupted
+	s.streams.remove(si)
+
+	existing.sendMutex.Lock()
+	existing.suspended = true
+	existing.setGRPCConn(si.grpcConn)
+	existing.setPeerFrom(si)
+	existing.sendMutex.Unlock()
+
+	existing.heartbeat.reset()
+	existing.setLinkState(shipyard.LinkState_CONNECTED)
+
+	// remove any services which were destroyed while the stream was interrupted
+	exposed := map[string]bool{}
+	for _, id := range m.Session.ServiceIds {
+		exposed[id] = true
+	}
+
+	destroyed := []string{}
+	existing.services.iterate(func(id string, svc *service) bool {
+		if !exposed[id] {
+			s.teardownService(svc)
+			destroyed = append(destroyed, id)
+		}
+
+		return true
+	})
+
+	for _, id := range destroyed {
+		s.streams.removeService(existing, id)
+	}
+
+	existing.grpcConn.Send(existing.sessionMessage(true))
+	s.resumeStream(existing, m.Session.Connections)
+
+	return existing
+}
+
+// handleSessionReply handles the Session message sent by the server in reply to the
+// client opening a stream
+func (s *Server) handleSessionReply(si *streamInfo, m *shipyard.OpenData_Session) {
+	if m.Session.Resumed {
+		s.resumeStream(si, m.Session.Connections)
+		return
+	}
+
+	// the remote does not know about the session, all the connections
+	// which were using the stream are lost
+	s.log.Debug(
+		"session",
+		"message", "Session not resumed by remote, closing connections",
+		"addr", si.addr,
+		"session_id", si.session)
+
+	si.services.iterate(func(id string, svc *service) bool {
+		svc.closeTCPConnections()
+		return true
+	})
+
+	si.sendMutex.Lock()
+	si.suspended = false
+	si.sendMutex.Unlock()
+}
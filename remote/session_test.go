@@ -0,0 +1,192 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+// testProxy forwards TCP connections to a target, it is used to simulate
+// the network between two connectors being interrupted
+type testProxy struct {
+	listener net.Listener
+	target   string
+	lock     sync.Mutex
+	conns    []net.Conn
+}
+
+func startProxy(t *testing.T, target string) *testProxy {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	p := &testProxy{listener: l, target: target}
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			u, err := net.Dial("tcp", target)
+			if err != nil {
+				c.Close()
+				continue
+			}
+
+			p.lock.Lock()
+			p.conns = append(p.conns, c, u)
+			p.lock.Unlock()
+
+			go io.Copy(c, u)
+			go io.Copy(u, c)
+		}
+	}()
+
+	t.Cleanup(func() {
+		l.Close()
+		p.interrupt()
+	})
+
+	return p
+}
+
+func (p *testProxy) addr() string {
+	return p.listener.Addr().String()
+}
+
+// interrupt closes all the connections currently passing through the proxy
+func (p *testProxy) interrupt() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, c := range p.conns {
+		c.Close()
+	}
+
+	p.conns = nil
+}
+
+func startEchoServer(t testing.TB) string {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				io.Copy(c, c)
+				c.Close()
+			}()
+		}
+	}()
+
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	return l.Addr().String()
+}
+
+func TestReplayBufferReturnsUnacknowledgedMessages(t *testing.T) {
+	r := newReplayBuffer()
+	r.add(0, []byte("abc"), false)
+	r.add(1, []byte("def"), false)
+	r.add(2, []byte("ghi"), false)
+
+	r.ack(3)
+
+	msgs := r.since(0)
+	require.Len(t, msgs, 2)
+	require.Equal(t, int32(1), msgs[0].id)
+
+	msgs = r.since(2)
+	require.Len(t, msgs, 1)
+	require.Equal(t, "ghi", string(msgs[0].data))
+}
+
+func TestReplayBufferKeepsPartiallyConsumedMessages(t *testing.T) {
+	r := newReplayBuffer()
+	r.add(0, []byte("abc"), false)
+
+	r.ack(2)
+
+	require.Len(t, r.since(0), 1)
+}
+
+func TestReplayBufferReturnsMessagesAfterIDWraps(t *testing.T) {
+	r := newReplayBuffer()
+	r.add(math.MaxInt32, []byte("abc"), false)
+	r.add(math.MinInt32, []byte("def"), false)
+
+	require.Len(t, r.since(math.MaxInt32), 2)
+
+	msgs := r.since(math.MinInt32)
+	require.Len(t, msgs, 1)
+	require.Equal(t, "def", string(msgs[0].data))
+}
+
+func TestInterruptedStreamResumesConnections(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	proxy := startProxy(t, servers[1].Address)
+	echo := startEchoServer(t)
+
+	p := int32(freePort(t))
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: proxy.addr(),
+			SourcePort:          p,
+			DestinationAddr:     echo,
+			Type:                shipyard.ServiceType_REMOTE,
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Id)
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+		return err == nil
+	}, time.Second, 50*time.Millisecond)
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	r := bufio.NewReader(conn)
+
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", line)
+
+	// break the stream between the two connectors
+	proxy.interrupt()
+
+	// the connection should still be usable once the stream has resumed
+	_, err = conn.Write([]byte("world\n"))
+	require.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "world\n", line)
+}
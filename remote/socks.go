@@ -0,0 +1,152 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+)
+
+// SOCKS5 protocol values, RFC 1928
+const (
+	socksVersion             = 5
+	socksMethodNoAuth        = 0x00
+	socksMethodNotAcceptable = 0xff
+	socksCommandConnect      = 1
+	socksAddressIPv4         = 1
+	socksAddressDomain       = 3
+	socksAddressIPv6         = 4
+)
+
+// SOCKS5 reply codes sent to the client in response to the CONNECT request
+const (
+	socksSucceeded           = 0x00
+	socksGeneralFailure      = 0x01
+	socksNotAllowed          = 0x02
+	socksHostUnreachable     = 0x04
+	socksCommandNotSupported = 0x07
+	socksAddressNotSupported = 0x08
+)
+
+// handleSOCKSConnection reads the CONNECT request from a SOCKS5 client and opens a
+// connection over the stream to the requested destination
+func (s *Server) handleSOCKSConnection(serviceID string, si *streamInfo, svc *service, l net.Listener, conn net.Conn) {
+	dest, err := readSOCKSRequest(conn)
+	if err != nil {
+		s.log.Debug("proxy", "message", "Unable to read SOCKS request", "service_id", serviceID, "error", err)
+		conn.Close()
+		return
+	}
+
+	s.openProxyConnection(serviceID, si, svc, l, conn, dest, func(err error) error {
+		if err != nil {
+			return writeSOCKSReply(conn, socksReplyCode(err))
+		}
+
+		return writeSOCKSReply(conn, socksSucceeded)
+	})
+}
+
+// readSOCKSRequest negotiates the authentication method and reads the CONNECT request,
+// returns the requested destination as host:port. Only clients which do not require
+// authentication are supported.
+func readSOCKSRequest(conn net.Conn) (string, error) {
+	// version, number of methods, methods
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(conn, hdr); err != nil {
+		return "", err
+	}
+
+	if hdr[0] != socksVersion {
+		return "", fmt.Errorf("unsupported SOCKS version %d", hdr[0])
+	}
+
+	methods := make([]byte, hdr[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", err
+	}
+
+	if bytes.IndexByte(methods, socksMethodNoAuth) < 0 {
+		conn.Write([]byte{socksVersion, socksMethodNotAcceptable})
+		return "", fmt.Errorf("client requires authentication")
+	}
+
+	if _, err := conn.Write([]byte{socksVersion, socksMethodNoAuth}); err != nil {
+		return "", err
+	}
+
+	// version, command, reserved, address type
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return "", err
+	}
+
+	if req[0] != socksVersion {
+		return "", fmt.Errorf("unsupported SOCKS version %d", req[0])
+	}
+
+	if req[1] != socksCommandConnect {
+		writeSOCKSReply(conn, socksCommandNotSupported)
+		return "", fmt.Errorf("unsupported SOCKS command %d", req[1])
+	}
+
+	var host string
+	switch req[3] {
+	case socksAddressIPv4, socksAddressIPv6:
+		ip := make([]byte, net.IPv4len)
+		if req[3] == socksAddressIPv6 {
+			ip = make([]byte, net.IPv6len)
+		}
+
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+
+		host = net.IP(ip).String()
+	case socksAddressDomain:
+		n := make([]byte, 1)
+		if _, err := io.ReadFull(conn, n); err != nil {
+			return "", err
+		}
+
+		name := make([]byte, n[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return "", err
+		}
+
+		host = string(name)
+	default:
+		writeSOCKSReply(conn, socksAddressNotSupported)
+		return "", fmt.Errorf("unsupported SOCKS address type %d", req[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// writeSOCKSReply sends the reply to the CONNECT request, the address the destination
+// was dialed from is not known by the client side and is always reported as 0.0.0.0:0
+func writeSOCKSReply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socksVersion, code, 0, socksAddressIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// socksReplyCode returns the reply code for an error opening a proxy connection
+func socksReplyCode(err error) byte {
+	switch proxyErrorCode(err) {
+	case codes.PermissionDenied:
+		return socksNotAllowed
+	case codes.Unavailable:
+		return socksHostUnreachable
+	}
+
+	return socksGeneralFailure
+}
@@ -2,119 +2,160 @@ package remote
 
 import (
 	"context"
+	"hash/fnv"
 	"sync"
 
 	"github.com/jumppad-labs/connector/protos/shipyard"
 )
 
-type streams []*streamInfo
-
-var streamMutex sync.Mutex
+type streamInfo struct {
+	addr        string
+	inbound     bool      // stream was opened by the remote
+	grpcConn    *grpcConn // primary stream used for control messages
+	pool        []*grpcConn
+	services    *services
+	updateMutex sync.Mutex
 
-func (c *streams) add(si *streamInfo) {
-	streamMutex.Lock()
-	defer streamMutex.Unlock()
+	session     string       // id used to resume the stream after an interruption
+	suspended   bool         // stream is interrupted, data is retained until it resumes
+	suspensions int          // number of times the stream has been suspended
+	sendMutex   sync.RWMutex // guards data sends against the stream resuming
 
-	*c = append(*c, si)
+	peer      peerInfo      // version and capabilities negotiated with the remote
+	handshake chan struct{} // closed when the handshake reply is received
+	heartbeat heartbeat
+	link      link         // state of the connection to the remote server
+	rateLimit *tokenBucket // bandwidth limit for all the services which use the link
 }
 
-func (c *streams) remove(si *streamInfo) {
-	streamMutex.Lock()
-	defer streamMutex.Unlock()
+// returns a grpc connection in a thread safe way
+func (si *streamInfo) closeGRPCConn() {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
 
-	newSlice := streams{}
-	for _, s := range *c {
-		if s != si {
-			newSlice = append(newSlice, si)
-		}
+	if si.grpcConn != nil {
+		si.grpcConn.Close()
 	}
 
-	*c = newSlice
-}
-
-func (c *streams) findByRemoteAddr(addr string) (*streamInfo, bool) {
-	for _, v := range *c {
-		if v.addr == addr {
-			return v, true
-		}
+	for _, g := range si.pool {
+		g.Close()
 	}
-
-	return nil, false
 }
 
-func (c *streams) findByServiceID(id string) (*streamInfo, bool) {
-	for _, v := range *c {
-		found := false
-		v.services.iterate(func(svcid string, svc *service) bool {
-			if id == svcid {
-				found = true
-				return false // stop itterating
-			}
-
-			return true
-		})
+// setGRPCConn sets the primary stream, any streams in the existing pool are closed
+// and the pool is reset to only contain the primary stream
+func (si *streamInfo) setGRPCConn(g *grpcConn) {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
 
-		if found {
-			return v, true
+	for _, p := range si.pool {
+		if p != g {
+			p.Close()
 		}
 	}
 
-	return nil, false
+	si.grpcConn = g
+	si.pool = []*grpcConn{g}
 }
 
-func (c *streams) findByRemoteConnection(rc interface{}) (*streamInfo, bool) {
-	for _, v := range *c {
-		if rc == v.grpcConn.conn {
-			return v, true
-		}
-	}
+func (si *streamInfo) getGRPCConn() *grpcConn {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
 
-	return nil, false
+	return si.grpcConn
 }
 
-type streamInfo struct {
-	connecting  bool
-	addr        string
-	grpcConn    *grpcConn
-	services    *services
-	updateMutex sync.Mutex
+// addStream adds an additional stream to the pool
+func (si *streamInfo) addStream(g *grpcConn) {
+	si.updateMutex.Lock()
+	defer si.updateMutex.Unlock()
+
+	si.pool = append(si.pool, g)
 }
 
-// returns a grpc connection in a thread safe way
-func (si *streamInfo) closeGRPCConn() {
+// inPool returns true if the stream is part of the current pool
+func (si *streamInfo) inPool(g *grpcConn) bool {
 	si.updateMutex.Lock()
 	defer si.updateMutex.Unlock()
 
-	if si.grpcConn != nil {
-		si.grpcConn.Close()
+	for _, p := range si.pool {
+		if p == g {
+			return true
+		}
 	}
+
+	return false
 }
 
-func (si *streamInfo) setGRPCConn(g *grpcConn) {
+// poolSize returns the number of streams in the pool
+func (si *streamInfo) poolSize() int {
 	si.updateMutex.Lock()
 	defer si.updateMutex.Unlock()
 
-	si.grpcConn = g
+	return len(si.pool)
 }
 
-func (si *streamInfo) isConnecting() bool {
+// closePool closes all the streams in the pool when the given stream is a member,
+// returns false if the stream is not part of the current pool. The streams in a pool
+// fail together, only the first stream to fail needs to handle the failure.
+func (si *streamInfo) closePool(g *grpcConn) bool {
 	si.updateMutex.Lock()
 	defer si.updateMutex.Unlock()
 
-	return si.connecting
-}
+	found := false
+	for _, p := range si.pool {
+		if p == g {
+			found = true
+		}
+	}
 
-func (si *streamInfo) setConnecting(value bool) {
+	if !found {
+		return false
+	}
+
+	for _, p := range si.pool {
+		p.Close()
+	}
+
+	si.pool = nil
 
+	return true
+}
+
+// connFor returns the stream used to send messages for a connection, connections
+// are spread across all the streams in the pool. Messages for a connection may
+// move streams when the pool changes, the receiver orders them by message id.
+func (si *streamInfo) connFor(connectionID string) *grpcConn {
 	si.updateMutex.Lock()
 	defer si.updateMutex.Unlock()
 
-	si.connecting = value
+	if len(si.pool) < 2 || connectionID == "" {
+		return si.grpcConn
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(connectionID))
+
+	return si.pool[h.Sum32()%uint32(len(si.pool))]
+}
+
+// dialsUpstream returns true when the destination for the service is reached from
+// this side of the stream. For inbound streams this is a remote service, for outbound
+// streams a local service.
+func (si *streamInfo) dialsUpstream(svc *service) bool {
+	if si.inbound {
+		return svc.detail.Type == shipyard.ServiceType_REMOTE
+	}
+
+	return svc.detail.Type == shipyard.ServiceType_LOCAL
 }
 
 func newStreamInfo() *streamInfo {
 	return &streamInfo{
-		services: newServices(),
+		services:  newServices(),
+		peer:      legacyPeer,
+		link:      newLink(),
+		rateLimit: newTokenBucket(nil),
 	}
 }
 
@@ -165,6 +206,9 @@ func (r *grpcConn) Recv() (*shipyard.OpenData, error) {
 }
 
 func (r *grpcConn) Close() {
+	// cancel before waiting for the lock, a send to a dead remote may be holding it
+	r.cancel()
+
 	r.syncMutex.Lock()
 	defer r.syncMutex.Unlock()
 
@@ -0,0 +1,146 @@
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPoolTests(t *testing.T, streams int) (shipyard.RemoteConnectionClient, []*serverStruct) {
+	p1 := freePort(t)
+	p2 := freePort(t)
+
+	a1 := fmt.Sprintf("localhost:%d", p1)
+	a2 := fmt.Sprintf("localhost:%d", p2)
+
+	s1, m1, c1 := createServer(t, a1, "server_local_1", WithStreams(streams))
+	s2, m2, c2 := createServer(t, a2, "server_remote_1")
+
+	servers := []*serverStruct{
+		{s1, p1, a1, m1, c1},
+		{s2, p2, a2, m2, c2},
+	}
+
+	return createClient(t, a1), servers
+}
+
+func exposeEchoService(t *testing.T, c shipyard.RemoteConnectionClient, remoteAddr string) int32 {
+	return exposeService(t, c, remoteAddr, startEchoServer(t), shipyard.ServiceType_REMOTE)
+}
+
+// requirePoolSize waits until both sides of the link have a pool of n streams
+func requirePoolSize(t *testing.T, servers []*serverStruct, remoteAddr string, n int) {
+	require.Eventually(t, func() bool {
+		local, ok := servers[0].Server.streams.findByRemoteAddr(remoteAddr)
+		if !ok || local.poolSize() != n {
+			return false
+		}
+
+		remote, ok := servers[1].Server.streams.findBySession(local.session)
+		return ok && remote.poolSize() == n
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func echoLines(t *testing.T, port int32, lines int) error {
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	r := bufio.NewReader(conn)
+
+	for i := 0; i < lines; i++ {
+		line := fmt.Sprintf("line %d\n", i)
+
+		_, err := conn.Write([]byte(line))
+		if err != nil {
+			return err
+		}
+
+		resp, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		if resp != line {
+			return fmt.Errorf("expected %q, got %q", line, resp)
+		}
+	}
+
+	return nil
+}
+
+func TestConnectionsAreShardedAcrossStreamPool(t *testing.T) {
+	c, servers := setupPoolTests(t, 4)
+	p := exposeEchoService(t, c, servers[1].Address)
+
+	requirePoolSize(t, servers, servers[1].Address, 4)
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 16)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- echoLines(t, p, 50)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestInterruptedStreamPoolResumesConnections(t *testing.T) {
+	c, servers := setupPoolTests(t, 4)
+	proxy := startProxy(t, servers[1].Address)
+	p := exposeEchoService(t, c, proxy.addr())
+
+	requirePoolSize(t, servers, proxy.addr(), 4)
+
+	var conn net.Conn
+	var err error
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+		return err == nil
+	}, time.Second, 50*time.Millisecond)
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	r := bufio.NewReader(conn)
+
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", line)
+
+	// break all the streams between the two connectors
+	proxy.interrupt()
+
+	_, err = conn.Write([]byte("world\n"))
+	require.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "world\n", line)
+
+	// the pool is opened again once the stream has resumed
+	requirePoolSize(t, servers, proxy.addr(), 4)
+}
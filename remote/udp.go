@@ -0,0 +1,225 @@
+package remote
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultUDPIdleTimeout is the time a UDP peer can be inactive before
+// its connection is closed
+const DefaultUDPIdleTimeout = 60 * time.Second
+
+// maxDatagramSize is the largest payload which can be received in a single datagram
+const maxDatagramSize = 65535
+
+// udpPendingDatagrams is the number of datagrams buffered for a peer before
+// new datagrams are dropped
+const udpPendingDatagrams = 64
+
+// udpPendingPeers is the number of new peers waiting to be accepted before
+// datagrams from further new peers are dropped
+const udpPendingPeers = 16
+
+// udpListener implements net.Listener for a UDP socket, datagrams from each
+// peer address are delivered to a pseudo connection which is returned by Accept
+// when the first datagram from the peer is received.
+type udpListener struct {
+	pc          net.PacketConn
+	idleTimeout time.Duration
+
+	lock  sync.Mutex
+	conns map[string]*udpConn
+
+	accept    chan *udpConn
+	done      chan struct{}
+	closeOnce sync.Once
+	refuse    atomic.Bool // datagrams from new peers are dropped
+}
+
+func newUDPListener(pc net.PacketConn, idleTimeout time.Duration) *udpListener {
+	l := &udpListener{
+		pc:          pc,
+		idleTimeout: idleTimeout,
+		conns:       map[string]*udpConn{},
+		accept:      make(chan *udpConn, udpPendingPeers),
+		done:        make(chan struct{}),
+	}
+
+	go l.serve()
+
+	return l
+}
+
+// serve reads datagrams from the socket and routes them to the connection for the peer
+func (l *udpListener) serve() {
+	buf := make([]byte, maxDatagramSize)
+
+	for {
+		n, addr, err := l.pc.ReadFrom(buf)
+		if err != nil {
+			l.Close()
+			return
+		}
+
+		data := make([]byte, n)
+		copy(data, buf[:n])
+
+		l.lock.Lock()
+		c, ok := l.conns[addr.String()]
+		if !ok && l.refuse.Load() {
+			l.lock.Unlock()
+			continue
+		}
+
+		if !ok {
+			c = newUDPConn(l, addr)
+			l.conns[addr.String()] = c
+		}
+		l.lock.Unlock()
+
+		c.deliver(data)
+
+		// reading datagrams must never wait for Accept as it would stall the peers which
+		// are already connected, the new peer is dropped when too many are waiting
+		if !ok {
+			select {
+			case l.accept <- c:
+			default:
+				c.Close()
+			}
+		}
+	}
+}
+
+// Accept waits for a datagram from a new peer and returns the connection for it
+func (l *udpListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close the listener and all the peer connections
+func (l *udpListener) Close() error {
+	var err error
+
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.pc.Close()
+
+		l.lock.Lock()
+		conns := l.conns
+		l.conns = map[string]*udpConn{}
+		l.lock.Unlock()
+
+		for _, c := range conns {
+			c.Close()
+		}
+	})
+
+	return err
+}
+
+// stopAccepting drops datagrams from new peers, existing peers are not affected
+func (l *udpListener) stopAccepting() {
+	l.refuse.Store(true)
+}
+
+func (l *udpListener) Addr() net.Addr {
+	return l.pc.LocalAddr()
+}
+
+func (l *udpListener) remove(c *udpConn) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.conns[c.peer.String()] == c {
+		delete(l.conns, c.peer.String())
+	}
+}
+
+// udpConn is a pseudo connection for a single UDP peer, each call to Read returns a
+// single datagram and each call to Write sends a single datagram. The connection is
+// closed when no datagrams have been sent or received for the idle timeout.
+type udpConn struct {
+	l     *udpListener
+	peer  net.Addr
+	reads chan []byte
+	idle  *time.Timer
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newUDPConn(l *udpListener, peer net.Addr) *udpConn {
+	c := &udpConn{
+		l:     l,
+		peer:  peer,
+		reads: make(chan []byte, udpPendingDatagrams),
+		done:  make(chan struct{}),
+	}
+
+	c.idle = time.AfterFunc(l.idleTimeout, func() { c.Close() })
+
+	return c
+}
+
+// deliver queues a datagram received from the peer, datagrams are
+// dropped when the connection is not reading them fast enough
+func (c *udpConn) deliver(data []byte) {
+	c.idle.Reset(c.l.idleTimeout)
+
+	select {
+	case c.reads <- data:
+	default:
+	}
+}
+
+func (c *udpConn) Read(b []byte) (int, error) {
+	select {
+	case d := <-c.reads:
+		return copy(b, d), nil
+	case <-c.done:
+		return 0, io.EOF
+	}
+}
+
+func (c *udpConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.done:
+		return 0, net.ErrClosed
+	default:
+	}
+
+	c.idle.Reset(c.l.idleTimeout)
+
+	return c.l.pc.WriteTo(b, c.peer)
+}
+
+func (c *udpConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.idle.Stop()
+		close(c.done)
+		c.l.remove(c)
+	})
+
+	return nil
+}
+
+func (c *udpConn) LocalAddr() net.Addr {
+	return c.l.pc.LocalAddr()
+}
+
+func (c *udpConn) RemoteAddr() net.Addr {
+	return c.peer
+}
+
+// deadlines are not supported, the connection is closed by the idle timeout
+func (c *udpConn) SetDeadline(t time.Time) error      { return nil }
+func (c *udpConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *udpConn) SetWriteDeadline(t time.Time) error { return nil }
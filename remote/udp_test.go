@@ -0,0 +1,274 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+func startUDPEchoServer(t *testing.T) string {
+	pc, err := net.ListenPacket("udp4", "localhost:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		pc.Close()
+	})
+
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			pc.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	return pc.LocalAddr().String()
+}
+
+func TestUDPListenerCreatesConnectionPerPeer(t *testing.T) {
+	pc, err := net.ListenPacket("udp4", "localhost:0")
+	require.NoError(t, err)
+
+	l := newUDPListener(pc, time.Minute)
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	c1, err := net.Dial("udp4", l.Addr().String())
+	require.NoError(t, err)
+	defer c1.Close()
+
+	c2, err := net.Dial("udp4", l.Addr().String())
+	require.NoError(t, err)
+	defer c2.Close()
+
+	c1.Write([]byte("one"))
+	conn1, err := l.Accept()
+	require.NoError(t, err)
+
+	c2.Write([]byte("two"))
+	conn2, err := l.Accept()
+	require.NoError(t, err)
+
+	buf := make([]byte, maxDatagramSize)
+	n, err := conn1.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "one", string(buf[:n]))
+
+	n, err = conn2.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "two", string(buf[:n]))
+
+	// replies are sent to the correct peer
+	_, err = conn2.Write([]byte("reply"))
+	require.NoError(t, err)
+
+	n, err = c2.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "reply", string(buf[:n]))
+}
+
+func TestUDPConnectionClosesWhenIdle(t *testing.T) {
+	pc, err := net.ListenPacket("udp4", "localhost:0")
+	require.NoError(t, err)
+
+	l := newUDPListener(pc, 100*time.Millisecond)
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	c, err := net.Dial("udp4", l.Addr().String())
+	require.NoError(t, err)
+	defer c.Close()
+
+	c.Write([]byte("hello"))
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	buf := make([]byte, maxDatagramSize)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+
+	_, err = conn.Read(buf)
+	require.Equal(t, io.EOF, err)
+
+	// a new datagram from the peer creates a new connection
+	c.Write([]byte("again"))
+	conn, err = l.Accept()
+	require.NoError(t, err)
+
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "again", string(buf[:n]))
+}
+
+func TestUDPListenerDropsNewPeersWhenAcceptIsFull(t *testing.T) {
+	pc, err := net.ListenPacket("udp4", "localhost:0")
+	require.NoError(t, err)
+
+	l := newUDPListener(pc, time.Minute)
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	c, err := net.Dial("udp4", l.Addr().String())
+	require.NoError(t, err)
+	defer c.Close()
+
+	c.Write([]byte("one"))
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	// new peers which are never accepted
+	for i := 0; i < udpPendingPeers+2; i++ {
+		p, err := net.Dial("udp4", l.Addr().String())
+		require.NoError(t, err)
+		defer p.Close()
+
+		p.Write([]byte("new"))
+	}
+
+	c.Write([]byte("two"))
+
+	read := make(chan string, 2)
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for i := 0; i < 2; i++ {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+
+			read <- string(buf[:n])
+		}
+	}()
+
+	for _, want := range []string{"one", "two"} {
+		select {
+		case d := <-read:
+			require.Equal(t, want, d)
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected datagrams for the accepted peer to be delivered")
+		}
+	}
+}
+
+func TestUDPServiceForwardsDatagrams(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	echo := startUDPEchoServer(t)
+
+	p := int32(freePort(t))
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			SourcePort:          p,
+			DestinationAddr:     echo,
+			Type:                shipyard.ServiceType_REMOTE,
+			Protocol:            shipyard.ServiceProtocol_UDP,
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Id)
+
+	conn, err := net.Dial("udp4", fmt.Sprintf("localhost:%d", p))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	buf := make([]byte, maxDatagramSize)
+
+	// the listener may not exist yet, keep sending until there is a reply
+	require.Eventually(t, func() bool {
+		conn.Write([]byte("ping"))
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+
+		n, err := conn.Read(buf)
+		return err == nil && string(buf[:n]) == "ping"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// drain any duplicate replies from the retries above
+	for {
+		conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		if _, err := conn.Read(buf); err != nil {
+			break
+		}
+	}
+
+	// datagram boundaries are preserved
+	large := make([]byte, 8000)
+	for i := range large {
+		large[i] = byte('a' + i%26)
+	}
+
+	conn.Write([]byte("one"))
+	conn.Write(large)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "one", string(buf[:n]))
+
+	n, err = conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, large, buf[:n])
+}
+
+func TestIdleUDPPeerClosesRemoteConnection(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	WithUDPIdleTimeout(200 * time.Millisecond)(servers[0].Server)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     startUDPEchoServer(t),
+			Type:                shipyard.ServiceType_REMOTE,
+			Protocol:            shipyard.ServiceProtocol_UDP,
+		},
+	})
+	require.NoError(t, err)
+
+	p := waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+	conn, err := net.Dial("udp4", fmt.Sprintf("localhost:%d", p))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	buf := make([]byte, maxDatagramSize)
+	require.Eventually(t, func() bool {
+		conn.Write([]byte("ping"))
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+
+		n, err := conn.Read(buf)
+		return err == nil && string(buf[:n]) == "ping"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	si, ok := servers[1].Server.streams.findByServiceID(resp.Id)
+	require.True(t, ok)
+
+	svc, ok := si.services.get(resp.Id)
+	require.True(t, ok)
+
+	// the socket dialed by the remote is closed once the peer is idle
+	require.Eventually(t, func() bool {
+		n := 0
+		svc.tcpConnections.Range(func(k, v interface{}) bool {
+			n++
+			return true
+		})
+
+		return n == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,79 @@
+package remote
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// unixSocketPrefix is the scheme for bind and destination addresses which are
+// the path of a Unix domain socket
+const unixSocketPrefix = "unix://"
+
+// staleSocketTimeout is the time to wait when checking if an existing socket file
+// is used by another process
+var staleSocketTimeout = time.Second
+
+// unixSocketPath returns the path for a unix:// address, returns false when
+// the address is not a Unix domain socket
+func unixSocketPath(addr string) (string, bool) {
+	if !strings.HasPrefix(addr, unixSocketPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(addr, unixSocketPrefix), true
+}
+
+// isUnixSocket returns true when the address is the path of a Unix domain socket
+func isUnixSocket(addr string) bool {
+	_, ok := unixSocketPath(addr)
+	return ok
+}
+
+// dialAddress returns the network and address used to dial the destination of a service
+func dialAddress(protocol shipyard.ServiceProtocol, addr string) (string, string) {
+	if path, ok := unixSocketPath(addr); ok {
+		return "unix", path
+	}
+
+	return strings.ToLower(protocol.String()), addr
+}
+
+// listenUnix creates a listener on the Unix domain socket at path, a socket file left
+// behind by a process which is no longer running is removed. The socket file is
+// removed when the listener is closed.
+func listenUnix(path string) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	return net.Listen("unix", path)
+}
+
+// removeStaleSocket removes the socket file at path when nothing is listening on it,
+// files which are not sockets or are in use are not removed
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s already exists and is not a socket", path)
+	}
+
+	if c, err := net.DialTimeout("unix", path, staleSocketTimeout); err == nil {
+		c.Close()
+		return fmt.Errorf("%s is already in use", path)
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,173 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+)
+
+func startUnixEchoServer(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "echo.sock")
+
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go io.Copy(c, c)
+		}
+	}()
+
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	return path
+}
+
+func requireEcho(t *testing.T, conn net.Conn) {
+	_, err := conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	d := make([]byte, 5)
+	_, err = io.ReadFull(conn, d)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(d))
+}
+
+func TestRemoveStaleSocketRemovesUnusedSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stale.sock")
+
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	// leave the socket file behind as if the process had crashed
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	require.NoError(t, removeStaleSocket(path))
+
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestRemoveStaleSocketReturnsErrorWhenInUse(t *testing.T) {
+	path := startUnixEchoServer(t)
+
+	require.Error(t, removeStaleSocket(path))
+}
+
+func TestRemoveStaleSocketDoesNotRemoveFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+
+	require.Error(t, removeStaleSocket(path))
+
+	_, err := os.Stat(path)
+	require.NoError(t, err)
+}
+
+func TestExposeLocalServiceWithUnixSocketDestination(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	path := startUnixEchoServer(t)
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "unix://" + path,
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	})
+	require.NoError(t, err)
+
+	p := waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", p))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	requireEcho(t, conn)
+}
+
+func TestExposeRemoteServiceWithUnixSocketListener(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+	dest := startEchoServer(t)
+	path := filepath.Join(t.TempDir(), "listener.sock")
+
+	resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     dest,
+			BindAddress:         "unix://" + path,
+			Type:                shipyard.ServiceType_REMOTE,
+		},
+	})
+	require.NoError(t, err)
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", path)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	requireEcho(t, conn)
+	conn.Close()
+
+	// the socket file is removed with the service
+	_, err = c.DestroyService(context.Background(), &shipyard.DestroyRequest{Id: resp.Id})
+	require.NoError(t, err)
+
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestExposeServiceWithInvalidUnixSocketReturnsError(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	tests := []*shipyard.Service{
+		// UDP is not supported
+		{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "unix:///tmp/test.sock",
+			Type:                shipyard.ServiceType_LOCAL,
+			Protocol:            shipyard.ServiceProtocol_UDP,
+		},
+		// Unix listeners do not have a port
+		{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "localhost:8080",
+			BindAddress:         "unix:///tmp/test.sock",
+			SourcePort:          8080,
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+		// the path is required
+		{
+			Name:                "Test 1",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "unix://",
+			Type:                shipyard.ServiceType_LOCAL,
+		},
+	}
+
+	for _, svc := range tests {
+		_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{Service: svc})
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,360 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+)
+
+// virtualHostTimeout is the time to wait for the Host header or the TLS server name
+// before a connection to a shared listener is closed
+var virtualHostTimeout = 10 * time.Second
+
+// tlsRecordTypeHandshake is the first byte of a TLS connection
+const tlsRecordTypeHandshake = 0x16
+
+// notFoundResponse is sent to HTTP clients when no service has the requested host
+const notFoundResponse = "HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"
+
+// errServerNameRead stops the TLS handshake once the server name has been read
+var errServerNameRead = errors.New("server name read")
+
+// vhostKey identifies a listener shared by services with virtual hosts
+type vhostKey struct {
+	bind string
+	port int
+}
+
+// virtualHosts are the listeners shared by services with virtual hosts, each connection
+// is routed to the service for the Host header of the first request or the TLS server
+// name. TLS connections are not decrypted.
+type virtualHosts struct {
+	lock      sync.Mutex
+	listeners map[vhostKey]*vhostListener
+}
+
+func newVirtualHosts() *virtualHosts {
+	return &virtualHosts{listeners: map[vhostKey]*vhostListener{}}
+}
+
+// vhostListener is a listener shared by the services with virtual hosts on a port
+type vhostListener struct {
+	key    vhostKey
+	l      net.Listener
+	routes map[string]*hostListener // guarded by the virtualHosts lock
+}
+
+// hostListener is the listener for a service on a shared listener, closing it removes
+// the routes for the service and the shared listener is closed when it has no routes
+type hostListener struct {
+	vhosts    *virtualHosts
+	shared    *vhostListener
+	serviceID string
+	hosts     []string
+	done      chan struct{}
+	once      sync.Once
+}
+
+// Accept implements the net.Listener interface, connections are accepted by the
+// shared listener and never returned
+func (h *hostListener) Accept() (net.Conn, error) {
+	<-h.done
+	return nil, net.ErrClosed
+}
+
+// Close implements the net.Listener interface
+func (h *hostListener) Close() error {
+	h.once.Do(func() {
+		close(h.done)
+		h.vhosts.remove(h)
+	})
+
+	return nil
+}
+
+// Addr implements the net.Listener interface
+func (h *hostListener) Addr() net.Addr {
+	return h.shared.l.Addr()
+}
+
+// createServiceListener creates the listener for a service, services with virtual hosts
+// share the listener for the port with the other services with virtual hosts
+func (s *Server) createServiceListener(serviceID string, detail *shipyard.Service, port int) (net.Listener, error) {
+	if len(detail.Hosts) == 0 {
+		return s.createListenerAndListen(serviceID, detail.BindAddress, port, detail.Protocol)
+	}
+
+	bind := detail.BindAddress
+	if bind == "" {
+		bind = s.bindAddress
+	}
+
+	s.vhosts.lock.Lock()
+	defer s.vhosts.lock.Unlock()
+
+	// listeners for services without a port are never shared
+	vl, ok := s.vhosts.listeners[vhostKey{bind, port}]
+	if !ok || port == 0 {
+		l, err := s.createListener(bind, port, shipyard.ServiceProtocol_TCP)
+		if err != nil {
+			return nil, err
+		}
+
+		vl = &vhostListener{key: vhostKey{bind, listenerPort(l)}, l: l, routes: map[string]*hostListener{}}
+		s.vhosts.listeners[vl.key] = vl
+
+		go s.handleVirtualHostListener(vl)
+	}
+
+	for _, h := range detail.Hosts {
+		if r, ok := vl.routes[h]; ok {
+			return nil, fmt.Errorf("host %s is already used by service %s", h, r.serviceID)
+		}
+	}
+
+	hl := &hostListener{
+		vhosts:    s.vhosts,
+		shared:    vl,
+		serviceID: serviceID,
+		hosts:     detail.Hosts,
+		done:      make(chan struct{}),
+	}
+
+	for _, h := range detail.Hosts {
+		vl.routes[h] = hl
+	}
+
+	s.log.Info("listener", "message", "Added virtual hosts", "service_id", serviceID, "addr", vl.l.Addr(), "hosts", detail.Hosts)
+
+	return hl, nil
+}
+
+// remove the routes for the service, the shared listener is closed when it has no routes
+func (v *virtualHosts) remove(hl *hostListener) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for _, h := range hl.hosts {
+		if hl.shared.routes[h] == hl {
+			delete(hl.shared.routes, h)
+		}
+	}
+
+	if len(hl.shared.routes) > 0 {
+		return
+	}
+
+	if v.listeners[hl.shared.key] == hl.shared {
+		delete(v.listeners, hl.shared.key)
+	}
+
+	hl.shared.l.Close()
+}
+
+// route returns the listener for the service with the host, a wildcard host such as
+// *.example.com matches any subdomain
+func (v *virtualHosts) route(vl *vhostListener, host string) *hostListener {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if hl, ok := vl.routes[host]; ok {
+		return hl
+	}
+
+	// a.b.example.com matches *.b.example.com then *.example.com
+	rest := host
+	for {
+		i := strings.IndexByte(rest, '.')
+		if i < 0 {
+			return nil
+		}
+
+		rest = rest[i+1:]
+		if hl, ok := vl.routes["*."+rest]; ok {
+			return hl
+		}
+	}
+}
+
+func (s *Server) handleVirtualHostListener(vl *vhostListener) {
+	for {
+		conn, err := vl.l.Accept()
+		if err != nil {
+			s.log.Error("listener", "message", "Unable to accept connection", "addr", vl.l.Addr(), "error", err)
+			return
+		}
+
+		go s.routeVirtualHost(vl, conn)
+	}
+}
+
+// routeVirtualHost reads the host for the connection and sends the connection to the
+// service with the host
+func (s *Server) routeVirtualHost(vl *vhostListener, conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(virtualHostTimeout))
+	host, isTLS, c, err := readServerName(conn)
+	conn.SetReadDeadline(time.Time{})
+
+	if err != nil {
+		s.log.Debug("listener", "message", "Unable to read host for connection", "addr", vl.l.Addr(), "error", err)
+		conn.Close()
+		return
+	}
+
+	hl := s.vhosts.route(vl, host)
+	if hl == nil {
+		s.log.Debug("listener", "message", "No service for host", "addr", vl.l.Addr(), "host", host)
+
+		if !isTLS {
+			conn.Write([]byte(notFoundResponse))
+		}
+
+		conn.Close()
+		return
+	}
+
+	s.log.Debug("listener", "message", "Handle new connection", "service_id", hl.serviceID, "host", host)
+
+	si, svc, ok := s.listenerService(hl.serviceID)
+	if !ok {
+		conn.Close()
+		return
+	}
+
+	s.acceptConnection(hl.serviceID, si, svc, hl, c)
+}
+
+// readServerName reads the TLS server name or the Host header of the first HTTP request
+// for the connection. The returned connection replays the data which has been read.
+func readServerName(conn net.Conn) (string, bool, net.Conn, error) {
+	buf := &bytes.Buffer{}
+	br := bufio.NewReader(io.TeeReader(conn, buf))
+	replay := &replayConn{Conn: conn, r: io.MultiReader(buf, conn)}
+
+	b, err := br.Peek(1)
+	if err != nil {
+		return "", false, nil, err
+	}
+
+	if b[0] == tlsRecordTypeHandshake {
+		name, err := readTLSServerName(br)
+		return normalizeHost(name), true, replay, err
+	}
+
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return "", false, nil, err
+	}
+
+	return normalizeHost(req.Host), false, replay, nil
+}
+
+// readTLSServerName reads the server name from the TLS client hello
+func readTLSServerName(r io.Reader) (string, error) {
+	var name string
+
+	cfg := &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			name = hello.ServerName
+			return nil, errServerNameRead
+		},
+	}
+
+	err := tls.Server(&helloConn{r: r}, cfg).Handshake()
+	if name == "" {
+		if errors.Is(err, errServerNameRead) {
+			return "", fmt.Errorf("TLS client hello does not contain a server name")
+		}
+
+		return "", err
+	}
+
+	return name, nil
+}
+
+// normalizeHost returns the lower case host without the port
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// validateHosts returns an error when the virtual hosts for the service are not valid
+func validateHosts(detail *shipyard.Service) error {
+	if len(detail.Hosts) == 0 {
+		return nil
+	}
+
+	if detail.Protocol != shipyard.ServiceProtocol_TCP {
+		return fmt.Errorf("hosts are only supported for TCP services")
+	}
+
+	if detail.Match != nil {
+		return fmt.Errorf("match rules are not supported for services with hosts")
+	}
+
+	for _, h := range detail.Hosts {
+		name := strings.TrimPrefix(h, "*.")
+		if name == "" || strings.ContainsAny(name, ":*/ ") {
+			return fmt.Errorf("%q is not a valid host, wildcards must be the first label e.g. *.example.com", h)
+		}
+	}
+
+	return nil
+}
+
+// replayConn is a connection which returns the data which has already been read
+// before reading from the connection
+type replayConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *replayConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// CloseWrite shuts down the write side of the connection when it is supported
+func (c *replayConn) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+
+	return c.Close()
+}
+
+// CloseRead shuts down the read side of the connection when it is supported
+func (c *replayConn) CloseRead() error {
+	if cr, ok := c.Conn.(interface{ CloseRead() error }); ok {
+		return cr.CloseRead()
+	}
+
+	return c.Close()
+}
+
+// helloConn is a connection for reading the TLS client hello, data written by the
+// TLS server is discarded
+type helloConn struct {
+	r io.Reader
+}
+
+func (c *helloConn) Read(p []byte) (int, error)         { return c.r.Read(p) }
+func (c *helloConn) Write(p []byte) (int, error)        { return len(p), nil }
+func (c *helloConn) Close() error                       { return nil }
+func (c *helloConn) LocalAddr() net.Addr                { return nil }
+func (c *helloConn) RemoteAddr() net.Addr               { return nil }
+func (c *helloConn) SetDeadline(t time.Time) error      { return nil }
+func (c *helloConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *helloConn) SetWriteDeadline(t time.Time) error { return nil }
@@ -0,0 +1,144 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/connector/protos/shipyard"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startTLSServer starts a HTTPS server which replies with the name
+func startTLSServer(t *testing.T, name string) string {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, name)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts.Listener.Addr().String()
+}
+
+// getHost sends a request for the url to the listener at addr, each request uses a new
+// connection, returns the status code and the body
+func getHost(t *testing.T, addr, url string) (int, string) {
+	c := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+	}
+
+	resp, err := c.Get(url)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+
+	d, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(d)
+}
+
+func TestReadServerNameReadsHostAndReplaysData(t *testing.T) {
+	client, conn := net.Pipe()
+	defer client.Close()
+
+	go client.Write([]byte("GET / HTTP/1.1\r\nHost: API.Example.com:8080\r\n\r\n"))
+
+	host, isTLS, c, err := readServerName(conn)
+	require.NoError(t, err)
+	require.False(t, isTLS)
+	require.Equal(t, "api.example.com", host)
+
+	d := make([]byte, 14)
+	_, err = io.ReadFull(c, d)
+	require.NoError(t, err)
+	require.Equal(t, "GET / HTTP/1.1", string(d))
+}
+
+func TestValidateHosts(t *testing.T) {
+	require.NoError(t, validateHosts(&shipyard.Service{Hosts: []string{"api.example.com", "*.example.com"}}))
+
+	require.Error(t, validateHosts(&shipyard.Service{Hosts: []string{"api.example.com:8080"}}))
+	require.Error(t, validateHosts(&shipyard.Service{Hosts: []string{"api.*.com"}}))
+	require.Error(t, validateHosts(&shipyard.Service{Hosts: []string{""}}))
+	require.Error(t, validateHosts(&shipyard.Service{Hosts: []string{"api.example.com"}, Protocol: shipyard.ServiceProtocol_UDP}))
+}
+
+func TestExposeServicesWithHostsShareListener(t *testing.T) {
+	c, _, _, servers := setupTests(t)
+
+	p := int32(freePort(t))
+	addr := fmt.Sprintf("localhost:%d", p)
+
+	expose := func(dest string, hosts ...string) string {
+		resp, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+			Service: &shipyard.Service{
+				Name:                "Test " + hosts[0],
+				RemoteConnectorAddr: servers[1].Address,
+				DestinationAddr:     dest,
+				SourcePort:          p,
+				Type:                shipyard.ServiceType_REMOTE,
+				Hosts:               hosts,
+			},
+		})
+		require.NoError(t, err)
+
+		waitForAllocatedPort(t, servers[0].Server, resp.Id)
+
+		return resp.Id
+	}
+
+	a := expose(startHTTPServer(t, "a"), "a.example.com")
+	expose(startTLSServer(t, "b"), "*.b.example.com")
+
+	// hosts must be unique for the port
+	_, err := c.ExposeService(context.Background(), &shipyard.ExposeRequest{
+		Service: &shipyard.Service{
+			Name:                "Test duplicate",
+			RemoteConnectorAddr: servers[1].Address,
+			DestinationAddr:     "localhost:8080",
+			SourcePort:          p,
+			Type:                shipyard.ServiceType_REMOTE,
+			Hosts:               []string{"A.example.com"},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	code, body := getHost(t, addr, "http://a.example.com/")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "a HTTP/1.1", body)
+
+	// TLS connections are routed by the server name
+	code, body = getHost(t, addr, "https://api.b.example.com/")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "b", body)
+
+	code, _ = getHost(t, addr, "http://c.example.com/")
+	require.Equal(t, http.StatusNotFound, code)
+
+	// the routes are removed when the service is destroyed
+	_, err = c.DestroyService(context.Background(), &shipyard.DestroyRequest{Id: a})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		code, _ := getHost(t, addr, "http://a.example.com/")
+		return code == http.StatusNotFound
+	}, 5*time.Second, 50*time.Millisecond)
+
+	code, body = getHost(t, addr, "https://api.b.example.com/")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "b", body)
+}